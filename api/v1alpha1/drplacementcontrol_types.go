@@ -135,6 +135,13 @@ type DRPlacementControlSpec struct {
 	// +kubebuilder:validation:Optional
 	ProtectedNamespaces *[]string `json:"protectedNamespaces,omitempty"`
 
+	// NamespaceMappings map the protected namespaces to the namespaces they are
+	// in on each cluster, so that the workload may be failed over into
+	// namespaces named differently, for example to avoid conflicts with the
+	// namespaces of other workloads.  Later mappings override earlier ones.
+	// +kubebuilder:validation:Optional
+	NamespaceMappings []NamespaceMapping `json:"namespaceMappings,omitempty"`
+
 	// DRPolicyRef is the reference to the DRPolicy participating in the DR replication for this DRPC
 	// +kubebuilder:validation:Required
 	// +kubebuilder:validation:XValidation:rule="self == oldSelf", message="drPolicyRef is immutable"
@@ -197,6 +204,25 @@ type VRGResourceMeta struct {
 	// VRG resource object
 	//+optional
 	ResourceVersion string `json:"resourceVersion,omitempty"`
+
+	// Summaries of the results of the VRG's recipe hooks' most recent
+	// executions
+	//+optional
+	HookResults []HookResultSummary `json:"hookResults,omitempty"`
+}
+
+// HookResultSummary summarizes a HookResult of a VRG.
+type HookResultSummary struct {
+	// Name of the hook and its operation or check, as "hook/operation"
+	Name string `json:"name"`
+
+	Type      string      `json:"type"`
+	EndTime   metav1.Time `json:"endTime"`
+	Succeeded bool        `json:"succeeded"`
+
+	// Error of an execution that failed
+	//+optional
+	Error string `json:"error,omitempty"`
 }
 
 // VRGConditions represents the conditions of the resources deployed on a
@@ -74,8 +74,146 @@ type S3StoreProfile struct {
 	// A CA bundle to use when verifying TLS connections to the provider
 	//+optional
 	CACertificates []byte `json:"caCertificates,omitempty"`
+	// Provider of the object store of this profile; one of s3, azure or gcs.
+	// Defaults to s3.  Objects are stored with the same encoding and key
+	// layout regardless of the provider.  See ObjectStoreProvider constants
+	// for the meaning of the other fields, and the format of the secret
+	// referenced by S3SecretRef, for each provider.  Kube objects are protected
+	// in azure and gcs stores by the native kube object protection engine only.
+	//+optional
+	Provider ObjectStoreProvider `json:"provider,omitempty"`
+	// Provider of the credentials used to access the S3 store of this profile.
+	// If not specified, the static access keys of the secret referenced by
+	// S3SecretRef are used.  Applies to the s3 provider only.
+	//+optional
+	S3CredentialsProvider *S3CredentialsProvider `json:"s3CredentialsProvider,omitempty"`
+	// Object lock retention of the objects uploaded with this profile, which
+	// protects them from being deleted or overwritten until it expires.  The
+	// bucket must have object lock enabled; buckets created by ramen with this
+	// profile have it enabled.  Applies to the s3 provider only.
+	//+optional
+	ObjectLock *S3ObjectLock `json:"objectLock,omitempty"`
+}
+
+// S3ObjectLockMode is the S3 object lock retention mode
+type S3ObjectLockMode string
+
+const (
+	// S3ObjectLockModeGovernance retention can be shortened or removed by users
+	// with the s3:BypassGovernanceRetention permission
+	S3ObjectLockModeGovernance = S3ObjectLockMode("GOVERNANCE")
+
+	// S3ObjectLockModeCompliance retention can not be shortened or removed by
+	// any user, including the root user of the account
+	S3ObjectLockModeCompliance = S3ObjectLockMode("COMPLIANCE")
+)
+
+// S3ObjectLock configures the object lock retention of uploaded objects
+type S3ObjectLock struct {
+	// Retention mode; one of GOVERNANCE or COMPLIANCE
+	Mode S3ObjectLockMode `json:"mode"`
+
+	// Retention period of each uploaded object, starting at its upload
+	RetentionPeriod metav1.Duration `json:"retentionPeriod"`
+}
+
+// ObjectStoreProvider is the type of object store of an S3 profile
+type ObjectStoreProvider string
+
+const (
+	// ObjectStoreProviderS3 is an AWS S3 or S3 compatible object store
+	ObjectStoreProviderS3 = ObjectStoreProvider("s3")
+
+	// ObjectStoreProviderAzure is an Azure Blob Storage account, or an Azurite
+	// emulator.  S3CompatibleEndpoint is the blob service URL of the storage
+	// account, e.g. https://<account>.blob.core.windows.net, and S3Bucket is
+	// the name of the blob container.  S3Region is not used.  The secret
+	// contains the storage account name and shared key with the keys
+	// AZURE_STORAGE_ACCOUNT_NAME and AZURE_STORAGE_ACCOUNT_KEY respectively.
+	ObjectStoreProviderAzure = ObjectStoreProvider("azure")
+
+	// ObjectStoreProviderGCS is a Google Cloud Storage bucket, or a
+	// fake-gcs-server emulator.  S3CompatibleEndpoint is optional, and
+	// overrides the JSON API endpoint, e.g. http://<emulator>:4443/storage/v1/.
+	// S3Bucket is the name of the bucket.  S3Region is not used.  The secret
+	// contains a service account key, in JSON format, with the key
+	// GOOGLE_SERVICE_ACCOUNT_KEY, which may be omitted for an emulator.
+	ObjectStoreProviderGCS = ObjectStoreProvider("gcs")
+)
+
+// S3CredentialsProviderType is the method used to obtain S3 credentials
+type S3CredentialsProviderType string
+
+const (
+	// S3CredentialsProviderStatic uses the long-lived access keys of the secret
+	// referenced by S3SecretRef
+	S3CredentialsProviderStatic = S3CredentialsProviderType("static")
+
+	// S3CredentialsProviderWebIdentity obtains short-lived credentials by
+	// assuming an IAM role with the projected service account token of the
+	// ramen operator, using AssumeRoleWithWebIdentity, and refreshes them as
+	// they expire.  S3SecretRef is not used.
+	S3CredentialsProviderWebIdentity = S3CredentialsProviderType("webIdentity")
+
+	// S3CredentialsProviderDefault uses the default credential chain of the
+	// AWS SDK, i.e. environment variables, shared credentials and config
+	// files, the web identity variables injected by EKS IRSA, and the ECS or
+	// EC2 instance roles.  S3SecretRef is not used.
+	S3CredentialsProviderDefault = S3CredentialsProviderType("default")
+)
+
+// S3CredentialsProvider selects, and configures, the method used to obtain
+// the credentials of an S3 profile
+type S3CredentialsProvider struct {
+	// Type of the credentials provider; one of static, webIdentity or default
+	Type S3CredentialsProviderType `json:"type"`
+
+	// Configuration of the webIdentity credentials provider
+	//+optional
+	WebIdentity *S3WebIdentity `json:"webIdentity,omitempty"`
+}
+
+// S3WebIdentity configures the assumption of an IAM role with a web identity
+// token to access an S3 store
+type S3WebIdentity struct {
+	// ARN of the IAM role to assume
+	RoleARN string `json:"roleARN"`
+
+	// Path of the projected service account token file presented to STS.
+	// Defaults to the value of the AWS_WEB_IDENTITY_TOKEN_FILE environment
+	// variable of the ramen operator
+	//+optional
+	TokenFile string `json:"tokenFile,omitempty"`
+
+	// Name of the role session, used to identify the ramen operator in the
+	// access logs of the S3 store.  Defaults to the S3 profile name
+	//+optional
+	RoleSessionName string `json:"roleSessionName,omitempty"`
+
+	// STS compatible endpoint, for S3 compatible stores that implement
+	// AssumeRoleWithWebIdentity, such as Ceph RGW and MinIO.  Defaults to the
+	// AWS STS endpoint of the profile's region
+	//+optional
+	STSEndpoint string `json:"stsEndpoint,omitempty"`
 }
 
+// KubeObjectProtectionEngine is the implementation of kube objects capture and
+// recovery
+type KubeObjectProtectionEngine string
+
+const (
+	// KubeObjectProtectionEngineVelero submits Velero, or OADP, backups and
+	// restores.  Velero must be installed on each DR cluster.
+	KubeObjectProtectionEngineVelero = KubeObjectProtectionEngine("velero")
+
+	// KubeObjectProtectionEngineNative captures kube objects with the dynamic
+	// client of the ramen operator, stores them in the S3 profiles, and
+	// recovers them from there.  Velero is not required.  Requests are
+	// recorded in the ramen operator namespace.  The operator must be granted
+	// access to objects of any resource, e.g. with config/dr-cluster/rbac-native.
+	KubeObjectProtectionEngineNative = KubeObjectProtectionEngine("native")
+)
+
 // ControllerMetrics defines the controller metrics configuration
 type ControllerMetrics struct {
 	// BindAddress is the TCP address that the controller should bind to
@@ -178,8 +316,15 @@ type RamenConfig struct {
 		Disabled bool `json:"disabled,omitempty"`
 		// Velero namespace input
 		VeleroNamespaceName string `json:"veleroNamespaceName,omitempty"`
+		// Engine that captures and recovers kube objects; one of velero, the
+		// default, or native
+		Engine KubeObjectProtectionEngine `json:"engine,omitempty"`
 	} `json:"kubeObjectProtection,omitempty"`
 
+	// Policies for the Secrets kube objects captures include, applied before
+	// those of each VRG
+	KubeObjectsSecretPolicies []KubeObjectsSecretPolicy `json:"kubeObjectsSecretPolicies,omitempty"`
+
 	MultiNamespace struct {
 		// Enables feature to protect resources in namespaces other than VRG's
 		FeatureEnabled   bool `json:"FeatureEnabled,omitempty"`
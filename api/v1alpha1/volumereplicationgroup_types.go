@@ -127,6 +127,12 @@ type KubeObjectProtectionSpec struct {
 	//+kubebuilder:validation:Format=duration
 	CaptureInterval *metav1.Duration `json:"captureInterval,omitempty"`
 
+	// Start a capture, sooner than the capture interval, when kube objects that
+	// the capture groups select change. The operator must be granted access to
+	// watch objects of any resource, as by config/dr-cluster/rbac-native
+	//+optional
+	CaptureOnChange *KubeObjectsCaptureOnChange `json:"captureOnChange,omitempty"`
+
 	// Name of the Recipe to reference for capture and recovery workflows and volume selection.
 	//+optional
 	RecipeRef *RecipeRef `json:"recipeRef,omitempty"`
@@ -138,6 +144,146 @@ type KubeObjectProtectionSpec struct {
 	// Label selector to identify all the kube objects that need DR protection.
 	// +optional
 	KubeObjectSelector *metav1.LabelSelector `json:"kubeObjectSelector,omitempty"`
+
+	// Retention of kube objects captures. Defaults to retaining the most recent capture only.
+	//+optional
+	CaptureRetention *KubeObjectsCaptureRetention `json:"captureRetention,omitempty"`
+
+	// Capture to recover kube objects from on failover. Defaults to the most recent capture.
+	//+optional
+	RecoverFrom *KubeObjectsRecoverFrom `json:"recoverFrom,omitempty"`
+
+	// Transformations of the kube objects recovered to each cluster, applied in order
+	//+optional
+	RecoverTransforms []KubeObjectsRecoverTransform `json:"recoverTransforms,omitempty"`
+
+	// Policies for the Secrets captured, after those of the ramen config and
+	// those of the recipe's ramendr.openshift.io/secret-policies annotation. The
+	// first policy that selects a Secret applies to it.
+	//+optional
+	SecretPolicies []KubeObjectsSecretPolicy `json:"secretPolicies,omitempty"`
+}
+
+// KubeObjectsCaptureOnChange delays a capture started on change so that it
+// includes subsequent changes, and spaces captures.
+type KubeObjectsCaptureOnChange struct {
+	// Time without further changes to wait after a change. Defaults to 10 seconds.
+	//+optional
+	//+kubebuilder:validation:Format=duration
+	Debounce *metav1.Duration `json:"debounce,omitempty"`
+
+	// Minimum time between capture starts. Defaults to 1 minute.
+	//+optional
+	//+kubebuilder:validation:Format=duration
+	MinInterval *metav1.Duration `json:"minInterval,omitempty"`
+}
+
+// KubeObjectsCaptureRetention limits the kube objects captures that are retained.
+// The most recent capture is always retained.
+type KubeObjectsCaptureRetention struct {
+	// Maximum number of captures to retain. Defaults to 1 if MaxAge is not set,
+	// and is otherwise unlimited.
+	//+optional
+	//+kubebuilder:validation:Minimum=1
+	Count *int32 `json:"count,omitempty"`
+
+	// Maximum age, since completion, of the captures to retain
+	//+optional
+	//+kubebuilder:validation:Format=duration
+	MaxAge *metav1.Duration `json:"maxAge,omitempty"`
+}
+
+// KubeObjectsRecoverFrom selects a retained kube objects capture, listed in the
+// VRG status, by number or time. The number is preferred if both are set.
+type KubeObjectsRecoverFrom struct {
+	// Number of the capture
+	//+optional
+	CaptureNumber *int64 `json:"captureNumber,omitempty"`
+
+	// Select the most recent capture completed at or before this time
+	//+optional
+	Time *metav1.Time `json:"time,omitempty"`
+}
+
+// KubeObjectsRecoverTransform patches the kube objects it matches as they are
+// recovered to the clusters it is chosen for, as a Velero resource modifier
+// rule does.
+type KubeObjectsRecoverTransform struct {
+	// Names of the DRClusters the transform is applied on recovery to. Defaults to all clusters.
+	//+optional
+	Clusters []string `json:"clusters,omitempty"`
+
+	// Resource of the objects to patch, qualified by its group if any, e.g. deployments.apps, or "*"
+	GroupResource string `json:"groupResource"`
+
+	// Regular expression the names of the objects to patch match
+	//+optional
+	ResourceNameRegex string `json:"resourceNameRegex,omitempty"`
+
+	// Namespaces, as recovered to, of the objects to patch. Defaults to all namespaces.
+	//+optional
+	Namespaces []string `json:"namespaces,omitempty"`
+
+	// Label selector of the objects to patch
+	//+optional
+	LabelSelector *metav1.LabelSelector `json:"labelSelector,omitempty"`
+
+	// JSON patch operations applied to the objects, in order
+	//+kubebuilder:validation:MinItems=1
+	Patches []KubeObjectsRecoverPatch `json:"patches"`
+}
+
+// KubeObjectsRecoverPatch is a JSON patch operation
+type KubeObjectsRecoverPatch struct {
+	//+kubebuilder:validation:Enum=add;remove;replace;copy;move;test
+	Operation string `json:"operation"`
+
+	// JSON pointer to the field to patch
+	Path string `json:"path"`
+
+	// JSON pointer to the field to copy or move from
+	//+optional
+	From string `json:"from,omitempty"`
+
+	// Value of the field, as JSON. Other text is a string value.
+	//+optional
+	Value string `json:"value,omitempty"`
+}
+
+// KubeObjectsSecretPolicyAction is what a capture does with the Secrets a
+// policy selects
+type KubeObjectsSecretPolicyAction string
+
+const (
+	// KubeObjectsSecretPolicyActionExclude does not capture the Secrets
+	KubeObjectsSecretPolicyActionExclude = KubeObjectsSecretPolicyAction("Exclude")
+
+	// KubeObjectsSecretPolicyActionRedact captures the Secrets without their
+	// data, which is recovered from a Secret that exists on the recovery cluster
+	KubeObjectsSecretPolicyActionRedact = KubeObjectsSecretPolicyAction("Redact")
+)
+
+// KubeObjectsSecretPolicy keeps the data of the Secrets it selects from being
+// stored with kube objects captures. Policies are applied by the native kube
+// object protection engine only; the velero engine does not capture the kube
+// objects of a VRG that has any.
+type KubeObjectsSecretPolicy struct {
+	// Types of the Secrets selected, e.g. kubernetes.io/tls. Defaults to all types.
+	//+optional
+	Types []corev1.SecretType `json:"types,omitempty"`
+
+	// Label selector of the Secrets selected. Defaults to all Secrets.
+	//+optional
+	LabelSelector *metav1.LabelSelector `json:"labelSelector,omitempty"`
+
+	//+kubebuilder:validation:Enum=Exclude;Redact
+	Action KubeObjectsSecretPolicyAction `json:"action"`
+
+	// Name of the Secret, in the namespace a redacted Secret is recovered to,
+	// whose data it is recovered with. Defaults to the redacted Secret's name,
+	// which must then exist on the recovery cluster.
+	//+optional
+	DataSourceName string `json:"dataSourceName,omitempty"`
 }
 
 type RecipeRef struct {
@@ -150,7 +296,12 @@ type RecipeRef struct {
 	Name string `json:"name,omitempty"`
 }
 
-const KubeObjectProtectionCaptureIntervalDefault = 5 * time.Minute
+const (
+	KubeObjectProtectionCaptureIntervalDefault   = 5 * time.Minute
+	KubeObjectsCaptureRetentionCountDefault      = 1
+	KubeObjectsCaptureOnChangeDebounceDefault    = 10 * time.Second
+	KubeObjectsCaptureOnChangeMinIntervalDefault = time.Minute
+)
 
 // VolumeReplicationGroup (VRG) spec declares the desired schedule for data
 // replication and replication state of all PVCs identified via the given
@@ -209,6 +360,28 @@ type VolumeReplicationGroupSpec struct {
 	// You can use a recipe to filter and coordinate the order of the resources that are protected.
 	//+optional
 	ProtectedNamespaces *[]string `json:"protectedNamespaces,omitempty"`
+
+	// NamespaceMappings map the protected namespaces to the namespaces they are
+	// in on each cluster.  Later mappings override earlier ones.  Only a VRG in
+	// the Ramen Ops Namespace may map namespaces.
+	//+optional
+	NamespaceMappings []NamespaceMapping `json:"namespaceMappings,omitempty"`
+}
+
+// NamespaceMapping maps protected namespaces to the namespaces their objects,
+// PVCs and VolSync replication destinations are in on the listed clusters, so
+// that a workload may be recovered into namespaces named differently than
+// those it was protected in.  The namespaces of consistency groups of VolSync
+// PVCs may not be mapped.
+type NamespaceMapping struct {
+	// Names of the clusters the namespaces are mapped on, or any if empty
+	//+optional
+	Clusters []string `json:"clusters,omitempty"`
+
+	// Namespaces maps the name of each protected namespace to the name of the
+	// namespace it is on the clusters
+	//+kubebuilder:validation:MinProperties=1
+	Namespaces map[string]string `json:"namespaces"`
 }
 
 type Identifier struct {
@@ -317,6 +490,141 @@ type KubeObjectsCaptureIdentifier struct {
 type KubeObjectProtectionStatus struct {
 	//+optional
 	CaptureToRecoverFrom *KubeObjectsCaptureIdentifier `json:"captureToRecoverFrom,omitempty"`
+
+	// Retained captures, most recent first
+	//+optional
+	Captures []KubeObjectsCaptureIdentifier `json:"captures,omitempty"`
+
+	// Differences between the most recent capture and the capture before it
+	//+optional
+	CaptureDiff *KubeObjectsCaptureDiff `json:"captureDiff,omitempty"`
+
+	// Result of the most recent verification, on the secondary cluster, that
+	// the capture-to-recover-from is recoverable
+	//+optional
+	RecoverVerification *KubeObjectsRecoverVerification `json:"recoverVerification,omitempty"`
+
+	// Secrets the most recent capture excluded or redacted
+	//+optional
+	CaptureSecrets *KubeObjectsCaptureSecrets `json:"captureSecrets,omitempty"`
+
+	// Results of the most recent execution of each recipe hook operation or
+	// check of the capture and recover workflows, of the recipe's current hooks
+	//+optional
+	HookResults []HookResult `json:"hookResults,omitempty"`
+}
+
+// HookResult is the result of an execution of a recipe hook operation or check.
+type HookResult struct {
+	// Name of the hook and its operation or check, as "hook/operation"
+	Name string `json:"name"`
+
+	// Type of the hook, e.g. exec or check
+	Type string `json:"type"`
+
+	// Resources the hook executed on, e.g. pods an exec hook's command executed
+	// in, or resources a scale hook scaled
+	//+optional
+	Targets []string `json:"targets,omitempty"`
+
+	// Start and end times of the first of the consecutive executions with
+	// this result
+	StartTime metav1.Time `json:"startTime"`
+	EndTime   metav1.Time `json:"endTime"`
+
+	// Whether the execution succeeded. An execution may fail without failing
+	// the workflow, if its hook's onError is continue.
+	Succeeded bool `json:"succeeded"`
+
+	// Exit code of an exec hook's command
+	//+optional
+	ExitCode *int32 `json:"exitCode,omitempty"`
+
+	// Status code of an http hook's response
+	//+optional
+	StatusCode *int32 `json:"statusCode,omitempty"`
+
+	// Result of a check hook's condition
+	//+optional
+	CheckResult *bool `json:"checkResult,omitempty"`
+
+	// Output of the execution, truncated to its last 1KiB
+	//+optional
+	Stdout string `json:"stdout,omitempty"`
+	//+optional
+	Stderr string `json:"stderr,omitempty"`
+
+	// Error of an execution that failed, truncated to its first 1KiB
+	//+optional
+	Error string `json:"error,omitempty"`
+
+	// Whether the operation's inverse operation was executed, as it failed
+	//+optional
+	InverseOpExecuted bool `json:"inverseOpExecuted,omitempty"`
+}
+
+// KubeObjectsCaptureSecrets counts the Secrets a capture excluded or redacted
+// by the secret policies. The Secrets are listed in a report stored with the
+// capture in each S3 store.
+type KubeObjectsCaptureSecrets struct {
+	// Number of the capture
+	Number int64 `json:"number"`
+
+	Excluded int32 `json:"excluded"`
+	Redacted int32 `json:"redacted"`
+}
+
+// KubeObjectsRecoverVerification is the result of recovering the kube objects
+// of a capture with a server-side dry run on the secondary cluster, that of the
+// S3 profile whose copy of the capture is the least recoverable.
+type KubeObjectsRecoverVerification struct {
+	// Number of the capture verified
+	CaptureNumber int64 `json:"captureNumber"`
+
+	// Time of the verification
+	Time metav1.Time `json:"time"`
+
+	// Number of objects that would be recovered
+	ObjectCount int32 `json:"objectCount"`
+
+	// Number of objects that could not be verified as their namespace does not
+	// exist on the secondary cluster
+	//+optional
+	Unverifiable int32 `json:"unverifiable,omitempty"`
+
+	// Resources of the objects that would not be recovered
+	//+optional
+	Failures []KubeObjectsRecoverFailure `json:"failures,omitempty"`
+}
+
+// KubeObjectsRecoverFailure counts the objects of a resource that would not be
+// recovered, and describes the error of the first.
+type KubeObjectsRecoverFailure struct {
+	// Group and resource name, as in deployments.apps
+	Resource string `json:"resource"`
+
+	Count   int32  `json:"count"`
+	Message string `json:"message"`
+}
+
+// KubeObjectsCaptureDiff counts the kube objects added, removed and modified
+// between two consecutive captures. The objects are listed in a report stored
+// with the capture in each S3 store.
+type KubeObjectsCaptureDiff struct {
+	// Number of the capture
+	Number int64 `json:"number"`
+
+	// Number of the capture before it
+	PreviousNumber int64 `json:"previousNumber"`
+
+	Added   int32 `json:"added"`
+	Removed int32 `json:"removed"`
+
+	// Objects modified are counted by the native kube object protection
+	// engine only, as Velero does not record the content of the objects it
+	// lists, so it is unset for Velero's captures
+	//+optional
+	Modified *int32 `json:"modified,omitempty"`
 }
 
 // VolumeReplicationGroupStatus defines the observed state of VolumeReplicationGroup
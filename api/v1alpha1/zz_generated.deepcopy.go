@@ -394,6 +394,13 @@ func (in *DRPlacementControlSpec) DeepCopyInto(out *DRPlacementControlSpec) {
 			copy(*out, *in)
 		}
 	}
+	if in.NamespaceMappings != nil {
+		in, out := &in.NamespaceMappings, &out.NamespaceMappings
+		*out = make([]NamespaceMapping, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
 	out.DRPolicyRef = in.DRPolicyRef
 	in.PVCSelector.DeepCopyInto(&out.PVCSelector)
 	if in.KubeObjectProtection != nil {
@@ -594,6 +601,59 @@ func (in *Groups) DeepCopy() *Groups {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *HookResult) DeepCopyInto(out *HookResult) {
+	*out = *in
+	if in.Targets != nil {
+		in, out := &in.Targets, &out.Targets
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	in.StartTime.DeepCopyInto(&out.StartTime)
+	in.EndTime.DeepCopyInto(&out.EndTime)
+	if in.ExitCode != nil {
+		in, out := &in.ExitCode, &out.ExitCode
+		*out = new(int32)
+		**out = **in
+	}
+	if in.StatusCode != nil {
+		in, out := &in.StatusCode, &out.StatusCode
+		*out = new(int32)
+		**out = **in
+	}
+	if in.CheckResult != nil {
+		in, out := &in.CheckResult, &out.CheckResult
+		*out = new(bool)
+		**out = **in
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new HookResult.
+func (in *HookResult) DeepCopy() *HookResult {
+	if in == nil {
+		return nil
+	}
+	out := new(HookResult)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *HookResultSummary) DeepCopyInto(out *HookResultSummary) {
+	*out = *in
+	in.EndTime.DeepCopyInto(&out.EndTime)
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new HookResultSummary.
+func (in *HookResultSummary) DeepCopy() *HookResultSummary {
+	if in == nil {
+		return nil
+	}
+	out := new(HookResultSummary)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *Identifier) DeepCopyInto(out *Identifier) {
 	*out = *in
@@ -622,6 +682,11 @@ func (in *KubeObjectProtectionSpec) DeepCopyInto(out *KubeObjectProtectionSpec)
 		*out = new(v1.Duration)
 		**out = **in
 	}
+	if in.CaptureOnChange != nil {
+		in, out := &in.CaptureOnChange, &out.CaptureOnChange
+		*out = new(KubeObjectsCaptureOnChange)
+		(*in).DeepCopyInto(*out)
+	}
 	if in.RecipeRef != nil {
 		in, out := &in.RecipeRef, &out.RecipeRef
 		*out = new(RecipeRef)
@@ -648,6 +713,30 @@ func (in *KubeObjectProtectionSpec) DeepCopyInto(out *KubeObjectProtectionSpec)
 		*out = new(v1.LabelSelector)
 		(*in).DeepCopyInto(*out)
 	}
+	if in.CaptureRetention != nil {
+		in, out := &in.CaptureRetention, &out.CaptureRetention
+		*out = new(KubeObjectsCaptureRetention)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.RecoverFrom != nil {
+		in, out := &in.RecoverFrom, &out.RecoverFrom
+		*out = new(KubeObjectsRecoverFrom)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.RecoverTransforms != nil {
+		in, out := &in.RecoverTransforms, &out.RecoverTransforms
+		*out = make([]KubeObjectsRecoverTransform, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	if in.SecretPolicies != nil {
+		in, out := &in.SecretPolicies, &out.SecretPolicies
+		*out = make([]KubeObjectsSecretPolicy, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
 }
 
 // DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new KubeObjectProtectionSpec.
@@ -668,6 +757,35 @@ func (in *KubeObjectProtectionStatus) DeepCopyInto(out *KubeObjectProtectionStat
 		*out = new(KubeObjectsCaptureIdentifier)
 		(*in).DeepCopyInto(*out)
 	}
+	if in.Captures != nil {
+		in, out := &in.Captures, &out.Captures
+		*out = make([]KubeObjectsCaptureIdentifier, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	if in.CaptureDiff != nil {
+		in, out := &in.CaptureDiff, &out.CaptureDiff
+		*out = new(KubeObjectsCaptureDiff)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.RecoverVerification != nil {
+		in, out := &in.RecoverVerification, &out.RecoverVerification
+		*out = new(KubeObjectsRecoverVerification)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.CaptureSecrets != nil {
+		in, out := &in.CaptureSecrets, &out.CaptureSecrets
+		*out = new(KubeObjectsCaptureSecrets)
+		**out = **in
+	}
+	if in.HookResults != nil {
+		in, out := &in.HookResults, &out.HookResults
+		*out = make([]HookResult, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
 }
 
 // DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new KubeObjectProtectionStatus.
@@ -680,6 +798,26 @@ func (in *KubeObjectProtectionStatus) DeepCopy() *KubeObjectProtectionStatus {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *KubeObjectsCaptureDiff) DeepCopyInto(out *KubeObjectsCaptureDiff) {
+	*out = *in
+	if in.Modified != nil {
+		in, out := &in.Modified, &out.Modified
+		*out = new(int32)
+		**out = **in
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new KubeObjectsCaptureDiff.
+func (in *KubeObjectsCaptureDiff) DeepCopy() *KubeObjectsCaptureDiff {
+	if in == nil {
+		return nil
+	}
+	out := new(KubeObjectsCaptureDiff)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *KubeObjectsCaptureIdentifier) DeepCopyInto(out *KubeObjectsCaptureIdentifier) {
 	*out = *in
@@ -697,6 +835,206 @@ func (in *KubeObjectsCaptureIdentifier) DeepCopy() *KubeObjectsCaptureIdentifier
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *KubeObjectsCaptureOnChange) DeepCopyInto(out *KubeObjectsCaptureOnChange) {
+	*out = *in
+	if in.Debounce != nil {
+		in, out := &in.Debounce, &out.Debounce
+		*out = new(v1.Duration)
+		**out = **in
+	}
+	if in.MinInterval != nil {
+		in, out := &in.MinInterval, &out.MinInterval
+		*out = new(v1.Duration)
+		**out = **in
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new KubeObjectsCaptureOnChange.
+func (in *KubeObjectsCaptureOnChange) DeepCopy() *KubeObjectsCaptureOnChange {
+	if in == nil {
+		return nil
+	}
+	out := new(KubeObjectsCaptureOnChange)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *KubeObjectsCaptureRetention) DeepCopyInto(out *KubeObjectsCaptureRetention) {
+	*out = *in
+	if in.Count != nil {
+		in, out := &in.Count, &out.Count
+		*out = new(int32)
+		**out = **in
+	}
+	if in.MaxAge != nil {
+		in, out := &in.MaxAge, &out.MaxAge
+		*out = new(v1.Duration)
+		**out = **in
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new KubeObjectsCaptureRetention.
+func (in *KubeObjectsCaptureRetention) DeepCopy() *KubeObjectsCaptureRetention {
+	if in == nil {
+		return nil
+	}
+	out := new(KubeObjectsCaptureRetention)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *KubeObjectsCaptureSecrets) DeepCopyInto(out *KubeObjectsCaptureSecrets) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new KubeObjectsCaptureSecrets.
+func (in *KubeObjectsCaptureSecrets) DeepCopy() *KubeObjectsCaptureSecrets {
+	if in == nil {
+		return nil
+	}
+	out := new(KubeObjectsCaptureSecrets)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *KubeObjectsRecoverFailure) DeepCopyInto(out *KubeObjectsRecoverFailure) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new KubeObjectsRecoverFailure.
+func (in *KubeObjectsRecoverFailure) DeepCopy() *KubeObjectsRecoverFailure {
+	if in == nil {
+		return nil
+	}
+	out := new(KubeObjectsRecoverFailure)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *KubeObjectsRecoverFrom) DeepCopyInto(out *KubeObjectsRecoverFrom) {
+	*out = *in
+	if in.CaptureNumber != nil {
+		in, out := &in.CaptureNumber, &out.CaptureNumber
+		*out = new(int64)
+		**out = **in
+	}
+	if in.Time != nil {
+		in, out := &in.Time, &out.Time
+		*out = (*in).DeepCopy()
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new KubeObjectsRecoverFrom.
+func (in *KubeObjectsRecoverFrom) DeepCopy() *KubeObjectsRecoverFrom {
+	if in == nil {
+		return nil
+	}
+	out := new(KubeObjectsRecoverFrom)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *KubeObjectsRecoverPatch) DeepCopyInto(out *KubeObjectsRecoverPatch) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new KubeObjectsRecoverPatch.
+func (in *KubeObjectsRecoverPatch) DeepCopy() *KubeObjectsRecoverPatch {
+	if in == nil {
+		return nil
+	}
+	out := new(KubeObjectsRecoverPatch)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *KubeObjectsRecoverTransform) DeepCopyInto(out *KubeObjectsRecoverTransform) {
+	*out = *in
+	if in.Clusters != nil {
+		in, out := &in.Clusters, &out.Clusters
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	if in.Namespaces != nil {
+		in, out := &in.Namespaces, &out.Namespaces
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	if in.LabelSelector != nil {
+		in, out := &in.LabelSelector, &out.LabelSelector
+		*out = new(v1.LabelSelector)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.Patches != nil {
+		in, out := &in.Patches, &out.Patches
+		*out = make([]KubeObjectsRecoverPatch, len(*in))
+		copy(*out, *in)
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new KubeObjectsRecoverTransform.
+func (in *KubeObjectsRecoverTransform) DeepCopy() *KubeObjectsRecoverTransform {
+	if in == nil {
+		return nil
+	}
+	out := new(KubeObjectsRecoverTransform)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *KubeObjectsRecoverVerification) DeepCopyInto(out *KubeObjectsRecoverVerification) {
+	*out = *in
+	in.Time.DeepCopyInto(&out.Time)
+	if in.Failures != nil {
+		in, out := &in.Failures, &out.Failures
+		*out = make([]KubeObjectsRecoverFailure, len(*in))
+		copy(*out, *in)
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new KubeObjectsRecoverVerification.
+func (in *KubeObjectsRecoverVerification) DeepCopy() *KubeObjectsRecoverVerification {
+	if in == nil {
+		return nil
+	}
+	out := new(KubeObjectsRecoverVerification)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *KubeObjectsSecretPolicy) DeepCopyInto(out *KubeObjectsSecretPolicy) {
+	*out = *in
+	if in.Types != nil {
+		in, out := &in.Types, &out.Types
+		*out = make([]corev1.SecretType, len(*in))
+		copy(*out, *in)
+	}
+	if in.LabelSelector != nil {
+		in, out := &in.LabelSelector, &out.LabelSelector
+		*out = new(v1.LabelSelector)
+		(*in).DeepCopyInto(*out)
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new KubeObjectsSecretPolicy.
+func (in *KubeObjectsSecretPolicy) DeepCopy() *KubeObjectsSecretPolicy {
+	if in == nil {
+		return nil
+	}
+	out := new(KubeObjectsSecretPolicy)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *MaintenanceMode) DeepCopyInto(out *MaintenanceMode) {
 	*out = *in
@@ -798,6 +1136,33 @@ func (in *MaintenanceModeStatus) DeepCopy() *MaintenanceModeStatus {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *NamespaceMapping) DeepCopyInto(out *NamespaceMapping) {
+	*out = *in
+	if in.Clusters != nil {
+		in, out := &in.Clusters, &out.Clusters
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	if in.Namespaces != nil {
+		in, out := &in.Namespaces, &out.Namespaces
+		*out = make(map[string]string, len(*in))
+		for key, val := range *in {
+			(*out)[key] = val
+		}
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new NamespaceMapping.
+func (in *NamespaceMapping) DeepCopy() *NamespaceMapping {
+	if in == nil {
+		return nil
+	}
+	out := new(NamespaceMapping)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *PeerClass) DeepCopyInto(out *PeerClass) {
 	*out = *in
@@ -1027,6 +1392,13 @@ func (in *RamenConfig) DeepCopyInto(out *RamenConfig) {
 	out.DrClusterOperator = in.DrClusterOperator
 	out.VolSync = in.VolSync
 	out.KubeObjectProtection = in.KubeObjectProtection
+	if in.KubeObjectsSecretPolicies != nil {
+		in, out := &in.KubeObjectsSecretPolicies, &out.KubeObjectsSecretPolicies
+		*out = make([]KubeObjectsSecretPolicy, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
 	out.MultiNamespace = in.MultiNamespace
 }
 
@@ -1365,6 +1737,42 @@ func (in *ReplicationSourceTriggerSpec) DeepCopy() *ReplicationSourceTriggerSpec
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *S3CredentialsProvider) DeepCopyInto(out *S3CredentialsProvider) {
+	*out = *in
+	if in.WebIdentity != nil {
+		in, out := &in.WebIdentity, &out.WebIdentity
+		*out = new(S3WebIdentity)
+		**out = **in
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new S3CredentialsProvider.
+func (in *S3CredentialsProvider) DeepCopy() *S3CredentialsProvider {
+	if in == nil {
+		return nil
+	}
+	out := new(S3CredentialsProvider)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *S3ObjectLock) DeepCopyInto(out *S3ObjectLock) {
+	*out = *in
+	out.RetentionPeriod = in.RetentionPeriod
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new S3ObjectLock.
+func (in *S3ObjectLock) DeepCopy() *S3ObjectLock {
+	if in == nil {
+		return nil
+	}
+	out := new(S3ObjectLock)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *S3StoreProfile) DeepCopyInto(out *S3StoreProfile) {
 	*out = *in
@@ -1379,6 +1787,16 @@ func (in *S3StoreProfile) DeepCopyInto(out *S3StoreProfile) {
 		*out = make([]byte, len(*in))
 		copy(*out, *in)
 	}
+	if in.S3CredentialsProvider != nil {
+		in, out := &in.S3CredentialsProvider, &out.S3CredentialsProvider
+		*out = new(S3CredentialsProvider)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.ObjectLock != nil {
+		in, out := &in.ObjectLock, &out.ObjectLock
+		*out = new(S3ObjectLock)
+		**out = **in
+	}
 }
 
 // DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new S3StoreProfile.
@@ -1391,6 +1809,21 @@ func (in *S3StoreProfile) DeepCopy() *S3StoreProfile {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *S3WebIdentity) DeepCopyInto(out *S3WebIdentity) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new S3WebIdentity.
+func (in *S3WebIdentity) DeepCopy() *S3WebIdentity {
+	if in == nil {
+		return nil
+	}
+	out := new(S3WebIdentity)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *StorageIdentifiers) DeepCopyInto(out *StorageIdentifiers) {
 	*out = *in
@@ -1493,6 +1926,13 @@ func (in *VRGResourceMeta) DeepCopyInto(out *VRGResourceMeta) {
 			(*in)[i].DeepCopyInto(&(*out)[i])
 		}
 	}
+	if in.HookResults != nil {
+		in, out := &in.HookResults, &out.HookResults
+		*out = make([]HookResultSummary, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
 }
 
 // DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new VRGResourceMeta.
@@ -1674,6 +2114,13 @@ func (in *VolumeReplicationGroupSpec) DeepCopyInto(out *VolumeReplicationGroupSp
 			copy(*out, *in)
 		}
 	}
+	if in.NamespaceMappings != nil {
+		in, out := &in.NamespaceMappings, &out.NamespaceMappings
+		*out = make([]NamespaceMapping, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
 }
 
 // DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new VolumeReplicationGroupSpec.
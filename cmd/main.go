@@ -187,6 +187,17 @@ func setupReconcilersCluster(mgr ctrl.Manager, ramenConfig *ramendrv1alpha1.Rame
 		os.Exit(1)
 	}
 
+	if !ramenConfig.KubeObjectProtection.Disabled {
+		if err := (&controllers.RecipeReconciler{
+			Client: mgr.GetClient(),
+			Scheme: mgr.GetScheme(),
+			Log:    ctrl.Log.WithName("recipe"),
+		}).SetupWithManager(mgr); err != nil {
+			setupLog.Error(err, "unable to create controller", "controller", "Recipe")
+			os.Exit(1)
+		}
+	}
+
 	if !ramenConfig.VolSync.Disabled {
 		setupLog.Info("VolSync enabled, setup ReplicationGroupSource and ReplicationGroupDestination controllers")
 
@@ -281,6 +292,12 @@ func main() {
 
 	setupReconcilers(mgr, ramenConfig)
 
+	// Invalidate pooled S3 connections on S3 secret and ramen config changes
+	if err := controllers.S3ObjectStorePoolWatch(context.Background(), mgr.GetCache()); err != nil {
+		setupLog.Error(err, "unable to watch S3 secrets and ramen config")
+		os.Exit(1)
+	}
+
 	// +kubebuilder:scaffold:builder
 	if err := mgr.AddHealthzCheck("health", healthz.Ping); err != nil {
 		setupLog.Error(err, "unable to set up health check")
@@ -0,0 +1,235 @@
+// SPDX-FileCopyrightText: The RamenDR authors
+// SPDX-License-Identifier: Apache-2.0
+
+package controllers
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"time"
+
+	"github.com/Azure/azure-sdk-for-go/sdk/azcore"
+	"github.com/Azure/azure-sdk-for-go/sdk/azcore/runtime"
+	"github.com/Azure/azure-sdk-for-go/sdk/azcore/streaming"
+	"github.com/Azure/azure-sdk-for-go/sdk/storage/azblob/bloberror"
+	"github.com/Azure/azure-sdk-for-go/sdk/storage/azblob/container"
+	ramen "github.com/ramendr/ramen/api/v1alpha1"
+	"github.com/ramendr/ramen/internal/controller/util"
+	corev1 "k8s.io/api/core/v1"
+)
+
+// azureObjectStore is an ObjectStorer of a blob container of an Azure Blob
+// Storage account, with the same object encoding and key layout as an S3
+// object store.
+type azureObjectStore struct {
+	client    *container.Client
+	endpoint  string
+	container string
+	callerTag string
+	name      string
+}
+
+// newAzureObjectStore creates a client, authenticated with the shared key of
+// the storage account in the given secret, of the blob container of the given
+// profile.
+func newAzureObjectStore(profileName string, s3StoreProfile ramen.S3StoreProfile, secret *corev1.Secret,
+) (*azureObjectStore, error) {
+	accountName := string(secret.Data[util.SecretKeyAzureStorageAccountName])
+	accountKey := string(secret.Data[util.SecretKeyAzureStorageAccountKey])
+
+	if accountName == "" || accountKey == "" {
+		return nil, fmt.Errorf("secret %s/%s lacks AZURE_STORAGE_ACCOUNT_NAME or AZURE_STORAGE_ACCOUNT_KEY",
+			secret.Namespace, secret.Name)
+	}
+
+	credential, err := container.NewSharedKeyCredential(accountName, accountKey)
+	if err != nil {
+		return nil, fmt.Errorf("invalid shared key of storage account %s, %w", accountName, err)
+	}
+
+	options := &container.ClientOptions{}
+
+	if len(s3StoreProfile.CACertificates) != 0 {
+		httpClient, err := s3HTTPClient(s3StoreProfile.CACertificates)
+		if err != nil {
+			return nil, err
+		}
+
+		options.Transport = httpClient
+	}
+
+	containerURL := runtime.JoinPaths(s3StoreProfile.S3CompatibleEndpoint, s3StoreProfile.S3Bucket)
+
+	client, err := container.NewClientWithSharedKeyCredential(containerURL, credential, options)
+	if err != nil {
+		return nil, err
+	}
+
+	return &azureObjectStore{
+		client:    client,
+		endpoint:  s3StoreProfile.S3CompatibleEndpoint,
+		container: s3StoreProfile.S3Bucket,
+		name:      profileName,
+	}, nil
+}
+
+func (s *azureObjectStore) withCallerTag(callerTag string) ObjectStorer {
+	s1 := *s
+	s1.callerTag = callerTag
+
+	return &s1
+}
+
+// processAzureError returns the given error prefix, annotated with the error
+// code of the given error if it is an Azure response error.
+func processAzureError(errMsgPrefix, err error) error {
+	var responseErr *azcore.ResponseError
+	if errors.As(err, &responseErr) {
+		return fmt.Errorf("%w: code: %s, status: %d", errMsgPrefix, responseErr.ErrorCode, responseErr.StatusCode)
+	}
+
+	return fmt.Errorf("%w: %w", errMsgPrefix, err)
+}
+
+func (s *azureObjectStore) UploadObject(key string, uploadContent interface{}) error {
+	encodedUploadContent, err := encodeObject(s.container, key, uploadContent)
+	if err != nil {
+		return err
+	}
+
+	ctx, cancel := context.WithDeadline(context.TODO(), time.Now().Add(s3Timeout))
+	defer cancel()
+
+	start := time.Now()
+	uploadSize := int64(encodedUploadContent.Len())
+
+	if _, err := s.client.NewBlockBlobClient(key).Upload(ctx,
+		streaming.NopCloser(bytes.NewReader(encodedUploadContent.Bytes())), nil,
+	); err != nil {
+		s3OperationFailed(s.name, s3OperationUpload, start, err)
+
+		return processAzureError(fmt.Errorf("failed to upload data of %s:%s", s.container, key), err)
+	}
+
+	s3OperationSucceeded(s.name, s3OperationUpload, start, uploadSize)
+
+	return nil
+}
+
+func (s *azureObjectStore) DownloadObject(key string, downloadContent interface{}) error {
+	ctx, cancel := context.WithDeadline(context.TODO(), time.Now().Add(s3Timeout))
+	defer cancel()
+
+	start := time.Now()
+
+	response, err := s.client.NewBlobClient(key).DownloadStream(ctx, nil)
+	if err != nil {
+		s3OperationFailed(s.name, s3OperationDownload, start, err)
+
+		return processAzureError(fmt.Errorf("failed to download data of %s:%s", s.container, key), err)
+	}
+
+	defer response.Body.Close()
+
+	data, err := io.ReadAll(response.Body)
+	if err != nil {
+		s3OperationFailed(s.name, s3OperationDownload, start, err)
+
+		return fmt.Errorf("failed to read data of %s:%s, %w", s.container, key, err)
+	}
+
+	s3OperationSucceeded(s.name, s3OperationDownload, start, int64(len(data)))
+
+	return decodeObject(s.container, key, data, downloadContent)
+}
+
+func (s *azureObjectStore) ListKeys(keyPrefix string) (keys []string, err error) {
+	ctx, cancel := context.WithDeadline(context.TODO(), time.Now().Add(s3Timeout))
+	defer cancel()
+
+	start := time.Now()
+
+	pager := s.client.NewListBlobsFlatPager(&container.ListBlobsFlatOptions{Prefix: &keyPrefix})
+	for pager.More() {
+		page, err := pager.NextPage(ctx)
+		if err != nil {
+			s3OperationFailed(s.name, s3OperationList, start, err)
+
+			return nil, processAzureError(fmt.Errorf("failed to list objects in container"), err)
+		}
+
+		for _, item := range page.Segment.BlobItems {
+			keys = append(keys, *item.Name)
+		}
+	}
+
+	s3OperationSucceeded(s.name, s3OperationList, start, 0)
+
+	return keys, nil
+}
+
+// DeleteObject deletes the blob with the given key; does not return an error
+// if the blob does not exist, as is the case for S3.
+func (s *azureObjectStore) DeleteObject(key string) error {
+	ctx, cancel := context.WithDeadline(context.TODO(), time.Now().Add(s3Timeout))
+	defer cancel()
+
+	if _, err := s.client.NewBlobClient(key).Delete(ctx, nil); err != nil &&
+		!bloberror.HasCode(err, bloberror.BlobNotFound) {
+		return processAzureError(fmt.Errorf("failed to delete object %s", key), err)
+	}
+
+	return nil
+}
+
+func (s *azureObjectStore) DeleteObjects(keys ...string) error {
+	start := time.Now()
+
+	errs := make([]error, 0, len(keys))
+
+	for _, key := range keys {
+		if err := s.DeleteObject(key); err != nil {
+			errs = append(errs, err)
+		}
+	}
+
+	if err := errors.Join(errs...); err != nil {
+		s3OperationFailed(s.name, s3OperationDelete, start, err)
+
+		return fmt.Errorf("unable to process batch delete, %w", err)
+	}
+
+	s3OperationSucceeded(s.name, s3OperationDelete, start, 0)
+
+	return nil
+}
+
+func (s *azureObjectStore) DeleteObjectsWithKeyPrefix(keyPrefix string) error {
+	keys, err := s.ListKeys(keyPrefix)
+	if err != nil {
+		return fmt.Errorf("unable to ListKeys in DeleteObjects "+
+			"from endpoint %s container %s keyPrefix %s, %w",
+			s.endpoint, s.container, keyPrefix, err)
+	}
+
+	if err = s.DeleteObjects(keys...); err != nil {
+		return fmt.Errorf("unable to DeleteObjects "+
+			"from endpoint %s container %s keyPrefix %s, %w",
+			s.endpoint, s.container, keyPrefix, err)
+	}
+
+	return nil
+}
+
+// azureErrorCode returns the error code of the given Azure response error.
+func azureErrorCode(err error) (string, bool) {
+	var responseErr *azcore.ResponseError
+	if !errors.As(err, &responseErr) || responseErr.ErrorCode == "" {
+		return "", false
+	}
+
+	return responseErr.ErrorCode, true
+}
@@ -5,9 +5,13 @@ package cephfscg
 
 import (
 	"context"
+	"fmt"
 	"time"
 
+	vgsv1beta1 "github.com/red-hat-storage/external-snapshotter/client/v8/apis/volumegroupsnapshot/v1beta1"
+	k8serrors "k8s.io/apimachinery/pkg/api/errors"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/util/wait"
 
 	"github.com/backube/volsync/controllers/mover"
 	"github.com/backube/volsync/controllers/statemachine"
@@ -17,19 +21,27 @@ import (
 	"sigs.k8s.io/controller-runtime/pkg/client"
 )
 
+// volumeGroupSnapshotCutTimeout bounds how long the applications are frozen
+// for a volume group snapshot to be cut.
+const volumeGroupSnapshotCutTimeout = 30 * time.Second
+
 type replicationGroupSourceMachine struct {
 	client.Client
 	ReplicationGroupSource *ramendrv1alpha1.ReplicationGroupSource
 	VSHandler              *volsync.VSHandler // VSHandler will be used to call the exist funcs
 	VolumeGroupHandler     VolumeGroupSourceHandler
+	Freezer                volsync.Freezer
 	Logger                 logr.Logger
 }
 
+// NewRGSMachine returns a ReplicationGroupSource state machine, whose volume
+// group snapshots the given freezer, if not nil, freezes the applications for.
 func NewRGSMachine(
 	client client.Client,
 	replicationGroupSource *ramendrv1alpha1.ReplicationGroupSource,
 	vsHandler *volsync.VSHandler,
 	volumeGroupHandler VolumeGroupSourceHandler,
+	freezer volsync.Freezer,
 	logger logr.Logger,
 ) statemachine.ReplicationMachine {
 	return &replicationGroupSourceMachine{
@@ -37,6 +49,7 @@ func NewRGSMachine(
 		ReplicationGroupSource: replicationGroupSource,
 		VSHandler:              vsHandler,
 		VolumeGroupHandler:     volumeGroupHandler,
+		Freezer:                freezer,
 		Logger:                 logger.WithName("ReplicationGroupSourceMachine"),
 	}
 }
@@ -105,9 +118,7 @@ func (m *replicationGroupSourceMachine) Conditions() *[]metav1.Condition {
 func (m *replicationGroupSourceMachine) Synchronize(ctx context.Context) (mover.Result, error) {
 	m.Logger.Info("Create volume group snapshot")
 
-	if err := m.VolumeGroupHandler.CreateOrUpdateVolumeGroupSnapshot(
-		ctx, m.ReplicationGroupSource,
-	); err != nil {
+	if err := m.volumeGroupSnapshotCreate(ctx); err != nil {
 		m.Logger.Error(err, "Failed to create volume group snapshot")
 
 		return mover.InProgress(), err
@@ -181,6 +192,58 @@ func (m *replicationGroupSourceMachine) Synchronize(ctx context.Context) (mover.
 	return mover.Complete(), nil
 }
 
+// volumeGroupSnapshotCreate creates or updates the volume group snapshot.  A
+// snapshot that does not exist yet is created with the applications frozen,
+// if there is a freezer, and they are unfrozen once it is cut, or it fails to
+// be, in this reconcile, rather than left frozen for the rest of the sync.
+func (m *replicationGroupSourceMachine) volumeGroupSnapshotCreate(ctx context.Context) error {
+	if m.Freezer == nil {
+		return m.VolumeGroupHandler.CreateOrUpdateVolumeGroupSnapshot(ctx, m.ReplicationGroupSource)
+	}
+
+	vgs := &vgsv1beta1.VolumeGroupSnapshot{}
+
+	err := m.Client.Get(ctx, client.ObjectKeyFromObject(m.ReplicationGroupSource), vgs)
+	if err == nil {
+		return m.VolumeGroupHandler.CreateOrUpdateVolumeGroupSnapshot(ctx, m.ReplicationGroupSource)
+	}
+
+	if !k8serrors.IsNotFound(err) {
+		return fmt.Errorf("volume group snapshot get: %w", err)
+	}
+
+	if err := m.Freezer.Freeze(ctx); err != nil {
+		return fmt.Errorf("freeze for volume group snapshot: %w", err)
+	}
+
+	defer func() {
+		if err := m.Freezer.Unfreeze(ctx); err != nil {
+			m.Logger.Error(err, "Failed to unfreeze after volume group snapshot")
+		}
+	}()
+
+	if err := m.VolumeGroupHandler.CreateOrUpdateVolumeGroupSnapshot(ctx, m.ReplicationGroupSource); err != nil {
+		return err
+	}
+
+	return m.volumeGroupSnapshotCutWait(ctx, vgs)
+}
+
+// volumeGroupSnapshotCutWait waits for the volume group snapshot's point in
+// time, its creation time, to be set.
+func (m *replicationGroupSourceMachine) volumeGroupSnapshotCutWait(ctx context.Context,
+	vgs *vgsv1beta1.VolumeGroupSnapshot,
+) error {
+	return wait.PollUntilContextTimeout(ctx, time.Second, volumeGroupSnapshotCutTimeout, true,
+		func(ctx context.Context) (bool, error) {
+			if err := m.Client.Get(ctx, client.ObjectKeyFromObject(m.ReplicationGroupSource), vgs); err != nil {
+				return false, client.IgnoreNotFound(err)
+			}
+
+			return vgs.Status != nil && vgs.Status.CreationTime != nil, nil
+		})
+}
+
 func (m *replicationGroupSourceMachine) Cleanup(ctx context.Context) (mover.Result, error) {
 	m.Logger.Info("Clean Replication Group Source")
 
@@ -16,9 +16,13 @@ import (
 	controllers "github.com/ramendr/ramen/internal/controller"
 	"github.com/ramendr/ramen/internal/controller/cephfscg"
 	"github.com/ramendr/ramen/internal/controller/volsync"
+	vgsv1beta1 "github.com/red-hat-storage/external-snapshotter/client/v8/apis/volumegroupsnapshot/v1beta1"
 	corev1 "k8s.io/api/core/v1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/utils/ptr"
 	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
 )
 
 var rgsName = "rgs"
@@ -45,7 +49,7 @@ var _ = Describe("Replicationgroupsource", func() {
 			k8sClient, rgs, volsync.NewVSHandler(context.Background(), k8sClient, testLogger, rgs,
 				&ramendrv1alpha1.VRGAsyncSpec{}, controllers.DefaultCephFSCSIDriverName,
 				controllers.DefaultVolSyncCopyMethod, false,
-			), fakeVolumeGroupSourceHandler, testLogger,
+			), fakeVolumeGroupSourceHandler, nil, testLogger,
 		)
 	})
 	Describe("Synchronize", func() {
@@ -71,6 +75,45 @@ var _ = Describe("Replicationgroupsource", func() {
 			})
 		})
 	})
+	Describe("Synchronize with a freezer", func() {
+		It("freezes for a new volume group snapshot only, until it is cut", func() {
+			scheme := runtime.NewScheme()
+			Expect(corev1.AddToScheme(scheme)).To(Succeed())
+			Expect(ramendrv1alpha1.AddToScheme(scheme)).To(Succeed())
+			Expect(vgsv1beta1.AddToScheme(scheme)).To(Succeed())
+
+			rgs := &ramendrv1alpha1.ReplicationGroupSource{
+				ObjectMeta: metav1.ObjectMeta{Name: rgsName, Namespace: "default"},
+			}
+			c := fake.NewClientBuilder().WithScheme(scheme).Build()
+			freezer := &fakeVolumeGroupFreezer{}
+
+			handler := &fakes.FakeVolumeGroupSourceHandler{}
+			handler.CreateOrUpdateVolumeGroupSnapshotStub = func(ctx context.Context, _ metav1.Object) error {
+				Expect(freezer.frozen).To(Equal(1))
+
+				return client.IgnoreAlreadyExists(c.Create(ctx, &vgsv1beta1.VolumeGroupSnapshot{
+					ObjectMeta: metav1.ObjectMeta{Name: rgsName, Namespace: "default"},
+					Status:     &vgsv1beta1.VolumeGroupSnapshotStatus{CreationTime: ptr.To(metav1.Now())},
+				}))
+			}
+
+			machine := cephfscg.NewRGSMachine(c, rgs, volsync.NewVSHandler(context.Background(), c, testLogger, rgs,
+				&ramendrv1alpha1.VRGAsyncSpec{}, controllers.DefaultCephFSCSIDriverName,
+				controllers.DefaultVolSyncCopyMethod, false,
+			), handler, freezer, testLogger)
+
+			_, err := machine.Synchronize(context.Background())
+			Expect(err).To(BeNil())
+			Expect(handler.CreateOrUpdateVolumeGroupSnapshotCallCount()).To(Equal(1))
+			Expect(*freezer).To(Equal(fakeVolumeGroupFreezer{frozen: 1, unfrozen: 1}))
+
+			_, err = machine.Synchronize(context.Background())
+			Expect(err).To(BeNil())
+			Expect(handler.CreateOrUpdateVolumeGroupSnapshotCallCount()).To(Equal(2))
+			Expect(*freezer).To(Equal(fakeVolumeGroupFreezer{frozen: 1, unfrozen: 1}))
+		})
+	})
 	Describe("Cleanup", func() {
 		Context("CleanVolumeGroupSnapshotReturns nil", func() {
 			It("Should be success", func() {
@@ -89,3 +132,20 @@ var _ = Describe("Replicationgroupsource", func() {
 		})
 	})
 })
+
+// fakeVolumeGroupFreezer counts the times it freezes and unfreezes.
+type fakeVolumeGroupFreezer struct {
+	frozen, unfrozen int
+}
+
+func (f *fakeVolumeGroupFreezer) Freeze(context.Context) error {
+	f.frozen++
+
+	return nil
+}
+
+func (f *fakeVolumeGroupFreezer) Unfreeze(context.Context) error {
+	f.unfrozen++
+
+	return nil
+}
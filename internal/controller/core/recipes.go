@@ -5,6 +5,9 @@ package core
 
 // spec.groups.includeResourceTypes is skipped which infers include all resource types for group workflow(backup/restore) operation
 // spec.groups.essential is skipped which infers group workflow action should succeed else stop further processing of workflow and initiate rollback
+// spec.hooks vm-freeze freezes the guest filesystems of the protected vms, best effort, while their resources are backed
+// up, and while their volumes are copied for a sync, as a volume group snapshot of their consistency group or by
+// VolSync, and unfreezes them once they are, or KubeVirt does after 5m should they not be
 const (
 	VMRecipeName         = "vm-recipe"
 	VMList               = "PROTECTED_VMS"
@@ -12,6 +15,8 @@ const (
 	PVCLabelSelector     = "PVC_RESOURCE_SELECTOR"
 	VMLabelSelector      = "ramendr.openshift.io/k8s-resource-selector"
 	ProtectedVMNamespace = "VM_NAMESPACE"
+	VMFreezeHookName     = "vm-freeze"
+	VMFreezeOpName       = "freeze"
 	VMRecipe             = `
 apiVersion: ramendr.openshift.io/v1alpha1
 kind: Recipe
@@ -40,11 +45,29 @@ spec:
         - ${K8S_RESOURCE_SELECTOR}
     name: vm-backup
     type: resource
+  hooks:
+  - name: vm-freeze
+    type: vm
+    labelSelector:
+      matchExpressions:
+      - key: ramendr.openshift.io/k8s-resource-selector
+        operator: In
+        values:
+        - ${K8S_RESOURCE_SELECTOR}
+    onError: continue
+    ops:
+    - name: freeze
+      command: freeze
+      inverseOp: unfreeze
+    - name: unfreeze
+      command: unfreeze
   workflows:
   - failOn: any-error
     name: backup
     sequence:
+    - hook: vm-freeze/freeze
     - group: vm-backup
+    - hook: vm-freeze/unfreeze
   - failOn: any-error
     name: restore
     sequence:
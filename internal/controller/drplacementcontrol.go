@@ -1820,6 +1820,7 @@ func (d *DRPCInstance) updateVRGOptionalFields(vrg, vrgFromView *rmn.VolumeRepli
 	}
 
 	vrg.Spec.ProtectedNamespaces = d.instance.Spec.ProtectedNamespaces
+	vrg.Spec.NamespaceMappings = d.instance.Spec.NamespaceMappings
 	vrg.Spec.S3Profiles = AvailableS3Profiles(d.drClusters)
 	vrg.Spec.KubeObjectProtection = d.instance.Spec.KubeObjectProtection
 	vrg.Spec.VolSync.Disabled = d.volSyncDisabled
@@ -2548,6 +2549,11 @@ func (d *DRPCInstance) shouldUpdateStatus() bool {
 		}
 	}
 
+	if !reflect.DeepEqual(d.instance.Status.ResourceConditions.ResourceMeta.HookResults,
+		hookResultsSummarize(vrg.Status.KubeObjectProtection.HookResults)) {
+		return true
+	}
+
 	return !reflect.DeepEqual(d.instance.Status.ResourceConditions.Conditions, vrg.Status.Conditions)
 }
 
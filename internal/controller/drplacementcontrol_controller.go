@@ -1386,6 +1386,8 @@ func (r *DRPlacementControlReconciler) updateResourceCondition(
 	}
 
 	drpc.Status.ResourceConditions.ResourceMeta.ProtectedPVCs = protectedPVCs
+	drpc.Status.ResourceConditions.ResourceMeta.HookResults = hookResultsSummarize(
+		vrg.Status.KubeObjectProtection.HookResults)
 
 	if rmnutil.IsCGEnabled(vrg.GetAnnotations()) {
 		drpc.Status.ResourceConditions.ResourceMeta.PVCGroups = vrg.Status.PVCGroups
@@ -67,6 +67,7 @@ func drClusterSecretsDeploy(
 			drClusterOperatorNamespaceNameOrDefault(rmnCfg),
 			util.SecretFormatRamen,
 			"",
+			s3SecretKeys(secretName, rmnCfg),
 		); err != nil {
 			return fmt.Errorf("cannot add secret '%v' to drcluster '%v': %w", secretName, clusterName, err)
 		}
@@ -79,6 +80,7 @@ func drClusterSecretsDeploy(
 				drClusterOperatorNamespaceNameOrDefault(rmnCfg),
 				util.SecretFormatVelero,
 				rmnCfg.KubeObjectProtection.VeleroNamespaceName,
+				nil,
 			); err != nil {
 				return fmt.Errorf("cannot add secret '%v' to drcluster '%v' in format '%v': %w",
 					secretName, clusterName, util.SecretFormatVelero, err)
@@ -170,7 +172,7 @@ func drClusterListMustHaveSecrets(
 	// Determine s3Secrets that must continue to exist on the cluster, based on other profiles
 	// that should still be present. This is done as multiple profiles MAY point to the same secret
 	for _, s3Profile := range ramenConfig.S3StoreProfiles {
-		if mustHaveS3Profiles.Has(s3Profile.S3ProfileName) {
+		if mustHaveS3Profiles.Has(s3Profile.S3ProfileName) && s3StoreProfileUsesSecret(&s3Profile) {
 			mustHaveS3Secrets = mustHaveS3Secrets.Insert(s3Profile.S3SecretRef.Name)
 		}
 	}
@@ -221,7 +223,10 @@ func drPolicySecretNames(drpolicy *rmn.DRPolicy,
 
 		for _, s3Profile := range rmnCfg.S3StoreProfiles {
 			if s3ProfileName == s3Profile.S3ProfileName {
-				secretNames.Insert(s3Profile.S3SecretRef.Name)
+				// Profiles with short-lived credentials have no secret to distribute
+				if s3StoreProfileUsesSecret(&s3Profile) {
+					secretNames.Insert(s3Profile.S3SecretRef.Name)
+				}
 
 				mcProfileFound = true
 
@@ -237,6 +242,22 @@ func drPolicySecretNames(drpolicy *rmn.DRPolicy,
 	return secretNames, err
 }
 
+// s3SecretKeys returns the keys of the given secret that the S3 profiles that
+// refer to it read, according to their object store providers.
+func s3SecretKeys(secretName string, rmnCfg *rmn.RamenConfig) []string {
+	secretKeys := sets.String{}
+
+	for i := range rmnCfg.S3StoreProfiles {
+		s3Profile := &rmnCfg.S3StoreProfiles[i]
+
+		if s3Profile.S3SecretRef.Name == secretName && s3StoreProfileUsesSecret(s3Profile) {
+			secretKeys.Insert(util.ObjectStoreSecretKeys(objectStoreProvider(s3Profile))...)
+		}
+	}
+
+	return secretKeys.List()
+}
+
 // Delete s3profile secret from cluster
 func deleteSecretFromCluster(
 	s3SecretToDelete, clusterName string,
@@ -0,0 +1,332 @@
+// SPDX-FileCopyrightText: The RamenDR authors
+// SPDX-License-Identifier: Apache-2.0
+
+package controllers
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"strconv"
+	"sync"
+	"time"
+
+	"cloud.google.com/go/storage"
+	ramen "github.com/ramendr/ramen/api/v1alpha1"
+	"github.com/ramendr/ramen/internal/controller/util"
+	"google.golang.org/api/googleapi"
+	"google.golang.org/api/iterator"
+	"google.golang.org/api/option"
+	htransport "google.golang.org/api/transport/http"
+	corev1 "k8s.io/api/core/v1"
+)
+
+// gcsObjectStore is an ObjectStorer of a Google Cloud Storage bucket, with the
+// same object encoding and key layout as an S3 object store.
+type gcsObjectStore struct {
+	connection *gcsConnection
+	bucket     *storage.BucketHandle
+	endpoint   string
+	bucketName string
+	callerTag  string
+	name       string
+}
+
+// errGCSConnectionClosed is returned by the operations of an object store
+// whose pooled connection was closed, which a retry re-establishes.
+var errGCSConnectionClosed = errors.New("gcs connection closed")
+
+// gcsConnection is the client shared by the copies of a GCS object store. It
+// is closed when its pool entry is invalidated, once the operations in flight
+// complete, since a closed client panics when used.
+type gcsConnection struct {
+	mutex  sync.RWMutex
+	client *storage.Client
+	closed bool
+}
+
+// acquire returns an error if the connection is closed, and otherwise holds it
+// open until released.
+func (c *gcsConnection) acquire() error {
+	c.mutex.RLock()
+
+	if c.closed {
+		c.mutex.RUnlock()
+
+		return errGCSConnectionClosed
+	}
+
+	return nil
+}
+
+func (c *gcsConnection) release() {
+	c.mutex.RUnlock()
+}
+
+func (c *gcsConnection) close() error {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+
+	if c.closed {
+		return nil
+	}
+
+	c.closed = true
+
+	return c.client.Close()
+}
+
+// newGCSObjectStore creates a client, authenticated with the service account
+// key in the given secret, of the bucket of the given profile.  A secret
+// without a key is accepted for an emulator, i.e. if the profile has an
+// endpoint.
+func newGCSObjectStore(profileName string, s3StoreProfile ramen.S3StoreProfile, secret *corev1.Secret,
+) (*gcsObjectStore, error) {
+	ctx := context.Background()
+	endpoint := s3StoreProfile.S3CompatibleEndpoint
+	options := []option.ClientOption{}
+
+	switch serviceAccountKey := secret.Data[util.SecretKeyGoogleServiceAccountKey]; {
+	case len(serviceAccountKey) != 0:
+		options = append(options, option.WithCredentialsJSON(serviceAccountKey))
+	case endpoint != "":
+		options = append(options, option.WithoutAuthentication())
+	default:
+		return nil, fmt.Errorf("secret %s/%s lacks GOOGLE_SERVICE_ACCOUNT_KEY", secret.Namespace, secret.Name)
+	}
+
+	if endpoint != "" {
+		options = append(options, option.WithEndpoint(endpoint))
+	}
+
+	if len(s3StoreProfile.CACertificates) != 0 {
+		httpClient, err := s3HTTPClient(s3StoreProfile.CACertificates)
+		if err != nil {
+			return nil, err
+		}
+
+		// An HTTP client option overrides the authentication options, hence
+		// authenticate its transport instead
+		transport, err := htransport.NewTransport(ctx, httpClient.Transport, options...)
+		if err != nil {
+			return nil, fmt.Errorf("failed to create transport, %w", err)
+		}
+
+		options = append(options, option.WithHTTPClient(&http.Client{Transport: transport}))
+	}
+
+	// Read with the JSON API, like all other operations, as the XML API is not
+	// served by emulators at the overridden endpoint
+	client, err := storage.NewClient(ctx, append(options, storage.WithJSONReads())...)
+	if err != nil {
+		return nil, err
+	}
+
+	return &gcsObjectStore{
+		connection: &gcsConnection{client: client},
+		bucket:     client.Bucket(s3StoreProfile.S3Bucket),
+		endpoint:   endpoint,
+		bucketName: s3StoreProfile.S3Bucket,
+		name:       profileName,
+	}, nil
+}
+
+func (s *gcsObjectStore) withCallerTag(callerTag string) ObjectStorer {
+	s1 := *s
+	s1.callerTag = callerTag
+
+	return &s1
+}
+
+func (s *gcsObjectStore) close() error {
+	return s.connection.close()
+}
+
+// processGCSError returns the given error prefix, annotated with the status
+// code of the given error if it is a Google API error.
+func processGCSError(errMsgPrefix, err error) error {
+	var apiErr *googleapi.Error
+	if errors.As(err, &apiErr) {
+		return fmt.Errorf("%w: code: %d, message: %s", errMsgPrefix, apiErr.Code, apiErr.Message)
+	}
+
+	return fmt.Errorf("%w: %w", errMsgPrefix, err)
+}
+
+func (s *gcsObjectStore) UploadObject(key string, uploadContent interface{}) error {
+	encodedUploadContent, err := encodeObject(s.bucketName, key, uploadContent)
+	if err != nil {
+		return err
+	}
+
+	if err := s.connection.acquire(); err != nil {
+		return err
+	}
+
+	defer s.connection.release()
+
+	ctx, cancel := context.WithDeadline(context.TODO(), time.Now().Add(s3Timeout))
+	defer cancel()
+
+	start := time.Now()
+	uploadSize := int64(encodedUploadContent.Len())
+
+	writer := s.bucket.Object(key).NewWriter(ctx)
+
+	_, err = io.Copy(writer, encodedUploadContent)
+	if closeErr := writer.Close(); err == nil {
+		err = closeErr
+	}
+
+	if err != nil {
+		s3OperationFailed(s.name, s3OperationUpload, start, err)
+
+		return processGCSError(fmt.Errorf("failed to upload data of %s:%s", s.bucketName, key), err)
+	}
+
+	s3OperationSucceeded(s.name, s3OperationUpload, start, uploadSize)
+
+	return nil
+}
+
+func (s *gcsObjectStore) DownloadObject(key string, downloadContent interface{}) error {
+	if err := s.connection.acquire(); err != nil {
+		return err
+	}
+
+	defer s.connection.release()
+
+	ctx, cancel := context.WithDeadline(context.TODO(), time.Now().Add(s3Timeout))
+	defer cancel()
+
+	start := time.Now()
+
+	reader, err := s.bucket.Object(key).NewReader(ctx)
+	if err != nil {
+		s3OperationFailed(s.name, s3OperationDownload, start, err)
+
+		return processGCSError(fmt.Errorf("failed to download data of %s:%s", s.bucketName, key), err)
+	}
+
+	defer reader.Close()
+
+	data, err := io.ReadAll(reader)
+	if err != nil {
+		s3OperationFailed(s.name, s3OperationDownload, start, err)
+
+		return fmt.Errorf("failed to read data of %s:%s, %w", s.bucketName, key, err)
+	}
+
+	s3OperationSucceeded(s.name, s3OperationDownload, start, int64(len(data)))
+
+	return decodeObject(s.bucketName, key, data, downloadContent)
+}
+
+func (s *gcsObjectStore) ListKeys(keyPrefix string) (keys []string, err error) {
+	if err := s.connection.acquire(); err != nil {
+		return nil, err
+	}
+
+	defer s.connection.release()
+
+	ctx, cancel := context.WithDeadline(context.TODO(), time.Now().Add(s3Timeout))
+	defer cancel()
+
+	start := time.Now()
+
+	query := &storage.Query{Prefix: keyPrefix}
+	if err := query.SetAttrSelection([]string{"Name"}); err != nil {
+		return nil, fmt.Errorf("failed to select attributes to list, %w", err)
+	}
+
+	objects := s.bucket.Objects(ctx, query)
+
+	for {
+		attrs, err := objects.Next()
+		if errors.Is(err, iterator.Done) {
+			break
+		}
+
+		if err != nil {
+			s3OperationFailed(s.name, s3OperationList, start, err)
+
+			return nil, processGCSError(fmt.Errorf("failed to list objects in bucket"), err)
+		}
+
+		keys = append(keys, attrs.Name)
+	}
+
+	s3OperationSucceeded(s.name, s3OperationList, start, 0)
+
+	return keys, nil
+}
+
+// DeleteObject deletes the object with the given key; does not return an
+// error if the object does not exist, as is the case for S3.
+func (s *gcsObjectStore) DeleteObject(key string) error {
+	if err := s.connection.acquire(); err != nil {
+		return err
+	}
+
+	defer s.connection.release()
+
+	ctx, cancel := context.WithDeadline(context.TODO(), time.Now().Add(s3Timeout))
+	defer cancel()
+
+	if err := s.bucket.Object(key).Delete(ctx); err != nil && !errors.Is(err, storage.ErrObjectNotExist) {
+		return processGCSError(fmt.Errorf("failed to delete object %s", key), err)
+	}
+
+	return nil
+}
+
+func (s *gcsObjectStore) DeleteObjects(keys ...string) error {
+	start := time.Now()
+
+	errs := make([]error, 0, len(keys))
+
+	for _, key := range keys {
+		if err := s.DeleteObject(key); err != nil {
+			errs = append(errs, err)
+		}
+	}
+
+	if err := errors.Join(errs...); err != nil {
+		s3OperationFailed(s.name, s3OperationDelete, start, err)
+
+		return fmt.Errorf("unable to process batch delete, %w", err)
+	}
+
+	s3OperationSucceeded(s.name, s3OperationDelete, start, 0)
+
+	return nil
+}
+
+func (s *gcsObjectStore) DeleteObjectsWithKeyPrefix(keyPrefix string) error {
+	keys, err := s.ListKeys(keyPrefix)
+	if err != nil {
+		return fmt.Errorf("unable to ListKeys in DeleteObjects "+
+			"from endpoint %s bucket %s keyPrefix %s, %w",
+			s.endpoint, s.bucketName, keyPrefix, err)
+	}
+
+	if err = s.DeleteObjects(keys...); err != nil {
+		return fmt.Errorf("unable to DeleteObjects "+
+			"from endpoint %s bucket %s keyPrefix %s, %w",
+			s.endpoint, s.bucketName, keyPrefix, err)
+	}
+
+	return nil
+}
+
+// gcsErrorCode returns the HTTP status code of the given Google API error.
+func gcsErrorCode(err error) (string, bool) {
+	var apiErr *googleapi.Error
+	if !errors.As(err, &apiErr) {
+		return "", false
+	}
+
+	return strconv.Itoa(apiErr.Code), true
+}
@@ -0,0 +1,94 @@
+// SPDX-FileCopyrightText: The RamenDR authors
+// SPDX-License-Identifier: Apache-2.0
+
+package hooks
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/google/cel-go/cel"
+	"github.com/google/cel-go/common/types"
+)
+
+const (
+	// CELConditionPrefix prefixes a check condition that is a CEL expression,
+	// rather than a JSONPath one, e.g. "cel: object.status.readyReplicas ==
+	// object.spec.replicas".
+	CELConditionPrefix = "cel:"
+
+	// celObjectVariable names the checked resource in a CEL condition
+	celObjectVariable = "object"
+
+	// celCostLimit limits the cost of an evaluation of a CEL condition, as
+	// Kubernetes does that of a validation rule, and celEvaluateTimeout its
+	// duration, which is checked every celInterruptCheckFrequency iterations of
+	// its comprehensions.
+	celCostLimit               = 1000000
+	celEvaluateTimeout         = 5 * time.Second
+	celInterruptCheckFrequency = 100
+)
+
+func isCELCondition(condition string) bool {
+	return strings.HasPrefix(strings.TrimSpace(condition), CELConditionPrefix)
+}
+
+// celProgramNew compiles the given CEL condition, whose result must be a bool.
+func celProgramNew(condition string) (cel.Program, error) {
+	expression := strings.TrimSpace(strings.TrimPrefix(strings.TrimSpace(condition), CELConditionPrefix))
+
+	env, err := cel.NewEnv(cel.Variable(celObjectVariable, cel.DynType))
+	if err != nil {
+		return nil, fmt.Errorf("cel environment create: %w", err)
+	}
+
+	ast, issues := env.Compile(expression)
+	if issues != nil && issues.Err() != nil {
+		return nil, fmt.Errorf("cel condition %q compile: %w", expression, issues.Err())
+	}
+
+	if outputType := ast.OutputType(); outputType != cel.BoolType && outputType != cel.DynType {
+		return nil, fmt.Errorf("cel condition %q type is %v, not bool", expression, outputType)
+	}
+
+	program, err := env.Program(ast, cel.CostLimit(celCostLimit),
+		cel.InterruptCheckFrequency(celInterruptCheckFrequency))
+	if err != nil {
+		return nil, fmt.Errorf("cel condition %q program create: %w", expression, err)
+	}
+
+	return program, nil
+}
+
+// CheckConditionValidate returns an error if the given check condition is a
+// CEL expression that does not compile to a bool.
+func CheckConditionValidate(condition string) error {
+	if !isCELCondition(condition) {
+		return nil
+	}
+
+	_, err := celProgramNew(condition)
+
+	return err
+}
+
+// celProgramEvaluate evaluates the given compiled CEL condition on the given
+// JSON data.
+func celProgramEvaluate(program cel.Program, condition string, jsonData interface{}) (bool, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), celEvaluateTimeout)
+	defer cancel()
+
+	result, _, err := program.ContextEval(ctx, map[string]interface{}{celObjectVariable: jsonData})
+	if err != nil {
+		return false, fmt.Errorf("cel condition %q evaluate: %w", condition, err)
+	}
+
+	value, ok := result.(types.Bool)
+	if !ok {
+		return false, fmt.Errorf("cel condition %q result %v is not bool", condition, result)
+	}
+
+	return bool(value), nil
+}
@@ -8,36 +8,58 @@ import (
 	"encoding/json"
 	"fmt"
 	"regexp"
+	"strings"
 	"time"
 
 	"github.com/go-logr/logr"
+	"github.com/google/cel-go/cel"
 	"github.com/ramendr/ramen/internal/controller/kubeobjects"
 	appsv1 "k8s.io/api/apps/v1"
 	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/meta"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime/schema"
 	"sigs.k8s.io/controller-runtime/pkg/client"
 )
 
 type CheckHook struct {
-	Hook   *kubeobjects.HookSpec
-	Reader client.Reader
+	Hook       *kubeobjects.HookSpec
+	Reader     client.Reader
+	RESTMapper meta.RESTMapper
 }
 
 func (c CheckHook) Execute(log logr.Logger) error {
-	hookResult, err := EvaluateCheckHook(c.Reader, c.Hook, log)
+	_, err := c.ExecuteWithResult(log)
+
+	return err
+}
+
+func (c CheckHook) ExecuteWithResult(log logr.Logger) (Result, error) {
+	result := Result{}
+
+	hookResult, err := EvaluateCheckHook(c.Reader, c.RESTMapper, c.Hook, log)
 	if err != nil {
 		log.Error(err, "error occurred while evaluating check hook")
 
-		return err
+		result.Error = err
+
+		return result, err
 	}
 
+	result.CheckResult = &hookResult
+
 	hookName := c.Hook.Name + "/" + c.Hook.Chk.Name
 	log.Info("check hook executed successfully", "hook", hookName, "result", hookResult)
 
-	if !hookResult && shouldChkHookBeFailedOnError(c.Hook) {
-		return fmt.Errorf("stopping workflow as hook %s failed", c.Hook.Name)
+	if !hookResult {
+		result.Error = fmt.Errorf("check hook %s condition is false", hookName)
+
+		if shouldChkHookBeFailedOnError(c.Hook) {
+			return result, fmt.Errorf("stopping workflow as hook %s failed", c.Hook.Name)
+		}
 	}
 
-	return nil
+	return result, nil
 }
 
 func shouldChkHookBeFailedOnError(hook *kubeobjects.HookSpec) bool {
@@ -53,11 +75,18 @@ func shouldChkHookBeFailedOnError(hook *kubeobjects.HookSpec) bool {
 	return true
 }
 
-func EvaluateCheckHook(k8sReader client.Reader, hook *kubeobjects.HookSpec, log logr.Logger) (bool, error) {
+func EvaluateCheckHook(k8sReader client.Reader, restMapper meta.RESTMapper, hook *kubeobjects.HookSpec,
+	log logr.Logger,
+) (bool, error) {
 	if hook.LabelSelector == nil && hook.NameSelector == "" {
 		return false, fmt.Errorf("either nameSelector or labelSelector should be provided to get resources")
 	}
 
+	condition, err := checkConditionNew(hook.Chk.Condition)
+	if err != nil {
+		return false, err
+	}
+
 	timeout := getChkHookTimeoutValue(hook)
 
 	pollInterval := pInterval * time.Microsecond
@@ -73,7 +102,7 @@ func EvaluateCheckHook(k8sReader client.Reader, hook *kubeobjects.HookSpec, log
 		case <-ctx.Done():
 			return false, fmt.Errorf("timeout waiting for resource %s to be ready: %w", hook.NameSelector, ctx.Err())
 		case <-ticker.C:
-			objs, err := getResourcesList(k8sReader, hook, log)
+			objs, err := getResourcesList(k8sReader, restMapper, hook, log)
 			if err != nil {
 				return false, err // Some other error occurred, return it
 			}
@@ -85,7 +114,7 @@ func EvaluateCheckHook(k8sReader client.Reader, hook *kubeobjects.HookSpec, log
 				continue
 			}
 
-			return EvaluateCheckHookForObjects(objs, hook, log)
+			return evaluateCheckConditionForObjects(condition, objs, hook, log)
 		}
 	}
 
@@ -93,6 +122,19 @@ func EvaluateCheckHook(k8sReader client.Reader, hook *kubeobjects.HookSpec, log
 }
 
 func EvaluateCheckHookForObjects(objs []client.Object, hook *kubeobjects.HookSpec, log logr.Logger) (bool, error) {
+	condition, err := checkConditionNew(hook.Chk.Condition)
+	if err != nil {
+		return false, err
+	}
+
+	return evaluateCheckConditionForObjects(condition, objs, hook, log)
+}
+
+// evaluateCheckConditionForObjects evaluates the given hook's compiled check
+// condition on each of the given objects.
+func evaluateCheckConditionForObjects(condition checkCondition, objs []client.Object, hook *kubeobjects.HookSpec,
+	log logr.Logger,
+) (bool, error) {
 	finalRes := true
 
 	var err error
@@ -105,7 +147,7 @@ func EvaluateCheckHookForObjects(objs []client.Object, hook *kubeobjects.HookSpe
 			return false, err
 		}
 
-		res, err := EvaluateCheckHookExp(hook, data)
+		res, err := condition.evaluate(hook, data)
 		finalRes = finalRes && res
 
 		if err != nil {
@@ -137,10 +179,12 @@ func ConvertClientObjectToMap(obj client.Object) (map[string]interface{}, error)
 	return jsonData, nil
 }
 
-func getResourcesList(k8sReader client.Reader, hook *kubeobjects.HookSpec, log logr.Logger) ([]client.Object, error) {
+func getResourcesList(k8sReader client.Reader, restMapper meta.RESTMapper, hook *kubeobjects.HookSpec,
+	log logr.Logger,
+) ([]client.Object, error) {
 	resourceList := make([]client.Object, 0)
 
-	objList, err := getObjectListBasedOnResourceType(hook.SelectResource)
+	objList, err := getObjectListBasedOnResourceType(hook.SelectResource, restMapper)
 	if err != nil {
 		return resourceList, fmt.Errorf("error getting object list based on resource type: %w", err)
 	}
@@ -171,7 +215,7 @@ func getResourcesList(k8sReader client.Reader, hook *kubeobjects.HookSpec, log l
 	return resourceList, nil
 }
 
-func getObjectListBasedOnResourceType(selectResource string) (client.ObjectList, error) {
+func getObjectListBasedOnResourceType(selectResource string, restMapper meta.RESTMapper) (client.ObjectList, error) {
 	switch selectResource {
 	case podType:
 		return &corev1.PodList{}, nil
@@ -180,8 +224,36 @@ func getObjectListBasedOnResourceType(selectResource string) (client.ObjectList,
 	case statefulsetType:
 		return &appsv1.StatefulSetList{}, nil
 	default:
+		return getUnstructuredListForResource(selectResource, restMapper)
+	}
+}
+
+// getUnstructuredListForResource returns a list of the kind of the given
+// resource, named as "resource.group/version", e.g.
+// "postgresclusters.postgres-operator.crunchydata.com/v1beta1", "resource.group"
+// for the group's preferred version, or "resource" for a core resource.
+func getUnstructuredListForResource(selectResource string, restMapper meta.RESTMapper,
+) (*unstructured.UnstructuredList, error) {
+	if restMapper == nil {
+		return nil, fmt.Errorf("unsupported resource type %s", selectResource)
+	}
+
+	resource, version, _ := strings.Cut(selectResource, "/")
+	resource, group, _ := strings.Cut(resource, ".")
+
+	if resource == "" {
 		return nil, fmt.Errorf("unsupported resource type %s", selectResource)
 	}
+
+	gvk, err := restMapper.KindFor(schema.GroupVersionResource{Group: group, Version: version, Resource: resource})
+	if err != nil {
+		return nil, fmt.Errorf("resource type %s kind get: %w", selectResource, err)
+	}
+
+	objList := &unstructured.UnstructuredList{}
+	objList.SetGroupVersionKind(gvk.GroupVersion().WithKind(gvk.Kind + "List"))
+
+	return objList, nil
 }
 
 func getMatchingPods(pList *corev1.PodList, re *regexp.Regexp) []client.Object {
@@ -221,5 +293,39 @@ func getMatchingStatefulSets(ssList *appsv1.StatefulSetList, re *regexp.Regexp)
 }
 
 func EvaluateCheckHookExp(hook *kubeobjects.HookSpec, jsonData interface{}) (bool, error) {
-	return evaluateBooleanExpression(hook, hook.Chk.Condition, jsonData)
+	condition, err := checkConditionNew(hook.Chk.Condition)
+	if err != nil {
+		return false, err
+	}
+
+	return condition.evaluate(hook, jsonData)
+}
+
+// checkCondition is a check condition, a CEL expression, compiled once to be
+// evaluated on each resource a hook checks, or else a JSONPath one.
+type checkCondition struct {
+	condition string
+	program   cel.Program
+}
+
+func checkConditionNew(condition string) (checkCondition, error) {
+	if !isCELCondition(condition) {
+		return checkCondition{condition: condition}, nil
+	}
+
+	program, err := celProgramNew(condition)
+	if err != nil {
+		return checkCondition{}, err
+	}
+
+	return checkCondition{condition: condition, program: program}, nil
+}
+
+// evaluate evaluates the check condition on the given JSON data.
+func (c checkCondition) evaluate(hook *kubeobjects.HookSpec, jsonData interface{}) (bool, error) {
+	if c.program != nil {
+		return celProgramEvaluate(c.program, c.condition, jsonData)
+	}
+
+	return evaluateBooleanExpression(hook, c.condition, jsonData)
 }
@@ -10,10 +10,15 @@ import (
 
 	"github.com/ramendr/ramen/internal/controller/hooks"
 	"github.com/ramendr/ramen/internal/controller/kubeobjects"
+	"github.com/stretchr/testify/assert"
 	appsv1 "k8s.io/api/apps/v1"
 	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/meta"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime/schema"
 	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
 	"sigs.k8s.io/controller-runtime/pkg/log/zap"
 )
 
@@ -217,6 +222,49 @@ var testCasesObjectData = []testCasesObject{
 		result:  true,
 		jsonObj: getDeploymentContent(),
 	},
+	{
+		hook: getHookSpec("Deployment", "cel: object.spec.replicas == object.status.readyReplicas && "+
+			"object.status.conditions.exists(c, c.type == 'Progressing' && c.status == 'True')"),
+		result:  true,
+		jsonObj: getDeploymentContent(),
+	},
+	{
+		hook:    getHookSpec("Deployment", "cel: object.status.updatedReplicas > 1"),
+		result:  false,
+		jsonObj: getDeploymentContent(),
+	},
+	{
+		hook:    getHookSpec("Deployment", "cel: has(object.status.availableReplicas)"),
+		result:  false,
+		jsonObj: getDeploymentContent(),
+	},
+}
+
+func TestCheckConditionValidate(t *testing.T) {
+	for condition, valid := range map[string]bool{
+		"{$.spec.replicas} == {1}":                   true,
+		"cel: object.spec.replicas == 1":             true,
+		"cel: object.spec.replicas ==":               false,
+		"cel: size(object.metadata.name)":            false,
+		"cel: object.metadata.name.startsWith(1, 2)": false,
+	} {
+		if err := hooks.CheckConditionValidate(condition); (err == nil) != valid {
+			t.Errorf("CheckConditionValidate(%q) = %v, want valid %v", condition, err, valid)
+		}
+	}
+}
+
+func TestCheckHookCELConditionCostLimit(t *testing.T) {
+	items := make([]interface{}, 200)
+	for i := range items {
+		items[i] = int64(i)
+	}
+
+	hook := getHookSpec("Deployment",
+		"cel: object.items.all(x, object.items.all(y, object.items.all(z, x + y + z >= 0)))")
+
+	_, err := hooks.EvaluateCheckHookExp(hook, map[string]interface{}{"items": items})
+	assert.ErrorContains(t, err, "cost limit")
 }
 
 func getHookSpec(resourceType, condition string) *kubeobjects.HookSpec {
@@ -230,6 +278,56 @@ func getHookSpec(resourceType, condition string) *kubeobjects.HookSpec {
 	}
 }
 
+func getCustomResourceClient(t *testing.T, ready bool) client.Client {
+	t.Helper()
+
+	gvk := schema.GroupVersionKind{Group: "postgres-operator.crunchydata.com", Version: "v1beta1", Kind: "PostgresCluster"}
+
+	restMapper := meta.NewDefaultRESTMapper([]schema.GroupVersion{gvk.GroupVersion()})
+	restMapper.Add(gvk, meta.RESTScopeNamespace)
+
+	object := &unstructured.Unstructured{}
+	object.SetGroupVersionKind(gvk)
+	object.SetName("hippo")
+	object.SetNamespace("test-ns")
+	object.SetLabels(map[string]string{"appname": "hippo"})
+	assert.NoError(t, unstructured.SetNestedField(object.Object, ready, "status", "ready"))
+
+	return fake.NewClientBuilder().WithRESTMapper(restMapper).WithObjects(object).Build()
+}
+
+func TestCheckHookForCustomResource(t *testing.T) {
+	for _, selectResource := range []string{
+		"postgresclusters.postgres-operator.crunchydata.com/v1beta1",
+		"postgresclusters.postgres-operator.crunchydata.com",
+	} {
+		for _, ready := range []bool{true, false} {
+			c := getCustomResourceClient(t, ready)
+
+			hook := getHookSpec(selectResource, "cel: object.status.ready")
+			hook.Namespace = "test-ns"
+			hook.Timeout = 1
+			hook.NameSelector = "hip.*"
+
+			err := hooks.CheckHook{Hook: hook, Reader: c, RESTMapper: c.RESTMapper()}.Execute(zap.New())
+			assert.Equal(t, ready, err == nil, "%s ready %v: %v", selectResource, ready, err)
+
+			hook.NameSelector = ""
+			hook.LabelSelector = &metav1.LabelSelector{MatchLabels: map[string]string{"appname": "hippo"}}
+
+			err = hooks.CheckHook{Hook: hook, Reader: c, RESTMapper: c.RESTMapper()}.Execute(zap.New())
+			assert.Equal(t, ready, err == nil, "%s ready %v: %v", selectResource, ready, err)
+		}
+	}
+
+	c := getCustomResourceClient(t, true)
+	hook := getHookSpec("postgresclusters.unknown.io/v1", "cel: object.status.ready")
+	hook.NameSelector = "hippo"
+
+	err := hooks.CheckHook{Hook: hook, Reader: c, RESTMapper: c.RESTMapper()}.Execute(zap.New())
+	assert.Error(t, err)
+}
+
 func TestEvaluateCheckHookExp(t *testing.T) {
 	for i, tt := range testCasesData {
 		test := tt
@@ -7,9 +7,15 @@ import (
 	"bytes"
 	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
+	"maps"
 	"regexp"
+	"slices"
+	"strconv"
 	"strings"
+	"sync"
+	"sync/atomic"
 	"time"
 
 	"k8s.io/apimachinery/pkg/runtime"
@@ -24,10 +30,23 @@ import (
 	"k8s.io/apimachinery/pkg/fields"
 	"k8s.io/client-go/kubernetes"
 	"k8s.io/client-go/tools/remotecommand"
+	utilexec "k8s.io/client-go/util/exec"
+	"k8s.io/utils/ptr"
 	"sigs.k8s.io/controller-runtime/pkg/client"
 	"sigs.k8s.io/controller-runtime/pkg/client/config"
 )
 
+const (
+	// ExecHookConcurrencyAnnotationPrefix prefixes the name of a hook in the name
+	// of a Recipe annotation whose value is the maximum number of pods in which the
+	// commands of the hook's exec operations are executed at once, e.g.
+	// "exec-concurrency.hooks.ramendr.openshift.io/db: 4".  It defaults to 1,
+	// i.e. one pod at a time.
+	ExecHookConcurrencyAnnotationPrefix = "exec-concurrency.hooks.ramendr.openshift.io/"
+
+	defaultExecHookConcurrency = 1
+)
+
 type ExecHook struct {
 	Hook           *kubeobjects.HookSpec
 	Reader         client.Reader
@@ -45,72 +64,65 @@ type ExecPodSpec struct {
 // Execute uses exec hook definition provided in the recipe which will have identifiers to
 // execute a command on the pod(s) matching the criteria.
 func (e ExecHook) Execute(log logr.Logger) error {
-	if e.Hook.LabelSelector == nil && e.Hook.NameSelector == "" {
-		return fmt.Errorf("either nameSelector or labelSelector should be provided to get resources")
-	}
-
-	execPods := e.GetPodsToExecuteCommands(log)
-	inverseOp := e.Hook.Op.InverseOp
-
-	_, err := e.executeCommands(execPods, log)
-	if shouldInverseOpBeExecuted(inverseOp, e.Hook, err) {
-		e.executeInverseOp(inverseOp, log)
+	_, err := e.ExecuteWithResult(log)
 
-		return err
-	}
-
-	return nil
+	return err
 }
 
-func (e ExecHook) executeInverseOp(inverseOp string, log logr.Logger) {
-	var err error
+func (e ExecHook) ExecuteWithResult(log logr.Logger) (Result, error) {
+	result := Result{}
 
-	var inverseExecPod ExecPodSpec
+	if err := selectorsCheck(e.Hook); err != nil {
+		result.Error = err
 
-	hookSpecForInvHook := e.getHookSpecForInverseOp(inverseOp)
-	if hookSpecForInvHook != nil {
-		log.Info("executing inverse operation", "inverseOp", inverseOp, "namespace", hookSpecForInvHook.Namespace)
+		return result, err
+	}
 
-		tempE := ExecHook{
-			Hook:           hookSpecForInvHook,
-			Reader:         e.Reader,
-			Scheme:         e.Scheme,
-			RecipeElements: e.RecipeElements,
-		}
+	inverseOp := e.Hook.Op.InverseOp
 
-		execPods := tempE.GetPodsToExecuteCommands(log)
-		inverseExecPod, err = tempE.executeCommands(execPods, log)
-	}
+	err := e.operationRun(e.Hook, &result, log)
+	if shouldInverseOpBeExecuted(inverseOp, e.Hook, err) {
+		result.InverseOpExecuted = executeInverseOp(e.Hook, inverseOp, "exec", e.RecipeElements, e.operationRun, log)
 
-	if hookSpecForInvHook == nil {
-		log.Error(err, "inverse operation not found in recipe", "inverseOp", inverseOp)
+		return result, err
 	}
 
-	if err != nil {
-		log.Error(err, "error executing inverse operation", "inverseOp", inverseOp, "pod", inverseExecPod.PodName,
-			"namespace", inverseExecPod.Namespace, "command", inverseExecPod.Command)
-	}
+	return result, nil
+}
+
+// operationRun executes the command of the operation of the given hook, the
+// executor's or that of its inverse operation, in the pods it selects.
+func (e ExecHook) operationRun(hook *kubeobjects.HookSpec, result *Result, log logr.Logger) error {
+	e.Hook = hook
 
-	log.Info("executed inverse operation successfully", "inverseOp", inverseOp, "pod", inverseExecPod.PodName,
-		"namespace", inverseExecPod.Namespace, "command", inverseExecPod.Command)
+	return e.executeCommands(e.GetPodsToExecuteCommands(log), result, log)
 }
 
 func shouldInverseOpBeExecuted(inverseOp string, hookSpec *kubeobjects.HookSpec, err error) bool {
 	return err != nil && inverseOp != "" && shouldOpHookBeFailedOnError(hookSpec)
 }
 
-func (e ExecHook) getHookSpecForInverseOp(inverseOp string) *kubeobjects.HookSpec {
+// getHookSpecForInverseOp returns the spec of the given hook's inverse
+// operation, named either "hook/op" or, for an operation of the same hook, "op",
+// from the recipe hooks of the given type.
+func getHookSpecForInverseOp(hookSpec *kubeobjects.HookSpec, inverseOp, hookType string,
+	recipeElements util.RecipeElements,
+) *kubeobjects.HookSpec {
+	if recipeElements.RecipeWithParams == nil {
+		return nil
+	}
+
 	invHookParts := make([]string, 0)
 	if strings.Contains(inverseOp, "/") {
 		invHookParts = strings.Split(inverseOp, "/")
 	} else {
-		invHookParts = append(invHookParts, e.Hook.Name)
+		invHookParts = append(invHookParts, hookSpec.Name)
 		invHookParts = append(invHookParts, inverseOp)
 	}
 
-	hooks := e.RecipeElements.RecipeWithParams.Spec.Hooks
+	hooks := recipeElements.RecipeWithParams.Spec.Hooks
 
-	hook := getMatchingHook(hooks, invHookParts[0])
+	hook := getMatchingHook(hooks, invHookParts[0], hookType)
 	if hook != nil {
 		return getHookSpec(hook, invHookParts[1])
 	}
@@ -123,6 +135,7 @@ func getHookSpec(hook *recipev1.Hook, inverseOp string) *kubeobjects.HookSpec {
 		if op.Name == inverseOp {
 			return &kubeobjects.HookSpec{
 				Name: hook.Name,
+				Type: hook.Type,
 				Op: kubeobjects.Operation{
 					Name:      op.Name,
 					Command:   op.Command,
@@ -146,9 +159,9 @@ func getHookSpec(hook *recipev1.Hook, inverseOp string) *kubeobjects.HookSpec {
 	return nil
 }
 
-func getMatchingHook(hooks []*recipev1.Hook, hookName string) *recipev1.Hook {
+func getMatchingHook(hooks []*recipev1.Hook, hookName, hookType string) *recipev1.Hook {
 	for _, hook := range hooks {
-		if hook.Name == hookName && hook.Type == "exec" {
+		if hook.Name == hookName && hook.Type == hookType {
 			return hook
 		}
 	}
@@ -156,29 +169,238 @@ func getMatchingHook(hooks []*recipev1.Hook, hookName string) *recipev1.Hook {
 	return nil
 }
 
-func (e ExecHook) executeCommands(execPods []ExecPodSpec, log logr.Logger) (ExecPodSpec, error) {
-	for _, execPod := range execPods {
-		err := executeCommand(&execPod, e.Hook, e.Scheme, log)
-		if err != nil && getOpHookOnError(e.Hook) == defaultOnErrorValue {
-			log.Error(err, "error executing command on pod", "pod", execPod.PodName,
-				"namespace", execPod.Namespace, "command", execPod.Command)
+// executeCommands executes the command in each of the given pods, in as many
+// at once as the operation's concurrency, and records the pods, and the output
+// and exit code of the first command that failed, or else of the last one, in
+// the given result.  Once a command fails, unless the operation's errors are to
+// be ignored, it is executed in no more pods, and the errors of the commands
+// that were executed are returned together.  A command whose output does not
+// meet the operation's output criteria fails.
+func (e ExecHook) executeCommands(execPods []ExecPodSpec, result *Result, log logr.Logger) error {
+	criteria, condition, err := execHookOutputCriteria(e.Hook, e.RecipeElements)
+	if err != nil {
+		result.Error = err
+
+		return fmt.Errorf("error executing exec hook: %w", err)
+	}
+
+	outputs := make([]commandOutput, len(execPods))
+	errs := make([]error, len(execPods))
+	executed := 0
+
+	var (
+		waitGroup sync.WaitGroup
+		failed    atomic.Bool
+	)
+
+	failOnError := getOpHookOnError(e.Hook) == defaultOnErrorValue
+	semaphore := make(chan struct{}, execHookConcurrency(e.Hook, e.RecipeElements))
 
-			return execPod, fmt.Errorf("error executing exec hook: %w", err)
+	for i := range execPods {
+		semaphore <- struct{}{}
+
+		if failOnError && failed.Load() {
+			<-semaphore
+
+			break
 		}
+
+		executed++
+
+		waitGroup.Add(1)
+
+		go func() {
+			defer func() {
+				<-semaphore
+				waitGroup.Done()
+			}()
+
+			outputs[i], errs[i] = executeCommand(&execPods[i], e.Hook, e.Scheme, log)
+			if errs[i] == nil && criteria != nil {
+				errs[i] = execOutputCheck(e.Hook, outputs[i], criteria, condition)
+			}
+
+			if errs[i] != nil {
+				failed.Store(true)
+			}
+		}()
 	}
 
-	return ExecPodSpec{}, nil
+	waitGroup.Wait()
+
+	if criteria != nil && criteria.Parameter != "" {
+		result.Parameters = map[string][]string{criteria.Parameter: outputParameterValues(outputs[:executed], errs)}
+	}
+
+	return e.commandsResultRecord(execPods[:executed], outputs, errs, result, failOnError, log)
+}
+
+// outputParameterValues returns the stdout, trimmed, of each command that
+// succeeded.
+func outputParameterValues(outputs []commandOutput, errs []error) []string {
+	values := make([]string, 0, len(outputs))
+
+	for i, output := range outputs {
+		if errs[i] == nil {
+			values = append(values, strings.TrimSpace(output.stdout))
+		}
+	}
+
+	return values
 }
 
-func executeCommand(execPod *ExecPodSpec, hook *kubeobjects.HookSpec, scheme *runtime.Scheme, log logr.Logger) error {
-	restCfg, err := config.GetConfig()
+func (e ExecHook) commandsResultRecord(execPods []ExecPodSpec, outputs []commandOutput, errs []error,
+	result *Result, failOnError bool, log logr.Logger,
+) error {
+	podErrs := make([]error, 0)
+	recorded := len(execPods) - 1
+
+	for i, execPod := range execPods {
+		result.Targets = append(result.Targets, execPod.Namespace+"/"+execPod.PodName)
+
+		if errs[i] == nil {
+			continue
+		}
+
+		log.Error(errs[i], "error executing command on pod", "pod", execPod.PodName,
+			"namespace", execPod.Namespace, "command", execPod.Command)
+
+		if len(podErrs) == 0 {
+			recorded = i
+		}
+
+		podErrs = append(podErrs, fmt.Errorf("pod %s/%s: %w", execPod.Namespace, execPod.PodName, errs[i]))
+	}
+
+	if recorded >= 0 {
+		result.Stdout = outputTruncate(outputs[recorded].stdout)
+		result.Stderr = outputTruncate(outputs[recorded].stderr)
+		result.ExitCode = outputs[recorded].exitCode
+	}
+
+	if len(podErrs) == 0 {
+		return nil
+	}
+
+	err := errors.Join(podErrs...)
+	if result.Error == nil {
+		result.Error = err
+	}
+
+	if !failOnError {
+		return nil
+	}
+
+	return fmt.Errorf("error executing exec hook in %d of %d pods: %w", len(podErrs), len(execPods), err)
+}
+
+// execHookConcurrency returns the maximum number of pods in which the given
+// exec hook operation's command is executed at once.
+func execHookConcurrency(hook *kubeobjects.HookSpec, recipeElements util.RecipeElements) int {
+	if recipeElements.RecipeWithParams != nil {
+		concurrency, err := execHookConcurrencyGet(recipeElements.RecipeWithParams.GetAnnotations(), hook.Name)
+		if err == nil && concurrency > 0 {
+			return concurrency
+		}
+	}
+
+	return defaultExecHookConcurrency
+}
+
+// execHookConcurrencyGet returns the named hook's concurrency annotation value,
+// or zero if it is not annotated.
+func execHookConcurrencyGet(annotations map[string]string, hookName string) (int, error) {
+	value, ok := annotations[ExecHookConcurrencyAnnotationPrefix+hookName]
+	if !ok {
+		return 0, nil
+	}
+
+	concurrency, err := strconv.Atoi(value)
+	if err != nil || concurrency < 1 {
+		return 0, fmt.Errorf("annotation %s value %q is not a positive integer",
+			ExecHookConcurrencyAnnotationPrefix+hookName, value)
+	}
+
+	return concurrency, nil
+}
+
+// ExecHookConcurrencyValidate returns an error if the given Recipe annotations
+// specify a concurrency for the named hook that is not a positive integer.
+func ExecHookConcurrencyValidate(annotations map[string]string, hookName string) error {
+	_, err := execHookConcurrencyGet(annotations, hookName)
+
+	return err
+}
+
+// ExecHookAnnotationNamesValidate returns an error for the first of the given
+// Recipe annotations whose name has the concurrency or output annotation prefix
+// but names no hook, or no operation, of the given hooks, e.g. as it has a typo,
+// which would otherwise be ignored.
+func ExecHookAnnotationNamesValidate(annotations map[string]string, recipeHooks []*recipev1.Hook) error {
+	names := make(map[string]struct{})
+
+	for _, hook := range recipeHooks {
+		names[ExecHookConcurrencyAnnotationPrefix+hook.Name] = struct{}{}
+
+		for _, op := range hook.Ops {
+			names[ExecHookOutputAnnotationPrefix+hook.Name+"."+op.Name] = struct{}{}
+		}
+	}
+
+	for _, name := range slices.Sorted(maps.Keys(annotations)) {
+		if !strings.HasPrefix(name, ExecHookConcurrencyAnnotationPrefix) &&
+			!strings.HasPrefix(name, ExecHookOutputAnnotationPrefix) {
+			continue
+		}
+
+		if _, ok := names[name]; !ok {
+			return fmt.Errorf("annotation %s names no hook operation of the recipe", name)
+		}
+	}
+
+	return nil
+}
+
+// commandOutput is the output of a command executed in a pod, and its exit
+// code, if it exited.
+type commandOutput struct {
+	stdout   string
+	stderr   string
+	exitCode *int32
+}
+
+// restConfigGet and clientsetGet return the kubeconfig and a clientset of it,
+// created once, on first use, to execute commands in, and get the logs of, pods.
+var (
+	restConfigGet = sync.OnceValues(config.GetConfig)
+	clientsetGet  = sync.OnceValues(func() (*kubernetes.Clientset, error) {
+		restCfg, err := restConfigGet()
+		if err != nil {
+			return nil, fmt.Errorf("error getting kubeconfig: %w", err)
+		}
+
+		coreClient, err := kubernetes.NewForConfig(restCfg)
+		if err != nil {
+			return nil, fmt.Errorf("error creating kubernetes client: %w", err)
+		}
+
+		return coreClient, nil
+	})
+)
+
+func executeCommand(execPod *ExecPodSpec, hook *kubeobjects.HookSpec, scheme *runtime.Scheme,
+	log logr.Logger,
+) (commandOutput, error) {
+	output := commandOutput{}
+
+	coreClient, err := clientsetGet()
 	if err != nil {
-		return fmt.Errorf("error getting kubeconfig: %w", err)
+		return output, err
 	}
 
-	coreClient, err := kubernetes.NewForConfig(restCfg)
+	restCfg, err := restConfigGet()
 	if err != nil {
-		return fmt.Errorf("error creating kubernetes client: %w", err)
+		return output, fmt.Errorf("error getting kubeconfig: %w", err)
 	}
 
 	buf := &bytes.Buffer{}
@@ -199,7 +421,7 @@ func executeCommand(execPod *ExecPodSpec, hook *kubeobjects.HookSpec, scheme *ru
 
 	exec, err := remotecommand.NewSPDYExecutor(restCfg, "POST", request.URL())
 	if err != nil {
-		return fmt.Errorf("error creating executor: %w", err)
+		return output, fmt.Errorf("error creating executor: %w", err)
 	}
 
 	// This time duration should be used from hook definition
@@ -210,16 +432,27 @@ func executeCommand(execPod *ExecPodSpec, hook *kubeobjects.HookSpec, scheme *ru
 		Stdout: buf,
 		Stderr: errBuf,
 	})
+
+	output.stdout, output.stderr = buf.String(), errBuf.String()
+
+	var exitErr utilexec.ExitError
+	if errors.As(err, &exitErr) {
+		output.exitCode = ptr.To(int32(exitErr.ExitStatus()))
+	}
+
 	if err != nil {
 		log.Error(err, "error executing command on pod")
 
-		return fmt.Errorf("error executing command on pod: command %s, error %s", execPod.Command, errBuf.String())
+		return output, fmt.Errorf("error executing command on pod: command %s, error %s", execPod.Command,
+			errBuf.String())
 	}
 
+	output.exitCode = ptr.To(int32(0))
+
 	log.Info("executed exec command successfully", "pod", execPod.PodName, "namespace", execPod.Namespace,
 		"command", execPod.Command, "output", buf.String())
 
-	return nil
+	return output, nil
 }
 
 func (e ExecHook) GetPodsToExecuteCommands(log logr.Logger) []ExecPodSpec {
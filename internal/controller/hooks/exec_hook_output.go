@@ -0,0 +1,157 @@
+// SPDX-FileCopyrightText: The RamenDR authors
+// SPDX-License-Identifier: Apache-2.0
+
+package hooks
+
+import (
+	"encoding/json"
+	"fmt"
+	"regexp"
+	"strings"
+
+	"github.com/ramendr/ramen/internal/controller/kubeobjects"
+	"github.com/ramendr/ramen/internal/controller/util"
+)
+
+// ExecHookOutputAnnotationPrefix prefixes the name of a hook, and that of one
+// of its exec operations, separated by a ".", in the name of a Recipe
+// annotation whose value is the operation's output criteria, in JSON, e.g.
+// "exec-output.hooks.ramendr.openshift.io/db.quiesce: {"stdoutPattern": "^OK"}".
+const ExecHookOutputAnnotationPrefix = "exec-output.hooks.ramendr.openshift.io/"
+
+// execHookOutput returns the output criteria of the given exec hook operation,
+// or nil if it has none.
+func execHookOutput(hook *kubeobjects.HookSpec, recipeElements util.RecipeElements,
+) (*kubeobjects.OperationOutput, error) {
+	if recipeElements.RecipeWithParams == nil {
+		return nil, nil
+	}
+
+	return execHookOutputGet(recipeElements.RecipeWithParams.GetAnnotations(), hook.Name, hook.Op.Name)
+}
+
+// execHookOutputCriteria returns the output criteria of the given exec hook
+// operation, or nil if it has none, and their condition, compiled once for the
+// output of each of its commands.
+func execHookOutputCriteria(hook *kubeobjects.HookSpec, recipeElements util.RecipeElements,
+) (*kubeobjects.OperationOutput, checkCondition, error) {
+	criteria, err := execHookOutput(hook, recipeElements)
+	if err != nil || criteria == nil || criteria.Condition == "" {
+		return criteria, checkCondition{}, err
+	}
+
+	condition, err := checkConditionNew(criteria.Condition)
+	if err != nil {
+		return nil, checkCondition{}, fmt.Errorf("output condition %s compile: %w", criteria.Condition, err)
+	}
+
+	return criteria, condition, nil
+}
+
+// execHookOutputGet returns the output criteria the given Recipe annotations
+// specify for the named hook operation, or nil if they specify none.
+func execHookOutputGet(annotations map[string]string, hookName, opName string,
+) (*kubeobjects.OperationOutput, error) {
+	name := ExecHookOutputAnnotationPrefix + hookName + "." + opName
+
+	value, ok := annotations[name]
+	if !ok {
+		return nil, nil
+	}
+
+	output := &kubeobjects.OperationOutput{}
+	if err := json.Unmarshal([]byte(value), output); err != nil {
+		return nil, fmt.Errorf("annotation %s value %q unmarshal: %w", name, value, err)
+	}
+
+	return output, nil
+}
+
+// ExecHookOutputValidate returns an error if the output criteria the given
+// Recipe annotations specify for the named hook operation are not valid.
+func ExecHookOutputValidate(annotations map[string]string, hookName, opName string) error {
+	output, err := execHookOutputGet(annotations, hookName, opName)
+	if err != nil || output == nil {
+		return err
+	}
+
+	for _, pattern := range []string{output.StdoutPattern, output.StderrPattern} {
+		if _, err := regexp.Compile(pattern); err != nil {
+			return fmt.Errorf("output pattern %q compile: %w", pattern, err)
+		}
+	}
+
+	return CheckConditionValidate(output.Condition)
+}
+
+// ExecHookOutputParameterNames returns the names of the recipe parameters the
+// given Recipe annotations specify that exec hook operations' output is stored
+// in.
+func ExecHookOutputParameterNames(annotations map[string]string) []string {
+	names := make([]string, 0)
+
+	for name, value := range annotations {
+		if !strings.HasPrefix(name, ExecHookOutputAnnotationPrefix) {
+			continue
+		}
+
+		output := kubeobjects.OperationOutput{}
+		if err := json.Unmarshal([]byte(value), &output); err == nil && output.Parameter != "" {
+			names = append(names, output.Parameter)
+		}
+	}
+
+	return names
+}
+
+// execOutputCheck returns an error if the given command output does not meet
+// the given criteria.
+func execOutputCheck(hook *kubeobjects.HookSpec, output commandOutput, criteria *kubeobjects.OperationOutput,
+	condition checkCondition,
+) error {
+	if err := execOutputPatternCheck("stdout", output.stdout, criteria.StdoutPattern); err != nil {
+		return err
+	}
+
+	if err := execOutputPatternCheck("stderr", output.stderr, criteria.StderrPattern); err != nil {
+		return err
+	}
+
+	if criteria.Condition == "" {
+		return nil
+	}
+
+	var jsonData interface{}
+	if err := json.Unmarshal([]byte(output.stdout), &jsonData); err != nil {
+		return fmt.Errorf("stdout %q is not json: %w", outputTruncate(output.stdout), err)
+	}
+
+	result, err := condition.evaluate(hook, jsonData)
+	if err != nil {
+		return fmt.Errorf("output condition %s evaluate: %w", criteria.Condition, err)
+	}
+
+	if !result {
+		return fmt.Errorf("output condition %s is false for stdout %q", criteria.Condition,
+			outputTruncate(output.stdout))
+	}
+
+	return nil
+}
+
+func execOutputPatternCheck(stream, output, pattern string) error {
+	if pattern == "" {
+		return nil
+	}
+
+	matched, err := regexp.MatchString(pattern, output)
+	if err != nil {
+		return fmt.Errorf("%s pattern %q compile: %w", stream, pattern, err)
+	}
+
+	if !matched {
+		return fmt.Errorf("%s %q does not match pattern %q", stream, outputTruncate(output), pattern)
+	}
+
+	return nil
+}
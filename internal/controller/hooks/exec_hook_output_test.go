@@ -0,0 +1,49 @@
+package hooks_test
+
+import (
+	"testing"
+
+	"github.com/ramendr/ramen/internal/controller/hooks"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestExecHookOutputValidate(t *testing.T) {
+	tests := map[string]struct {
+		value   string
+		wantErr bool
+	}{
+		"stdout pattern":    {value: `{"stdoutPattern": "^OK"}`},
+		"jsonpath":          {value: `{"condition": "{$.status} == \"quiesced\"", "parameter": "SNAPSHOT"}`},
+		"cel":               {value: `{"condition": "cel: object.status == 'quiesced'"}`},
+		"not json":          {value: `^OK`, wantErr: true},
+		"invalid pattern":   {value: `{"stderrPattern": "("}`, wantErr: true},
+		"invalid condition": {value: `{"condition": "cel: object.status +"}`, wantErr: true},
+	}
+
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			annotations := map[string]string{hooks.ExecHookOutputAnnotationPrefix + "db.quiesce": tt.value}
+
+			err := hooks.ExecHookOutputValidate(annotations, "db", "quiesce")
+			if tt.wantErr {
+				assert.Error(t, err)
+			} else {
+				assert.NoError(t, err)
+			}
+		})
+	}
+
+	assert.NoError(t, hooks.ExecHookOutputValidate(map[string]string{}, "db", "quiesce"))
+}
+
+func TestExecHookOutputParameterNames(t *testing.T) {
+	annotations := map[string]string{
+		hooks.ExecHookOutputAnnotationPrefix + "db.quiesce":  `{"stdoutPattern": "^OK", "parameter": "SNAPSHOT"}`,
+		hooks.ExecHookOutputAnnotationPrefix + "db.check":    `{"stdoutPattern": "^OK"}`,
+		hooks.ExecHookConcurrencyAnnotationPrefix + "db":     "2",
+		"unrelated.example.com/parameter":                    `{"parameter": "OTHER"}`,
+		hooks.ExecHookOutputAnnotationPrefix + "db.snapshot": `{"parameter": "SNAPSHOT_ID"}`,
+	}
+
+	assert.ElementsMatch(t, []string{"SNAPSHOT", "SNAPSHOT_ID"}, hooks.ExecHookOutputParameterNames(annotations))
+}
@@ -6,6 +6,8 @@ import (
 
 	"github.com/ramendr/ramen/internal/controller/hooks"
 	"github.com/ramendr/ramen/internal/controller/kubeobjects"
+	"github.com/ramendr/ramen/internal/controller/util"
+	recipev1 "github.com/ramendr/recipe/api/v1alpha1"
 	"github.com/stretchr/testify/assert"
 	appsv1 "k8s.io/api/apps/v1"
 	corev1 "k8s.io/api/core/v1"
@@ -237,3 +239,76 @@ func TestIsRSOwnedByDeployment(t *testing.T) {
 	}
 	assert.True(t, hooks.IsRSOwnedByDeployment(rs, "test-deployment"))
 }
+
+func TestExecHookConcurrencyValidate(t *testing.T) {
+	tests := map[string]struct {
+		annotations map[string]string
+		wantErr     bool
+	}{
+		"not annotated": {annotations: map[string]string{}},
+		"positive":      {annotations: map[string]string{hooks.ExecHookConcurrencyAnnotationPrefix + "test": "4"}},
+		"other hook":    {annotations: map[string]string{hooks.ExecHookConcurrencyAnnotationPrefix + "other": "x"}},
+		"zero": {
+			annotations: map[string]string{hooks.ExecHookConcurrencyAnnotationPrefix + "test": "0"},
+			wantErr:     true,
+		},
+		"not integer": {
+			annotations: map[string]string{hooks.ExecHookConcurrencyAnnotationPrefix + "test": "all"},
+			wantErr:     true,
+		},
+	}
+
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			err := hooks.ExecHookConcurrencyValidate(tt.annotations, "test")
+			if tt.wantErr {
+				assert.Error(t, err)
+			} else {
+				assert.NoError(t, err)
+			}
+		})
+	}
+}
+
+func TestExecuteWithNoPodsSelectedConcurrently(t *testing.T) {
+	hookSpec := getOpHookSpec()
+	hookSpec.LabelSelector = &metav1.LabelSelector{MatchLabels: map[string]string{"appname": "busybox"}}
+	recipeElements := util.RecipeElements{RecipeWithParams: &recipev1.Recipe{ObjectMeta: metav1.ObjectMeta{
+		Annotations: map[string]string{hooks.ExecHookConcurrencyAnnotationPrefix + hookSpec.Name: "4"},
+	}}}
+
+	result, err := hooks.ExecHook{Hook: hookSpec, Reader: setup(t), RecipeElements: recipeElements}.
+		ExecuteWithResult(zap.New())
+	assert.NoError(t, err)
+	assert.Empty(t, result.Targets)
+	assert.NoError(t, result.Error)
+}
+
+func TestExecHookAnnotationNamesValidate(t *testing.T) {
+	recipeHooks := []*recipev1.Hook{{Name: "db", Ops: []*recipev1.Operation{{Name: "quiesce"}}}}
+
+	tests := map[string]struct {
+		name    string
+		wantErr bool
+	}{
+		"concurrency":              {name: hooks.ExecHookConcurrencyAnnotationPrefix + "db"},
+		"output":                   {name: hooks.ExecHookOutputAnnotationPrefix + "db.quiesce"},
+		"unrelated":                {name: "unrelated.example.com/dbs"},
+		"concurrency of no hook":   {name: hooks.ExecHookConcurrencyAnnotationPrefix + "dbs", wantErr: true},
+		"output of no hook":        {name: hooks.ExecHookOutputAnnotationPrefix + "dbs.quiesce", wantErr: true},
+		"output of no operation":   {name: hooks.ExecHookOutputAnnotationPrefix + "db.quiesced", wantErr: true},
+		"output of hook, not op":   {name: hooks.ExecHookOutputAnnotationPrefix + "db", wantErr: true},
+		"concurrency of operation": {name: hooks.ExecHookConcurrencyAnnotationPrefix + "db.quiesce", wantErr: true},
+	}
+
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			err := hooks.ExecHookAnnotationNamesValidate(map[string]string{tt.name: "1"}, recipeHooks)
+			if tt.wantErr {
+				assert.ErrorContains(t, err, tt.name)
+			} else {
+				assert.NoError(t, err)
+			}
+		})
+	}
+}
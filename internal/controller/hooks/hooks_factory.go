@@ -5,30 +5,76 @@ package hooks
 
 import (
 	"fmt"
+	"unicode/utf8"
 
 	"github.com/go-logr/logr"
 	"github.com/ramendr/ramen/internal/controller/kubeobjects"
 	"github.com/ramendr/ramen/internal/controller/util"
+	"k8s.io/apimachinery/pkg/api/meta"
 	"k8s.io/apimachinery/pkg/runtime"
 	"sigs.k8s.io/controller-runtime/pkg/client"
 )
 
 // Hook interface will help in executing the hooks based on the types.
-// Supported types are "check", "scale" and "exec". The implementor needs
+// Supported types are listed in SupportedTypes. The implementor needs
 // return the result which would be boolean and error if any.
 type HookExecutor interface {
 	Execute(log logr.Logger) error
+	ExecuteWithResult(log logr.Logger) (Result, error)
 }
 
+// Result is the outcome of a hook's execution: the resources it executed on,
+// the exit code of its command, the status code of its request or the result
+// of its check, its output, truncated, whether its inverse operation was
+// executed, and the recipe parameters its output was stored in.  Error is that of an execution that
+// failed, even if the hook's onError is continue.
+type Result struct {
+	Targets           []string
+	ExitCode          *int32
+	StatusCode        *int32
+	CheckResult       *bool
+	Stdout            string
+	Stderr            string
+	Error             error
+	InverseOpExecuted bool
+	Parameters        map[string][]string
+}
+
+// outputTruncate returns at most the last outputLimit bytes of the given
+// output, from a rune boundary.
+func outputTruncate(output string) string {
+	if len(output) <= outputLimit {
+		return output
+	}
+
+	start := len(output) - outputLimit
+	for start < len(output) && !utf8.RuneStart(output[start]) {
+		start++
+	}
+
+	return output[start:]
+}
+
+// SupportedTypes are the hook types GetHookExecutor returns an executor for.
+var SupportedTypes = []string{"check", "exec", "scale", "http", "job", "vm"}
+
 // Based on the hook type, return the appropriate implementation of the hook.
-func GetHookExecutor(hook kubeobjects.HookSpec, reader client.Reader, scheme *runtime.Scheme,
-	recipeElements util.RecipeElements,
+func GetHookExecutor(hook kubeobjects.HookSpec, reader client.Reader, writer client.Writer, scheme *runtime.Scheme,
+	restMapper meta.RESTMapper, recipeElements util.RecipeElements,
 ) (HookExecutor, error) {
 	switch hook.Type {
 	case "check":
-		return CheckHook{Hook: &hook, Reader: reader}, nil
+		return CheckHook{Hook: &hook, Reader: reader, RESTMapper: restMapper}, nil
 	case "exec":
 		return ExecHook{Hook: &hook, Reader: reader, Scheme: scheme, RecipeElements: recipeElements}, nil
+	case "scale":
+		return ScaleHook{Hook: &hook, Reader: reader, Writer: writer, RecipeElements: recipeElements}, nil
+	case "http":
+		return HTTPHook{Hook: &hook, Reader: reader, RecipeElements: recipeElements}, nil
+	case "job":
+		return JobHook{Hook: &hook, Reader: reader, Writer: writer, RecipeElements: recipeElements}, nil
+	case "vm":
+		return VMHook{Hook: &hook, Reader: reader, RecipeElements: recipeElements}, nil
 	default:
 		return nil, fmt.Errorf("unsupported hook type")
 	}
@@ -14,22 +14,27 @@ import (
 func TestGetHookExecutor(t *testing.T) {
 	client := fake.NewFakeClient()
 
-	executor, err := hooks.GetHookExecutor(getHookSpecForFactoryTest("check"), client, client.Scheme(),
-		util.RecipeElements{})
-	assert.Nil(t, err)
-
-	_, ok := executor.(hooks.CheckHook)
-	assert.True(t, ok)
+	executors := map[string]hooks.HookExecutor{
+		"check": hooks.CheckHook{},
+		"exec":  hooks.ExecHook{},
+		"scale": hooks.ScaleHook{},
+		"http":  hooks.HTTPHook{},
+		"job":   hooks.JobHook{},
+		"vm":    hooks.VMHook{},
+	}
+	assert.Len(t, hooks.SupportedTypes, len(executors))
 
-	executor, err = hooks.GetHookExecutor(getHookSpecForFactoryTest("exec"), client, client.Scheme(),
-		util.RecipeElements{})
-	assert.Nil(t, err)
+	for _, hookType := range hooks.SupportedTypes {
+		executor, err := hooks.GetHookExecutor(getHookSpecForFactoryTest(hookType), client, client, client.Scheme(),
+			client.RESTMapper(), util.RecipeElements{})
+		assert.Nil(t, err)
 
-	_, ok = executor.(hooks.ExecHook)
-	assert.True(t, ok)
+		assert.Contains(t, executors, hookType)
+		assert.IsType(t, executors[hookType], executor, hookType)
+	}
 
-	executor, err = hooks.GetHookExecutor(getHookSpecForFactoryTest("undefined"), client, client.Scheme(),
-		util.RecipeElements{})
+	executor, err := hooks.GetHookExecutor(getHookSpecForFactoryTest("undefined"), client, client, client.Scheme(),
+		client.RESTMapper(), util.RecipeElements{})
 
 	assert.Nil(t, executor)
 	assert.EqualError(t, err, "unsupported hook type")
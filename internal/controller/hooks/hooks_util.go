@@ -5,10 +5,13 @@ import (
 	"fmt"
 	"regexp"
 
+	"github.com/go-logr/logr"
 	"github.com/ramendr/ramen/internal/controller/kubeobjects"
+	"github.com/ramendr/ramen/internal/controller/util"
 	appsv1 "k8s.io/api/apps/v1"
 	corev1 "k8s.io/api/core/v1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
 	"k8s.io/apimachinery/pkg/fields"
 	"sigs.k8s.io/controller-runtime/pkg/client"
 )
@@ -16,8 +19,78 @@ import (
 const (
 	defaultTimeoutValue = 300
 	defaultOnErrorValue = "fail"
+	outputLimit         = 1024
 )
 
+// operationRun runs the operation of the given hook, an executor's hook or that
+// of the inverse of its operation, recording its outcome in the given result.
+type operationRun func(hook *kubeobjects.HookSpec, result *Result, log logr.Logger) error
+
+// executeWithResult runs the operation of the given hook, of the given type,
+// with the given run function.  If it fails, and the hook is to fail on error,
+// the inverse of the operation, if any, is run too.
+func executeWithResult(hook *kubeobjects.HookSpec, hookType string, recipeElements util.RecipeElements,
+	run operationRun, log logr.Logger,
+) (Result, error) {
+	result := Result{}
+
+	err := run(hook, &result, log)
+	if err == nil {
+		return result, nil
+	}
+
+	result.Error = err
+
+	log.Error(err, "error executing "+hookType+" hook", "hook", hook.Name, "operation", hook.Op.Name)
+
+	if !shouldOpHookBeFailedOnError(hook) {
+		return result, nil
+	}
+
+	if inverseOp := hook.Op.InverseOp; inverseOp != "" {
+		result.InverseOpExecuted = executeInverseOp(hook, inverseOp, hookType, recipeElements, run, log)
+	}
+
+	return result, fmt.Errorf("error executing %s hook: %w", hookType, err)
+}
+
+// executeInverseOp runs the given inverse operation of the given hook's
+// operation, of a hook of the given type of the recipe, with the given run
+// function, and returns whether it was found and run, successfully or not.
+func executeInverseOp(hook *kubeobjects.HookSpec, inverseOp, hookType string, recipeElements util.RecipeElements,
+	run operationRun, log logr.Logger,
+) bool {
+	hookSpec := getHookSpecForInverseOp(hook, inverseOp, hookType, recipeElements)
+	if hookSpec == nil {
+		log.Info("inverse operation not found in recipe", "inverseOp", inverseOp)
+
+		return false
+	}
+
+	log = log.WithValues("inverseOp", inverseOp, "namespace", hookSpec.Namespace)
+	log.Info("executing inverse operation")
+
+	if err := run(hookSpec, &Result{}, log); err != nil {
+		log.Error(err, "error executing inverse operation")
+
+		return true
+	}
+
+	log.Info("executed inverse operation successfully")
+
+	return true
+}
+
+// selectorsCheck returns an error if the given hook selects its resources
+// neither by name nor by label.
+func selectorsCheck(hook *kubeobjects.HookSpec) error {
+	if hook.LabelSelector == nil && hook.NameSelector == "" {
+		return fmt.Errorf("either nameSelector or labelSelector should be provided to get resources")
+	}
+
+	return nil
+}
+
 func getResourcesUsingNameSelector(r client.Reader, hook *kubeobjects.HookSpec,
 	objList client.ObjectList,
 ) ([]client.Object, error) {
@@ -80,6 +153,10 @@ func getObjectsBasedOnType(objList client.ObjectList) []client.Object {
 		for _, ss := range v.Items {
 			objs = append(objs, &ss)
 		}
+	case *unstructured.UnstructuredList:
+		for i := range v.Items {
+			objs = append(objs, &v.Items[i])
+		}
 	}
 
 	return objs
@@ -101,6 +178,12 @@ func getObjectsBasedOnTypeAndRegex(objList client.ObjectList, nameSelector strin
 		objs = getMatchingDeployments(v, re)
 	case *appsv1.StatefulSetList:
 		objs = getMatchingStatefulSets(v, re)
+	case *unstructured.UnstructuredList:
+		for i := range v.Items {
+			if re.MatchString(v.Items[i].GetName()) {
+				objs = append(objs, &v.Items[i])
+			}
+		}
 	}
 
 	return objs
@@ -0,0 +1,115 @@
+package hooks_test
+
+import (
+	"testing"
+
+	"github.com/ramendr/ramen/internal/controller/hooks"
+	"github.com/ramendr/ramen/internal/controller/kubeobjects"
+	"github.com/stretchr/testify/assert"
+	batchv1 "k8s.io/api/batch/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+	"sigs.k8s.io/controller-runtime/pkg/log/zap"
+)
+
+// getHookTestClientBuilder returns a fake client builder with the given
+// schemes and objects.
+func getHookTestClientBuilder(t *testing.T, addToSchemes []func(*runtime.Scheme) error,
+	objects ...client.Object,
+) *fake.ClientBuilder {
+	t.Helper()
+
+	scheme := runtime.NewScheme()
+	for _, addToScheme := range addToSchemes {
+		assert.NoError(t, addToScheme(scheme))
+	}
+
+	return fake.NewClientBuilder().WithScheme(scheme).WithObjects(objects...)
+}
+
+// getHookTestSpec returns a hook spec of the given type in the test namespace
+// with an operation of the given name and command.
+func getHookTestSpec(hookType, opName, command string) *kubeobjects.HookSpec {
+	return &kubeobjects.HookSpec{
+		Name:      "test",
+		Namespace: "test-ns",
+		Type:      hookType,
+		Timeout:   5,
+		Op: kubeobjects.Operation{
+			Name:    opName,
+			Command: command,
+		},
+	}
+}
+
+func TestHooksFailForInvalidCommand(t *testing.T) {
+	tests := []struct {
+		name     string
+		executor func(t *testing.T) hooks.HookExecutor
+	}{
+		{
+			name: "scale",
+			executor: func(t *testing.T) hooks.HookExecutor {
+				t.Helper()
+
+				c := setupForScaleHook(t, getDeploymentForScaleHook(3, 3, nil))
+				t.Cleanup(func() { assert.Equal(t, int32(3), *getScaledDeployment(t, c).Spec.Replicas) })
+
+				return hooks.ScaleHook{Hook: getScaleHookSpec("down"), Reader: c, Writer: c}
+			},
+		},
+		{
+			name: "http",
+			executor: func(t *testing.T) hooks.HookExecutor {
+				t.Helper()
+
+				return hooks.HTTPHook{Hook: getHookTestSpec("http", "quiesce", "curl http://test")}
+			},
+		},
+		{
+			name: "http service outside namespace",
+			executor: func(t *testing.T) hooks.HookExecutor {
+				t.Helper()
+
+				return hooks.HTTPHook{Hook: getHookTestSpec("http", "quiesce", `{"service":"example.com/app"}`)}
+			},
+		},
+		{
+			name: "job",
+			executor: func(t *testing.T) hooks.HookExecutor {
+				t.Helper()
+
+				c := setupForJobHook(t, batchv1.JobComplete)
+				t.Cleanup(func() { assert.Empty(t, getJobHookJobs(t, c)) })
+
+				return hooks.JobHook{Hook: getHookTestSpec("job", "backup", "kubectl run"), Reader: c, Writer: c}
+			},
+		},
+		{
+			name: "vm thaw",
+			executor: func(t *testing.T) hooks.HookExecutor {
+				t.Helper()
+
+				return hooks.VMHook{Hook: getVMHookSpec("thaw"), Reader: setupForVMHook(t, "ns1"), Freezer: &fakeVMFreezer{}}
+			},
+		},
+		{
+			name: "vm freeze timeout",
+			executor: func(t *testing.T) hooks.HookExecutor {
+				t.Helper()
+
+				return hooks.VMHook{
+					Hook: getVMHookSpec("freeze never"), Reader: setupForVMHook(t, "ns1"), Freezer: &fakeVMFreezer{},
+				}
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := tt.executor(t).Execute(zap.New())
+			assert.Error(t, err)
+		})
+	}
+}
@@ -0,0 +1,236 @@
+// SPDX-FileCopyrightText: The RamenDR authors
+// SPDX-License-Identifier: Apache-2.0
+
+package hooks
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/go-logr/logr"
+	"github.com/ramendr/ramen/internal/controller/kubeobjects"
+	"github.com/ramendr/ramen/internal/controller/util"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/util/validation"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+const (
+	defaultHTTPHookPort   = 80
+	defaultHTTPHookMethod = http.MethodPost
+	httpHookBodyLimit     = 1 << 20
+)
+
+// HTTPRequest is the request an http hook operation makes, as specified, in
+// JSON, by its command, so that recipe parameters are expanded in its path and
+// body.  The request is made of the named Service, in the hook's namespace,
+// only, and redirects are not followed.  Each key of the named Secret's data is a header
+// of the request, and its value the header's value.  The request succeeds if the
+// response's status code is one of those specified, or a 2xx one if none are,
+// and the condition, if specified, on the response's JSON body, a check hook
+// condition, is true.
+type HTTPRequest struct {
+	Service           string `json:"service,omitempty"`
+	Port              int32  `json:"port,omitempty"`
+	Scheme            string `json:"scheme,omitempty"`
+	Method            string `json:"method,omitempty"`
+	Path              string `json:"path,omitempty"`
+	HeadersSecretName string `json:"headersSecretName,omitempty"`
+	Body              string `json:"body,omitempty"`
+	StatusCodes       []int  `json:"statusCodes,omitempty"`
+	Condition         string `json:"condition,omitempty"`
+}
+
+// HTTPHook makes the request its operation's command specifies, e.g. of an
+// application's quiesce endpoint, with Transport, or the default one if nil.
+type HTTPHook struct {
+	Hook           *kubeobjects.HookSpec
+	Reader         client.Reader
+	RecipeElements util.RecipeElements
+	Transport      http.RoundTripper
+}
+
+func (h HTTPHook) Execute(log logr.Logger) error {
+	_, err := h.ExecuteWithResult(log)
+
+	return err
+}
+
+func (h HTTPHook) ExecuteWithResult(log logr.Logger) (Result, error) {
+	return executeWithResult(h.Hook, "http", h.RecipeElements, h.operationRun, log)
+}
+
+// operationRun runs the operation of the given hook, the executor's or that of
+// its inverse operation.
+func (h HTTPHook) operationRun(hook *kubeobjects.HookSpec, result *Result, log logr.Logger) error {
+	h.Hook = hook
+
+	return h.request(result, log)
+}
+
+// httpClientNew returns a client that makes requests with the given transport
+// and timeout, and does not follow redirects, so that a request is made of its
+// Service only.
+func httpClientNew(transport http.RoundTripper, timeout time.Duration) *http.Client {
+	return &http.Client{
+		Transport: transport,
+		Timeout:   timeout,
+		CheckRedirect: func(*http.Request, []*http.Request) error {
+			return http.ErrUseLastResponse
+		},
+	}
+}
+
+// request makes the request, and records its URL and response status code in
+// the given result.
+func (h HTTPHook) request(result *Result, log logr.Logger) error {
+	spec := HTTPRequest{}
+	if err := json.Unmarshal([]byte(h.Hook.Op.Command), &spec); err != nil {
+		return fmt.Errorf("command %q is not an http request: %w", h.Hook.Op.Command, err)
+	}
+
+	timeout := time.Duration(getOpHookTimeoutValue(h.Hook)) * time.Second
+
+	ctx, cancel := context.WithTimeout(context.Background(), timeout)
+	defer cancel()
+
+	request, err := h.requestNew(ctx, spec)
+	if err != nil {
+		return err
+	}
+
+	result.Targets = []string{request.Method + " " + request.URL.Redacted()}
+
+	response, err := httpClientNew(h.Transport, timeout).Do(request)
+	if err != nil {
+		return fmt.Errorf("%s %s: %w", request.Method, request.URL.Redacted(), err)
+	}
+
+	defer response.Body.Close()
+
+	statusCode := int32(response.StatusCode)
+	result.StatusCode = &statusCode
+
+	log.Info("http hook response", "method", request.Method, "url", request.URL.Redacted(),
+		"status", response.StatusCode)
+
+	if !httpStatusCodeSucceeded(spec.StatusCodes, response.StatusCode) {
+		return fmt.Errorf("%s %s response status %d", request.Method, request.URL.Redacted(), response.StatusCode)
+	}
+
+	if spec.Condition == "" {
+		return nil
+	}
+
+	body, err := io.ReadAll(io.LimitReader(response.Body, httpHookBodyLimit))
+	if err != nil {
+		return fmt.Errorf("%s %s response body read: %w", request.Method, request.URL.Redacted(), err)
+	}
+
+	return h.conditionEvaluate(spec.Condition, body)
+}
+
+func (h HTTPHook) requestNew(ctx context.Context, spec HTTPRequest) (*http.Request, error) {
+	requestURL, err := h.urlGet(spec)
+	if err != nil {
+		return nil, err
+	}
+
+	method := spec.Method
+	if method == "" {
+		method = defaultHTTPHookMethod
+	}
+
+	var body io.Reader
+	if spec.Body != "" {
+		body = bytes.NewBufferString(spec.Body)
+	}
+
+	request, err := http.NewRequestWithContext(ctx, method, requestURL, body)
+	if err != nil {
+		return nil, fmt.Errorf("http request %s %s: %w", method, requestURL, err)
+	}
+
+	if spec.Body != "" && json.Valid([]byte(spec.Body)) {
+		request.Header.Set("Content-Type", "application/json")
+	}
+
+	if spec.HeadersSecretName == "" {
+		return request, nil
+	}
+
+	secret := &corev1.Secret{}
+	if err := h.Reader.Get(ctx, client.ObjectKey{Namespace: h.Hook.Namespace, Name: spec.HeadersSecretName},
+		secret); err != nil {
+		return nil, fmt.Errorf("headers secret %s/%s get: %w", h.Hook.Namespace, spec.HeadersSecretName, err)
+	}
+
+	for name, value := range secret.Data {
+		request.Header.Set(name, string(value))
+	}
+
+	return request, nil
+}
+
+// urlGet returns the URL of the request's path of the Service, named by its
+// cluster domain name.
+func (h HTTPHook) urlGet(spec HTTPRequest) (string, error) {
+	if errs := validation.IsDNS1035Label(spec.Service); len(errs) != 0 {
+		return "", fmt.Errorf("service %q is not a service name: %s", spec.Service, strings.Join(errs, ", "))
+	}
+
+	scheme := spec.Scheme
+	if scheme == "" {
+		scheme = "http"
+	}
+
+	if scheme != "http" && scheme != "https" {
+		return "", fmt.Errorf("scheme %q is neither http nor https", scheme)
+	}
+
+	port := spec.Port
+	if port == 0 {
+		port = defaultHTTPHookPort
+	}
+
+	return (&url.URL{
+		Scheme: scheme,
+		Host:   spec.Service + "." + h.Hook.Namespace + ".svc:" + strconv.Itoa(int(port)),
+		Path:   spec.Path,
+	}).String(), nil
+}
+
+func httpStatusCodeSucceeded(statusCodes []int, statusCode int) bool {
+	if len(statusCodes) == 0 {
+		return statusCode >= http.StatusOK && statusCode < http.StatusMultipleChoices
+	}
+
+	return slices.Contains(statusCodes, statusCode)
+}
+
+func (h HTTPHook) conditionEvaluate(condition string, body []byte) error {
+	var jsonData interface{}
+	if err := json.Unmarshal(body, &jsonData); err != nil {
+		return fmt.Errorf("response body is not json: %w", err)
+	}
+
+	result, err := evaluateBooleanExpression(h.Hook, condition, jsonData)
+	if err != nil {
+		return fmt.Errorf("condition %s evaluate: %w", condition, err)
+	}
+
+	if !result {
+		return fmt.Errorf("condition %s is false for response body", condition)
+	}
+
+	return nil
+}
@@ -0,0 +1,177 @@
+package hooks_test
+
+import (
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"testing"
+
+	"github.com/ramendr/ramen/internal/controller/hooks"
+	"github.com/ramendr/ramen/internal/controller/kubeobjects"
+	"github.com/ramendr/ramen/internal/controller/util"
+	recipev1 "github.com/ramendr/recipe/api/v1alpha1"
+	"github.com/stretchr/testify/assert"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/log/zap"
+)
+
+type httpHookRequest struct {
+	method string
+	host   string
+	path   string
+	token  string
+	body   string
+}
+
+// httpHookTransport makes each request of its server instead of its host.
+type httpHookTransport struct {
+	server *httptest.Server
+}
+
+func (t httpHookTransport) RoundTrip(request *http.Request) (*http.Response, error) {
+	serverURL, err := url.Parse(t.server.URL)
+	if err != nil {
+		return nil, err
+	}
+
+	request = request.Clone(request.Context())
+	request.Header.Set("X-Host", request.URL.Host)
+	request.URL.Host = serverURL.Host
+
+	return http.DefaultTransport.RoundTrip(request)
+}
+
+func setupForHTTPHook(t *testing.T, status int, responseBody string) (http.RoundTripper, *httpHookRequest) {
+	t.Helper()
+
+	received := &httpHookRequest{}
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+
+		*received = httpHookRequest{
+			method: r.Method, host: r.Header.Get("X-Host"), path: r.URL.Path, token: r.Header.Get("X-Token"),
+			body: string(body),
+		}
+
+		if status == http.StatusFound {
+			w.Header().Set("Location", "/redirected")
+		}
+
+		w.WriteHeader(status)
+		_, _ = w.Write([]byte(responseBody))
+	}))
+	t.Cleanup(server.Close)
+
+	return httpHookTransport{server: server}, received
+}
+
+func getHTTPHookSpec(t *testing.T, request hooks.HTTPRequest) *kubeobjects.HookSpec {
+	t.Helper()
+
+	command, err := json.Marshal(request)
+	assert.NoError(t, err)
+
+	return getHookTestSpec("http", "quiesce", string(command))
+}
+
+func getHeadersSecretClient(t *testing.T) client.Client {
+	t.Helper()
+
+	return getHookTestClientBuilder(t, []func(*runtime.Scheme) error{corev1.AddToScheme}, &corev1.Secret{
+		ObjectMeta: metav1.ObjectMeta{Name: "headers", Namespace: "test-ns"},
+		Data:       map[string][]byte{"X-Token": []byte("secret-token")},
+	}).Build()
+}
+
+func TestHTTPHookMakesRequestOfService(t *testing.T) {
+	transport, received := setupForHTTPHook(t, http.StatusOK, "")
+
+	hookSpec := getHTTPHookSpec(t, hooks.HTTPRequest{
+		Service:           "app",
+		Port:              8080,
+		Method:            http.MethodPut,
+		Path:              "/quiesce",
+		HeadersSecretName: "headers",
+		Body:              `{"flush":true}`,
+	})
+
+	result, err := hooks.HTTPHook{Hook: hookSpec, Reader: getHeadersSecretClient(t), Transport: transport}.
+		ExecuteWithResult(zap.New())
+	assert.NoError(t, err)
+	assert.Equal(t, httpHookRequest{
+		method: http.MethodPut, host: "app.test-ns.svc:8080", path: "/quiesce", token: "secret-token",
+		body: `{"flush":true}`,
+	}, *received)
+	assert.Equal(t, int32(http.StatusOK), *result.StatusCode)
+}
+
+func TestHTTPHookFailsForUnexpectedStatusCode(t *testing.T) {
+	transport, _ := setupForHTTPHook(t, http.StatusAccepted, "response secret")
+
+	hookSpec := getHTTPHookSpec(t, hooks.HTTPRequest{Service: "app", StatusCodes: []int{http.StatusOK}})
+
+	result, err := hooks.HTTPHook{Hook: hookSpec, Transport: transport}.ExecuteWithResult(zap.New())
+	assert.Error(t, err)
+	assert.NotContains(t, err.Error(), "response secret")
+	assert.Empty(t, result.Stdout)
+	assert.Equal(t, int32(http.StatusAccepted), *result.StatusCode)
+
+	hookSpec.OnError = "continue"
+
+	err = hooks.HTTPHook{Hook: hookSpec, Transport: transport}.Execute(zap.New())
+	assert.NoError(t, err)
+}
+
+func TestHTTPHookExecutesInverseOpOnError(t *testing.T) {
+	transport, received := setupForHTTPHook(t, http.StatusAccepted, "")
+
+	hookSpec := getHTTPHookSpec(t, hooks.HTTPRequest{Service: "app", Path: "/quiesce", StatusCodes: []int{http.StatusOK}})
+	hookSpec.Op.InverseOp = "unquiesce"
+
+	command, err := json.Marshal(hooks.HTTPRequest{Service: "app", Path: "/unquiesce"})
+	assert.NoError(t, err)
+
+	recipeElements := util.RecipeElements{RecipeWithParams: &recipev1.Recipe{Spec: recipev1.RecipeSpec{
+		Hooks: []*recipev1.Hook{{
+			Name: "test", Namespace: "test-ns", Type: "http",
+			Ops: []*recipev1.Operation{{Name: "unquiesce", Command: string(command)}},
+		}},
+	}}}
+
+	result, err := hooks.HTTPHook{Hook: hookSpec, Transport: transport, RecipeElements: recipeElements}.
+		ExecuteWithResult(zap.New())
+	assert.ErrorContains(t, err, "error executing http hook")
+	assert.True(t, result.InverseOpExecuted)
+	assert.Equal(t, "/unquiesce", received.path)
+}
+
+func TestHTTPHookDoesNotFollowRedirects(t *testing.T) {
+	transport, received := setupForHTTPHook(t, http.StatusFound, "")
+
+	hookSpec := getHTTPHookSpec(t, hooks.HTTPRequest{Service: "app", Path: "/quiesce"})
+
+	err := hooks.HTTPHook{Hook: hookSpec, Transport: transport}.Execute(zap.New())
+	assert.ErrorContains(t, err, "status 302")
+	assert.Equal(t, "/quiesce", received.path)
+}
+
+func TestHTTPHookEvaluatesCondition(t *testing.T) {
+	transport, _ := setupForHTTPHook(t, http.StatusOK, `{"status":{"quiesced":"true"}}`)
+
+	hookSpec := getHTTPHookSpec(t, hooks.HTTPRequest{Service: "app", Condition: "{$.status.quiesced} == {true}"})
+
+	err := hooks.HTTPHook{Hook: hookSpec, Transport: transport}.Execute(zap.New())
+	assert.NoError(t, err)
+
+	hookSpec = getHTTPHookSpec(t, hooks.HTTPRequest{Service: "app", Condition: "{$.status.quiesced} == {false}"})
+
+	err = hooks.HTTPHook{Hook: hookSpec, Transport: transport}.Execute(zap.New())
+	assert.Error(t, err)
+	assert.NotContains(t, err.Error(), "quiesced\":")
+}
@@ -0,0 +1,344 @@
+// SPDX-FileCopyrightText: The RamenDR authors
+// SPDX-License-Identifier: Apache-2.0
+
+package hooks
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"time"
+
+	"github.com/go-logr/logr"
+	"github.com/ramendr/ramen/internal/controller/kubeobjects"
+	"github.com/ramendr/ramen/internal/controller/util"
+	batchv1 "k8s.io/api/batch/v1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/util/wait"
+	corev1client "k8s.io/client-go/kubernetes/typed/core/v1"
+	"k8s.io/utils/ptr"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/yaml"
+)
+
+const (
+	// JobHookLabel labels the Jobs that job hooks create with the name of the
+	// hook and its operation.
+	JobHookLabel = "ramendr.openshift.io/job-hook"
+
+	JobHookCleanupAlways    = "Always"
+	JobHookCleanupOnSuccess = "OnSuccess"
+	JobHookCleanupNever     = "Never"
+
+	defaultJobHookTemplateKey = "job.yaml"
+	defaultJobHookLogLines    = 20
+	jobHookPollInterval       = 2 * time.Second
+)
+
+// JobRequest is the Job a job hook operation runs, as specified, in JSON, by
+// its command.  The Job is that of the given pod spec, or else the manifest of
+// the named ConfigMap's key, a template in which recipe parameters are
+// expanded.  The Job is deleted as its cleanup policy directs, by default once
+// it succeeds, so that a Job that fails is kept to be inspected until the hook
+// operation runs again.  A Job that does not complete within the hook's timeout
+// is deleted, so that a retry of the hook runs a new Job instead of leaving the
+// previous one running.
+//
+// The Job is created with the operator's permissions, so a recipe author may run
+// any pod in the hook's namespace, with any of its service accounts.  Creating
+// or updating a recipe, or a template ConfigMap it names, should therefore be
+// granted only to those trusted to run pods as those service accounts.
+type JobRequest struct {
+	TemplateConfigMapName string          `json:"templateConfigMapName,omitempty"`
+	TemplateKey           string          `json:"templateKey,omitempty"`
+	PodSpec               *corev1.PodSpec `json:"podSpec,omitempty"`
+	CleanupPolicy         string          `json:"cleanupPolicy,omitempty"`
+	LogLines              int64           `json:"logLines,omitempty"`
+}
+
+// JobHook runs the Job its operation's command specifies in the hook's
+// namespace, waits for it to complete, and logs the tail of the logs of its
+// pod.  A Job that fails fails the hook, with the tail of its pod's logs.
+type JobHook struct {
+	Hook           *kubeobjects.HookSpec
+	Reader         client.Reader
+	Writer         client.Writer
+	RecipeElements util.RecipeElements
+	Pods           corev1client.PodsGetter
+}
+
+func (j JobHook) Execute(log logr.Logger) error {
+	_, err := j.ExecuteWithResult(log)
+
+	return err
+}
+
+func (j JobHook) ExecuteWithResult(log logr.Logger) (Result, error) {
+	return executeWithResult(j.Hook, "job", j.RecipeElements, j.operationRun, log)
+}
+
+// operationRun runs the operation of the given hook, the executor's or that of
+// its inverse operation.
+func (j JobHook) operationRun(hook *kubeobjects.HookSpec, result *Result, log logr.Logger) error {
+	j.Hook = hook
+
+	return j.run(result, log)
+}
+
+// run runs the Job, and records it and the tail of its pod's logs in the given
+// result.
+func (j JobHook) run(result *Result, log logr.Logger) error {
+	spec := JobRequest{}
+	if err := json.Unmarshal([]byte(j.Hook.Op.Command), &spec); err != nil {
+		return fmt.Errorf("command %q is not a job request: %w", j.Hook.Op.Command, err)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), time.Duration(getOpHookTimeoutValue(j.Hook))*time.Second)
+	defer cancel()
+
+	job, err := j.jobNew(ctx, spec)
+	if err != nil {
+		return err
+	}
+
+	if err := j.failedJobsDelete(ctx, job, log); err != nil {
+		return err
+	}
+
+	if err := j.Writer.Create(ctx, job); err != nil {
+		return fmt.Errorf("job create in namespace %s: %w", j.Hook.Namespace, err)
+	}
+
+	log.Info("job created", "job", job.GetName(), "namespace", job.GetNamespace())
+
+	result.Targets = []string{job.GetNamespace() + "/" + job.GetName()}
+
+	succeeded, err := j.jobCompletedWait(ctx, job)
+
+	logs := j.jobLogsTail(job, spec.LogLines, log)
+	result.Stdout = outputTruncate(logs)
+	log.Info("job completed", "job", job.GetName(), "succeeded", succeeded, "logs", logs)
+
+	if err != nil {
+		j.jobDelete(job, log)
+
+		return fmt.Errorf("job %s/%s wait for completion: %w; logs: %s", job.GetNamespace(), job.GetName(), err, logs)
+	}
+
+	if cleanup := spec.CleanupPolicy; cleanup == JobHookCleanupAlways ||
+		(succeeded && (cleanup == "" || cleanup == JobHookCleanupOnSuccess)) {
+		j.jobDelete(job, log)
+	}
+
+	if !succeeded {
+		return fmt.Errorf("job %s/%s failed; logs: %s", job.GetNamespace(), job.GetName(), logs)
+	}
+
+	return nil
+}
+
+func (j JobHook) jobNew(ctx context.Context, spec JobRequest) (*batchv1.Job, error) {
+	job := &batchv1.Job{}
+
+	switch {
+	case spec.PodSpec != nil:
+		job.Spec = batchv1.JobSpec{
+			BackoffLimit: ptr.To(int32(0)),
+			Template:     corev1.PodTemplateSpec{Spec: *spec.PodSpec},
+		}
+	case spec.TemplateConfigMapName != "":
+		if err := j.jobTemplateGet(ctx, spec, job); err != nil {
+			return nil, err
+		}
+	default:
+		return nil, fmt.Errorf("either templateConfigMapName or podSpec should be provided to create job")
+	}
+
+	if job.Spec.Template.Spec.RestartPolicy == "" {
+		job.Spec.Template.Spec.RestartPolicy = corev1.RestartPolicyNever
+	}
+
+	job.SetName("")
+	job.SetGenerateName(j.Hook.Name + "-" + j.Hook.Op.Name + "-")
+	job.SetNamespace(j.Hook.Namespace)
+	job.SetResourceVersion("")
+
+	labels := job.GetLabels()
+	if labels == nil {
+		labels = map[string]string{}
+	}
+
+	labels[JobHookLabel] = j.Hook.Name + "." + j.Hook.Op.Name
+	job.SetLabels(labels)
+
+	return job, nil
+}
+
+// failedJobsDelete deletes the failed Jobs that earlier runs of the hook
+// operation of the given Job kept to be inspected, so that they do not
+// accumulate as the hook is retried.
+func (j JobHook) failedJobsDelete(ctx context.Context, job *batchv1.Job, log logr.Logger) error {
+	jobs := &batchv1.JobList{}
+	if err := j.Reader.List(ctx, jobs, client.InNamespace(job.GetNamespace()),
+		client.MatchingLabels{JobHookLabel: job.GetLabels()[JobHookLabel]}); err != nil {
+		return fmt.Errorf("jobs list in namespace %s: %w", job.GetNamespace(), err)
+	}
+
+	for i := range jobs.Items {
+		if jobFailed(&jobs.Items[i]) {
+			log.Info("failed job delete", "job", jobs.Items[i].GetName())
+			j.jobDelete(&jobs.Items[i], log)
+		}
+	}
+
+	return nil
+}
+
+func jobFailed(job *batchv1.Job) bool {
+	for _, condition := range job.Status.Conditions {
+		if condition.Type == batchv1.JobFailed && condition.Status == corev1.ConditionTrue {
+			return true
+		}
+	}
+
+	return false
+}
+
+// jobTemplateGet sets the given Job to that of the manifest of the spec's
+// ConfigMap key, with recipe parameters expanded.
+func (j JobHook) jobTemplateGet(ctx context.Context, spec JobRequest, job *batchv1.Job) error {
+	key := spec.TemplateKey
+	if key == "" {
+		key = defaultJobHookTemplateKey
+	}
+
+	configMap := &corev1.ConfigMap{}
+	if err := j.Reader.Get(ctx, client.ObjectKey{Namespace: j.Hook.Namespace, Name: spec.TemplateConfigMapName},
+		configMap); err != nil {
+		return fmt.Errorf("job template configmap %s/%s get: %w", j.Hook.Namespace, spec.TemplateConfigMapName, err)
+	}
+
+	template, ok := configMap.Data[key]
+	if !ok {
+		return fmt.Errorf("job template configmap %s/%s has no key %s", j.Hook.Namespace,
+			spec.TemplateConfigMapName, key)
+	}
+
+	template = util.ParametersExpand(template, j.RecipeElements.Parameters, nil)
+
+	if err := yaml.Unmarshal([]byte(template), job); err != nil {
+		return fmt.Errorf("job template configmap %s/%s key %s unmarshal: %w", j.Hook.Namespace,
+			spec.TemplateConfigMapName, key, err)
+	}
+
+	return nil
+}
+
+// jobCompletedWait waits for the given Job to complete, and returns whether it
+// succeeded.
+func (j JobHook) jobCompletedWait(ctx context.Context, job *batchv1.Job) (bool, error) {
+	succeeded := false
+
+	err := wait.PollUntilContextCancel(ctx, jobHookPollInterval, true, func(ctx context.Context) (bool, error) {
+		if err := j.Reader.Get(ctx, client.ObjectKeyFromObject(job), job); err != nil {
+			return false, err
+		}
+
+		for _, condition := range job.Status.Conditions {
+			if condition.Status != corev1.ConditionTrue {
+				continue
+			}
+
+			switch condition.Type {
+			case batchv1.JobComplete:
+				succeeded = true
+
+				return true, nil
+			case batchv1.JobFailed:
+				return true, nil
+			}
+		}
+
+		return false, nil
+	})
+
+	return succeeded, err
+}
+
+// jobLogsTail returns the tail of the logs of the given Job's latest pod.
+func (j JobHook) jobLogsTail(job *batchv1.Job, lines int64, log logr.Logger) string {
+	if lines == 0 {
+		lines = defaultJobHookLogLines
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), jobHookPollInterval*5)
+	defer cancel()
+
+	pods := &corev1.PodList{}
+	if err := j.Reader.List(ctx, pods, client.InNamespace(job.GetNamespace()),
+		client.MatchingLabels{batchv1.JobNameLabel: job.GetName()}); err != nil {
+		log.Error(err, "job pods list", "job", job.GetName())
+
+		return ""
+	}
+
+	if len(pods.Items) == 0 {
+		return ""
+	}
+
+	latest := &pods.Items[0]
+
+	for i := range pods.Items {
+		if latest.CreationTimestamp.Before(&pods.Items[i].CreationTimestamp) {
+			latest = &pods.Items[i]
+		}
+	}
+
+	podsGetter, err := j.podsGetter()
+	if err != nil {
+		log.Error(err, "job pod logs get", "pod", latest.GetName())
+
+		return ""
+	}
+
+	stream, err := podsGetter.Pods(latest.GetNamespace()).
+		GetLogs(latest.GetName(), &corev1.PodLogOptions{TailLines: &lines}).Stream(ctx)
+	if err != nil {
+		log.Error(err, "job pod logs get", "pod", latest.GetName())
+
+		return ""
+	}
+
+	defer stream.Close()
+
+	logs, err := io.ReadAll(stream)
+	if err != nil {
+		log.Error(err, "job pod logs read", "pod", latest.GetName())
+	}
+
+	return string(logs)
+}
+
+func (j JobHook) podsGetter() (corev1client.PodsGetter, error) {
+	if j.Pods != nil {
+		return j.Pods, nil
+	}
+
+	coreClient, err := clientsetGet()
+	if err != nil {
+		return nil, err
+	}
+
+	return coreClient.CoreV1(), nil
+}
+
+func (j JobHook) jobDelete(job *batchv1.Job, log logr.Logger) {
+	ctx, cancel := context.WithTimeout(context.Background(), jobHookPollInterval*5)
+	defer cancel()
+
+	if err := j.Writer.Delete(ctx, job, client.PropagationPolicy(metav1.DeletePropagationBackground)); err != nil {
+		log.Error(err, "job delete", "job", job.GetName(), "namespace", job.GetNamespace())
+	}
+}
@@ -0,0 +1,177 @@
+package hooks_test
+
+import (
+	"context"
+	"encoding/json"
+	"testing"
+
+	"github.com/ramendr/ramen/internal/controller/hooks"
+	"github.com/ramendr/ramen/internal/controller/kubeobjects"
+	"github.com/ramendr/ramen/internal/controller/util"
+	"github.com/stretchr/testify/assert"
+	batchv1 "k8s.io/api/batch/v1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	kubefake "k8s.io/client-go/kubernetes/fake"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/interceptor"
+	"sigs.k8s.io/controller-runtime/pkg/log/zap"
+)
+
+// setupForJobHook returns a client that completes each Job it creates, as the
+// given condition type, with a pod.
+func setupForJobHook(t *testing.T, conditionType batchv1.JobConditionType, objects ...client.Object) client.Client {
+	t.Helper()
+
+	addToSchemes := []func(*runtime.Scheme) error{corev1.AddToScheme, batchv1.AddToScheme}
+
+	return getHookTestClientBuilder(t, addToSchemes, objects...).
+		WithInterceptorFuncs(interceptor.Funcs{
+			Create: func(ctx context.Context, c client.WithWatch, obj client.Object, opts ...client.CreateOption) error {
+				job, ok := obj.(*batchv1.Job)
+				if !ok {
+					return c.Create(ctx, obj, opts...)
+				}
+
+				job.Status.Conditions = []batchv1.JobCondition{{Type: conditionType, Status: corev1.ConditionTrue}}
+
+				if err := c.Create(ctx, job, opts...); err != nil {
+					return err
+				}
+
+				return c.Create(ctx, &corev1.Pod{ObjectMeta: metav1.ObjectMeta{
+					Name:      job.GetName() + "-pod",
+					Namespace: job.GetNamespace(),
+					Labels:    map[string]string{batchv1.JobNameLabel: job.GetName()},
+				}})
+			},
+		}).Build()
+}
+
+func getJobHookSpec(t *testing.T, request hooks.JobRequest) *kubeobjects.HookSpec {
+	t.Helper()
+
+	command, err := json.Marshal(request)
+	assert.NoError(t, err)
+
+	return getHookTestSpec("job", "backup", string(command))
+}
+
+func getJobHookPodSpec() *corev1.PodSpec {
+	return &corev1.PodSpec{Containers: []corev1.Container{{Name: "backup", Image: "test-image"}}}
+}
+
+func getJobHookJobs(t *testing.T, c client.Client) []batchv1.Job {
+	t.Helper()
+
+	jobs := &batchv1.JobList{}
+	assert.NoError(t, c.List(context.Background(), jobs, client.InNamespace("test-ns")))
+
+	return jobs.Items
+}
+
+func TestJobHookRunsPodSpecJobAndCleansUp(t *testing.T) {
+	c := setupForJobHook(t, batchv1.JobComplete)
+
+	hookSpec := getJobHookSpec(t, hooks.JobRequest{PodSpec: getJobHookPodSpec()})
+
+	err := hooks.JobHook{Hook: hookSpec, Reader: c, Writer: c, Pods: kubefake.NewSimpleClientset().CoreV1()}.
+		Execute(zap.New())
+	assert.NoError(t, err)
+	assert.Empty(t, getJobHookJobs(t, c))
+}
+
+func TestJobHookRunsTemplateJobWithParameters(t *testing.T) {
+	c := setupForJobHook(t, batchv1.JobComplete, &corev1.ConfigMap{
+		ObjectMeta: metav1.ObjectMeta{Name: "job-template", Namespace: "test-ns"},
+		Data: map[string]string{"job.yaml": `
+spec:
+  template:
+    spec:
+      containers:
+      - name: backup
+        image: $image
+`},
+	})
+
+	hookSpec := getJobHookSpec(t, hooks.JobRequest{
+		TemplateConfigMapName: "job-template",
+		CleanupPolicy:         hooks.JobHookCleanupNever,
+	})
+	recipeElements := util.RecipeElements{Parameters: map[string][]string{"image": {"backup-image"}}}
+
+	err := hooks.JobHook{
+		Hook: hookSpec, Reader: c, Writer: c, RecipeElements: recipeElements,
+		Pods: kubefake.NewSimpleClientset().CoreV1(),
+	}.Execute(zap.New())
+	assert.NoError(t, err)
+
+	jobs := getJobHookJobs(t, c)
+	assert.Len(t, jobs, 1)
+	assert.Equal(t, "backup-image", jobs[0].Spec.Template.Spec.Containers[0].Image)
+	assert.Equal(t, corev1.RestartPolicyNever, jobs[0].Spec.Template.Spec.RestartPolicy)
+	assert.Equal(t, "test.backup", jobs[0].Labels[hooks.JobHookLabel])
+}
+
+func TestJobHookFailsWithLogsAndKeepsFailedJob(t *testing.T) {
+	c := setupForJobHook(t, batchv1.JobFailed)
+
+	hookSpec := getJobHookSpec(t, hooks.JobRequest{PodSpec: getJobHookPodSpec()})
+
+	result, err := hooks.JobHook{Hook: hookSpec, Reader: c, Writer: c, Pods: kubefake.NewSimpleClientset().CoreV1()}.
+		ExecuteWithResult(zap.New())
+	assert.ErrorContains(t, err, "fake logs")
+	assert.Equal(t, "fake logs", result.Stdout)
+	assert.Len(t, getJobHookJobs(t, c), 1)
+}
+
+func TestJobHookFailsForMissingJobSpec(t *testing.T) {
+	c := setupForJobHook(t, batchv1.JobComplete)
+
+	err := hooks.JobHook{Hook: getJobHookSpec(t, hooks.JobRequest{}), Reader: c, Writer: c}.Execute(zap.New())
+	assert.Error(t, err)
+	assert.Empty(t, getJobHookJobs(t, c))
+}
+
+func TestJobHookDeletesJobOnTimeout(t *testing.T) {
+	c := setupForJobHook(t, batchv1.JobSuspended)
+
+	hookSpec := getJobHookSpec(t, hooks.JobRequest{PodSpec: getJobHookPodSpec(), CleanupPolicy: hooks.JobHookCleanupNever})
+	hookSpec.Timeout = 1
+
+	err := hooks.JobHook{Hook: hookSpec, Reader: c, Writer: c, Pods: kubefake.NewSimpleClientset().CoreV1()}.
+		Execute(zap.New())
+	assert.Error(t, err)
+	assert.Empty(t, getJobHookJobs(t, c))
+}
+
+func TestJobHookDeletesFailedJobOfEarlierRun(t *testing.T) {
+	failedJob := &batchv1.Job{
+		ObjectMeta: metav1.ObjectMeta{
+			Name: "test-backup-failed", Namespace: "test-ns",
+			Labels: map[string]string{hooks.JobHookLabel: "test.backup"},
+		},
+		Status: batchv1.JobStatus{
+			Conditions: []batchv1.JobCondition{{Type: batchv1.JobFailed, Status: corev1.ConditionTrue}},
+		},
+	}
+	otherJob := &batchv1.Job{
+		ObjectMeta: metav1.ObjectMeta{
+			Name: "test-restore-failed", Namespace: "test-ns",
+			Labels: map[string]string{hooks.JobHookLabel: "test.restore"},
+		},
+		Status: failedJob.Status,
+	}
+	c := setupForJobHook(t, batchv1.JobComplete, failedJob, otherJob)
+
+	hookSpec := getJobHookSpec(t, hooks.JobRequest{PodSpec: getJobHookPodSpec()})
+
+	err := hooks.JobHook{Hook: hookSpec, Reader: c, Writer: c, Pods: kubefake.NewSimpleClientset().CoreV1()}.
+		Execute(zap.New())
+	assert.NoError(t, err)
+
+	jobs := getJobHookJobs(t, c)
+	assert.Len(t, jobs, 1)
+	assert.Equal(t, otherJob.Name, jobs[0].Name)
+}
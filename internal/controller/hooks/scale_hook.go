@@ -0,0 +1,308 @@
+// SPDX-FileCopyrightText: The RamenDR authors
+// SPDX-License-Identifier: Apache-2.0
+
+package hooks
+
+import (
+	"context"
+	"fmt"
+	"regexp"
+	"strconv"
+	"time"
+
+	"github.com/go-logr/logr"
+	"github.com/ramendr/ramen/internal/controller/kubeobjects"
+	"github.com/ramendr/ramen/internal/controller/util"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/util/wait"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+const (
+	// ScaleHookReplicasAnnotation records, on a scaled resource, its replica
+	// count before it was first scaled, so that it can be restored.
+	ScaleHookReplicasAnnotation = "ramendr.openshift.io/scale-hook-replicas"
+
+	// scaleHookRestoreCommand restores the replica counts recorded
+	scaleHookRestoreCommand = "restore"
+
+	scaleHookPollInterval = time.Second
+)
+
+// scaleHookResources are the kinds of the resources a scale hook scales, by
+// the select resource value that selects them.
+var scaleHookResources = map[string]schema.GroupVersionKind{
+	"deployment":       {Group: "apps", Version: "v1", Kind: "Deployment"},
+	"statefulset":      {Group: "apps", Version: "v1", Kind: "StatefulSet"},
+	"deploymentconfig": {Group: "apps.openshift.io", Version: "v1", Kind: "DeploymentConfig"},
+}
+
+// ScaleHook scales the resources its selectors select to the replica count of
+// its operation's command, recording their replica counts before, and waits
+// for them to be scaled.  A command of "restore" scales them back to the
+// replica counts recorded.  Deployments, statefulsets and deployment configs
+// are scaled, unless the select resource is one of them.
+type ScaleHook struct {
+	Hook           *kubeobjects.HookSpec
+	Reader         client.Reader
+	Writer         client.Writer
+	RecipeElements util.RecipeElements
+}
+
+func (s ScaleHook) Execute(log logr.Logger) error {
+	_, err := s.ExecuteWithResult(log)
+
+	return err
+}
+
+func (s ScaleHook) ExecuteWithResult(log logr.Logger) (Result, error) {
+	if err := selectorsCheck(s.Hook); err != nil {
+		return Result{Error: err}, err
+	}
+
+	return executeWithResult(s.Hook, "scale", s.RecipeElements, s.operationRun, log)
+}
+
+// operationRun runs the operation of the given hook, the executor's or that of
+// its inverse operation.
+func (s ScaleHook) operationRun(hook *kubeobjects.HookSpec, result *Result, log logr.Logger) error {
+	s.Hook = hook
+
+	return s.scale(result, log)
+}
+
+// scale scales the selected resources, and records those it scales in the
+// given result.
+func (s ScaleHook) scale(result *Result, log logr.Logger) error {
+	restore := s.Hook.Op.Command == scaleHookRestoreCommand
+
+	var replicas int64
+
+	if !restore {
+		var err error
+
+		replicas, err = strconv.ParseInt(s.Hook.Op.Command, 10, 32)
+		if err != nil || replicas < 0 {
+			return fmt.Errorf("command %q is neither a replica count nor %q", s.Hook.Op.Command,
+				scaleHookRestoreCommand)
+		}
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), time.Duration(getOpHookTimeoutValue(s.Hook))*time.Second)
+	defer cancel()
+
+	objects, err := s.objectsGet(ctx)
+	if err != nil {
+		return err
+	}
+
+	for _, object := range objects {
+		objectReplicas, scaled, err := s.objectScale(ctx, object, restore, replicas)
+		if err != nil {
+			return err
+		}
+
+		if !scaled {
+			continue
+		}
+
+		result.Targets = append(result.Targets,
+			object.GetKind()+" "+object.GetNamespace()+"/"+object.GetName())
+
+		log.Info("scaled", "kind", object.GetKind(), "name", object.GetName(), "namespace", object.GetNamespace(),
+			"replicas", objectReplicas)
+	}
+
+	for _, object := range objects {
+		if err := s.objectScaledWait(ctx, object); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// objectScale sets the replica count of the given object, and returns it
+// along with whether the object was scaled.
+func (s ScaleHook) objectScale(
+	ctx context.Context, object *unstructured.Unstructured, restore bool, replicas int64,
+) (int64, bool, error) {
+	original := object.DeepCopy()
+	annotations := object.GetAnnotations()
+
+	currentReplicas, found, err := unstructured.NestedInt64(object.Object, "spec", "replicas")
+	if err != nil {
+		return 0, false, fmt.Errorf("%s %s/%s replicas get: %w", object.GetKind(), object.GetNamespace(),
+			object.GetName(), err)
+	}
+
+	if !found {
+		currentReplicas = 1
+	}
+
+	if restore {
+		recordedReplicas, ok := annotations[ScaleHookReplicasAnnotation]
+		if !ok {
+			return 0, false, nil
+		}
+
+		if replicas, err = strconv.ParseInt(recordedReplicas, 10, 32); err != nil {
+			return 0, false, fmt.Errorf("%s %s/%s recorded replicas %q parse: %w", object.GetKind(),
+				object.GetNamespace(), object.GetName(), recordedReplicas, err)
+		}
+
+		delete(annotations, ScaleHookReplicasAnnotation)
+	} else {
+		if annotations == nil {
+			annotations = map[string]string{}
+		}
+
+		// Replicas recorded previously are retained, as they precede any scaling
+		if _, ok := annotations[ScaleHookReplicasAnnotation]; !ok {
+			annotations[ScaleHookReplicasAnnotation] = strconv.FormatInt(currentReplicas, 10)
+		}
+	}
+
+	object.SetAnnotations(annotations)
+
+	if err := unstructured.SetNestedField(object.Object, replicas, "spec", "replicas"); err != nil {
+		return 0, false, fmt.Errorf("%s %s/%s replicas set: %w", object.GetKind(), object.GetNamespace(),
+			object.GetName(), err)
+	}
+
+	if err := s.Writer.Patch(ctx, object, client.MergeFrom(original)); err != nil {
+		return 0, false, fmt.Errorf("%s %s/%s scale: %w", object.GetKind(), object.GetNamespace(),
+			object.GetName(), err)
+	}
+
+	return replicas, true, nil
+}
+
+// objectScaledWait waits for the given object's controller to observe its
+// replica count, and for its replicas to number it.
+func (s ScaleHook) objectScaledWait(ctx context.Context, object *unstructured.Unstructured) error {
+	key := client.ObjectKeyFromObject(object)
+
+	if err := wait.PollUntilContextCancel(ctx, scaleHookPollInterval, true,
+		func(ctx context.Context) (bool, error) {
+			current := &unstructured.Unstructured{}
+			current.SetGroupVersionKind(object.GroupVersionKind())
+
+			if err := s.Reader.Get(ctx, key, current); err != nil {
+				return false, err
+			}
+
+			replicas, _, _ := unstructured.NestedInt64(current.Object, "spec", "replicas")
+			statusReplicas, _, _ := unstructured.NestedInt64(current.Object, "status", "replicas")
+			observedGeneration, _, _ := unstructured.NestedInt64(current.Object, "status", "observedGeneration")
+
+			return observedGeneration >= current.GetGeneration() && statusReplicas == replicas, nil
+		},
+	); err != nil {
+		return fmt.Errorf("%s %s wait for scale: %w", object.GetKind(), key, err)
+	}
+
+	return nil
+}
+
+// objectsGet returns the resources the hook's label or name selector selects.
+func (s ScaleHook) objectsGet(ctx context.Context) ([]*unstructured.Unstructured, error) {
+	kinds := []schema.GroupVersionKind{}
+
+	if s.Hook.SelectResource == "" {
+		for _, name := range []string{"deployment", "statefulset", "deploymentconfig"} {
+			kinds = append(kinds, scaleHookResources[name])
+		}
+	} else {
+		kind, ok := scaleHookResources[s.Hook.SelectResource]
+		if !ok {
+			return nil, fmt.Errorf("resource %q is not scalable", s.Hook.SelectResource)
+		}
+
+		kinds = append(kinds, kind)
+	}
+
+	objects := []*unstructured.Unstructured{}
+
+	for _, kind := range kinds {
+		kindObjects, err := s.kindObjectsGet(ctx, kind)
+		if err != nil {
+			// Deployment configs are served by OpenShift only
+			if s.Hook.SelectResource == "" && meta.IsNoMatchError(err) {
+				continue
+			}
+
+			return nil, err
+		}
+
+		objects = append(objects, kindObjects...)
+	}
+
+	return objects, nil
+}
+
+func (s ScaleHook) kindObjectsGet(
+	ctx context.Context, kind schema.GroupVersionKind,
+) ([]*unstructured.Unstructured, error) {
+	list := &unstructured.UnstructuredList{}
+	list.SetGroupVersionKind(kind.GroupVersion().WithKind(kind.Kind + "List"))
+
+	if err := s.Reader.List(ctx, list, client.InNamespace(s.Hook.Namespace)); err != nil {
+		return nil, fmt.Errorf("%s list in namespace %s: %w", kind.Kind, s.Hook.Namespace, err)
+	}
+
+	selected, err := s.selectorNew()
+	if err != nil {
+		return nil, err
+	}
+
+	objects := []*unstructured.Unstructured{}
+
+	for i := range list.Items {
+		if selected(&list.Items[i]) {
+			objects = append(objects, &list.Items[i])
+		}
+	}
+
+	return objects, nil
+}
+
+// selectorNew returns a function that returns true for objects that either the
+// label selector or the name selector, a name or a regular expression, selects.
+func (s ScaleHook) selectorNew() (func(*unstructured.Unstructured) bool, error) {
+	labelSelected := func(*unstructured.Unstructured) bool { return false }
+
+	if s.Hook.LabelSelector != nil {
+		selector, err := metav1.LabelSelectorAsSelector(s.Hook.LabelSelector)
+		if err != nil {
+			return nil, fmt.Errorf("error converting labelSelector to selector: %w", err)
+		}
+
+		labelSelected = func(object *unstructured.Unstructured) bool {
+			return selector.Matches(labels.Set(object.GetLabels()))
+		}
+	}
+
+	nameSelected := func(*unstructured.Unstructured) bool { return false }
+
+	switch {
+	case s.Hook.NameSelector == "":
+	case isValidK8sName(s.Hook.NameSelector):
+		nameSelected = func(object *unstructured.Unstructured) bool {
+			return object.GetName() == s.Hook.NameSelector
+		}
+	case isValidRegex(s.Hook.NameSelector):
+		re := regexp.MustCompile(s.Hook.NameSelector)
+		nameSelected = func(object *unstructured.Unstructured) bool { return re.MatchString(object.GetName()) }
+	default:
+		return nil, fmt.Errorf("nameSelector is neither distinct name nor regex")
+	}
+
+	return func(object *unstructured.Unstructured) bool {
+		return labelSelected(object) || nameSelected(object)
+	}, nil
+}
@@ -0,0 +1,139 @@
+package hooks_test
+
+import (
+	"context"
+	"testing"
+
+	"github.com/ramendr/ramen/internal/controller/hooks"
+	"github.com/ramendr/ramen/internal/controller/kubeobjects"
+	"github.com/ramendr/ramen/internal/controller/util"
+	recipev1 "github.com/ramendr/recipe/api/v1alpha1"
+	"github.com/stretchr/testify/assert"
+	appsv1 "k8s.io/api/apps/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/utils/ptr"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/log/zap"
+)
+
+func setupForScaleHook(t *testing.T, objects ...client.Object) client.Client {
+	t.Helper()
+
+	return getHookTestClientBuilder(t, []func(*runtime.Scheme) error{appsv1.AddToScheme}, objects...).Build()
+}
+
+func getDeploymentForScaleHook(replicas, statusReplicas int32, annotations map[string]string) *appsv1.Deployment {
+	return &appsv1.Deployment{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:        "test-deployment",
+			Namespace:   "test-ns",
+			Labels:      map[string]string{"appname": "busybox"},
+			Annotations: annotations,
+		},
+		Spec:   appsv1.DeploymentSpec{Replicas: ptr.To(replicas)},
+		Status: appsv1.DeploymentStatus{Replicas: statusReplicas},
+	}
+}
+
+func getScaleHookSpec(command string) *kubeobjects.HookSpec {
+	hookSpec := getHookTestSpec("scale", "down", command)
+	hookSpec.SelectResource = "deployment"
+	hookSpec.LabelSelector = &metav1.LabelSelector{MatchLabels: map[string]string{"appname": "busybox"}}
+	hookSpec.Timeout = 1
+
+	return hookSpec
+}
+
+func getRecipeWithScaleHook() *recipev1.Recipe {
+	return &recipev1.Recipe{
+		Spec: recipev1.RecipeSpec{
+			Hooks: []*recipev1.Hook{
+				{
+					Name:           "test",
+					Namespace:      "test-ns",
+					Type:           "scale",
+					SelectResource: "deployment",
+					LabelSelector:  &metav1.LabelSelector{MatchLabels: map[string]string{"appname": "busybox"}},
+					Timeout:        1,
+					Ops: []*recipev1.Operation{
+						{Name: "down", Command: "0", InverseOp: "up"},
+						{Name: "up", Command: "restore"},
+					},
+				},
+			},
+		},
+	}
+}
+
+func getScaledDeployment(t *testing.T, c client.Client) *appsv1.Deployment {
+	t.Helper()
+
+	deployment := &appsv1.Deployment{}
+	err := c.Get(context.Background(), client.ObjectKey{Namespace: "test-ns", Name: "test-deployment"}, deployment)
+	assert.NoError(t, err)
+
+	return deployment
+}
+
+func TestScaleHookScalesAndRecordsReplicas(t *testing.T) {
+	c := setupForScaleHook(t, getDeploymentForScaleHook(3, 0, nil))
+
+	err := hooks.ScaleHook{Hook: getScaleHookSpec("0"), Reader: c, Writer: c}.Execute(zap.New())
+	assert.NoError(t, err)
+
+	deployment := getScaledDeployment(t, c)
+	assert.Equal(t, int32(0), *deployment.Spec.Replicas)
+	assert.Equal(t, "3", deployment.Annotations[hooks.ScaleHookReplicasAnnotation])
+}
+
+func TestScaleHookRetainsRecordedReplicas(t *testing.T) {
+	c := setupForScaleHook(t, getDeploymentForScaleHook(1, 0,
+		map[string]string{hooks.ScaleHookReplicasAnnotation: "3"}))
+
+	hookSpec := getScaleHookSpec("0")
+	hookSpec.SelectResource = ""
+
+	err := hooks.ScaleHook{Hook: hookSpec, Reader: c, Writer: c}.Execute(zap.New())
+	assert.NoError(t, err)
+
+	assert.Equal(t, "3", getScaledDeployment(t, c).Annotations[hooks.ScaleHookReplicasAnnotation])
+}
+
+func TestScaleHookRestoresRecordedReplicas(t *testing.T) {
+	c := setupForScaleHook(t, getDeploymentForScaleHook(0, 2,
+		map[string]string{hooks.ScaleHookReplicasAnnotation: "2"}))
+
+	err := hooks.ScaleHook{Hook: getScaleHookSpec("restore"), Reader: c, Writer: c}.Execute(zap.New())
+	assert.NoError(t, err)
+
+	deployment := getScaledDeployment(t, c)
+	assert.Equal(t, int32(2), *deployment.Spec.Replicas)
+	assert.NotContains(t, deployment.Annotations, hooks.ScaleHookReplicasAnnotation)
+}
+
+func TestScaleHookExecutesInverseOpOnTimeout(t *testing.T) {
+	c := setupForScaleHook(t, getDeploymentForScaleHook(3, 3, nil))
+
+	hookSpec := getScaleHookSpec("0")
+	hookSpec.Op.InverseOp = "up"
+
+	recipeElements := util.RecipeElements{RecipeWithParams: getRecipeWithScaleHook()}
+
+	err := hooks.ScaleHook{Hook: hookSpec, Reader: c, Writer: c, RecipeElements: recipeElements}.Execute(zap.New())
+	assert.Error(t, err)
+
+	deployment := getScaledDeployment(t, c)
+	assert.Equal(t, int32(3), *deployment.Spec.Replicas)
+	assert.NotContains(t, deployment.Annotations, hooks.ScaleHookReplicasAnnotation)
+}
+
+func TestScaleHookIgnoresErrorOnContinue(t *testing.T) {
+	c := setupForScaleHook(t, getDeploymentForScaleHook(3, 3, nil))
+
+	hookSpec := getScaleHookSpec("0")
+	hookSpec.OnError = "continue"
+
+	err := hooks.ScaleHook{Hook: hookSpec, Reader: c, Writer: c}.Execute(zap.New())
+	assert.NoError(t, err)
+}
@@ -0,0 +1,344 @@
+// SPDX-FileCopyrightText: The RamenDR authors
+// SPDX-License-Identifier: Apache-2.0
+
+package hooks
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"regexp"
+	"strings"
+	"time"
+
+	"github.com/go-logr/logr"
+	"github.com/ramendr/ramen/internal/controller/core"
+	"github.com/ramendr/ramen/internal/controller/kubeobjects"
+	"github.com/ramendr/ramen/internal/controller/util"
+	k8serrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/client-go/kubernetes/scheme"
+	"k8s.io/client-go/rest"
+	virtv1 "kubevirt.io/api/core/v1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/config"
+)
+
+const (
+	vmHookFreezeCommand   = "freeze"
+	vmHookUnfreezeCommand = "unfreeze"
+
+	// defaultVMUnfreezeTimeout is how long after it is frozen KubeVirt thaws a
+	// guest's filesystems, if it is not unfrozen before.
+	defaultVMUnfreezeTimeout = 5 * time.Minute
+
+	// vmFSFrozen is the filesystem freeze status of a frozen guest
+	vmFSFrozen = "frozen"
+)
+
+// VMFreezer freezes and unfreezes the guest filesystems of VirtualMachineInstances.
+type VMFreezer interface {
+	Freeze(ctx context.Context, namespace, name string, unfreezeTimeout time.Duration) error
+	Unfreeze(ctx context.Context, namespace, name string) error
+}
+
+// VMHook freezes, or unfreezes, the guest filesystems of the running
+// VirtualMachines its selectors select, as its operation's command, "freeze"
+// or "unfreeze", directs.  A command of "freeze" may be followed by how long
+// after which KubeVirt unfreezes them regardless, e.g. "freeze 2m", so that a
+// guest is never left frozen if its unfreeze operation is not executed.  The
+// VirtualMachines are selected in the hook's namespace, or else in the
+// protected VM namespaces.
+type VMHook struct {
+	Hook           *kubeobjects.HookSpec
+	Reader         client.Reader
+	RecipeElements util.RecipeElements
+	Freezer        VMFreezer
+}
+
+func (h VMHook) Execute(log logr.Logger) error {
+	_, err := h.ExecuteWithResult(log)
+
+	return err
+}
+
+func (h VMHook) ExecuteWithResult(log logr.Logger) (Result, error) {
+	if err := selectorsCheck(h.Hook); err != nil {
+		return Result{Error: err}, err
+	}
+
+	return executeWithResult(h.Hook, "vm", h.RecipeElements, h.operationRun, log)
+}
+
+// operationRun runs the operation of the given hook, the executor's or that of
+// its inverse operation.
+func (h VMHook) operationRun(hook *kubeobjects.HookSpec, result *Result, log logr.Logger) error {
+	h.Hook = hook
+
+	return h.freezeOrUnfreeze(result, log)
+}
+
+// freezeOrUnfreeze freezes, or unfreezes, the guests of the selected running
+// VirtualMachines, and records them in the given result.  Once any fails to
+// freeze, unless the operation's errors are to be ignored, those frozen are
+// unfrozen.
+func (h VMHook) freezeOrUnfreeze(result *Result, log logr.Logger) error {
+	command := strings.Fields(h.Hook.Op.Command)
+	if len(command) == 0 || (command[0] != vmHookFreezeCommand && command[0] != vmHookUnfreezeCommand) {
+		return fmt.Errorf("command %q is neither %s nor %s", h.Hook.Op.Command, vmHookFreezeCommand,
+			vmHookUnfreezeCommand)
+	}
+
+	unfreezeTimeout, err := vmUnfreezeTimeout(command)
+	if err != nil {
+		return err
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), time.Duration(getOpHookTimeoutValue(h.Hook))*time.Second)
+	defer cancel()
+
+	freezer, err := h.freezer()
+	if err != nil {
+		return err
+	}
+
+	vmis, err := h.vmisGet(ctx)
+	if err != nil {
+		return err
+	}
+
+	if command[0] == vmHookUnfreezeCommand {
+		return h.unfreeze(ctx, freezer, vmis, result, log)
+	}
+
+	return h.freeze(ctx, freezer, vmis, unfreezeTimeout, result, log)
+}
+
+func vmUnfreezeTimeout(command []string) (time.Duration, error) {
+	if len(command) < 2 || command[0] != vmHookFreezeCommand {
+		return defaultVMUnfreezeTimeout, nil
+	}
+
+	unfreezeTimeout, err := time.ParseDuration(command[1])
+	if err != nil || unfreezeTimeout <= 0 {
+		return 0, fmt.Errorf("freeze command unfreeze timeout %q is not a positive duration", command[1])
+	}
+
+	return unfreezeTimeout, nil
+}
+
+func (h VMHook) freeze(ctx context.Context, freezer VMFreezer, vmis []virtv1.VirtualMachineInstance,
+	unfreezeTimeout time.Duration, result *Result, log logr.Logger,
+) error {
+	frozen := make([]virtv1.VirtualMachineInstance, 0, len(vmis))
+	errs := make([]error, 0)
+
+	for i := range vmis {
+		vmi := &vmis[i]
+		result.Targets = append(result.Targets, vmi.GetNamespace()+"/"+vmi.GetName())
+
+		// KubeVirt does not reschedule the unfreeze of a guest that is already
+		// frozen, e.g. by a previous attempt, so it is unfrozen, and frozen again,
+		// for it to stay frozen for this freeze's timeout rather than what is left
+		// of the previous one's
+		if vmi.Status.FSFreezeStatus == vmFSFrozen {
+			log.Info("vm already frozen, refreezing", "vm", vmi.GetName(), "namespace", vmi.GetNamespace())
+
+			if err := freezer.Unfreeze(ctx, vmi.GetNamespace(), vmi.GetName()); err != nil {
+				errs = append(errs, fmt.Errorf("vm %s/%s unfreeze to refreeze: %w", vmi.GetNamespace(), vmi.GetName(), err))
+
+				continue
+			}
+		}
+
+		if err := freezer.Freeze(ctx, vmi.GetNamespace(), vmi.GetName(), unfreezeTimeout); err != nil {
+			errs = append(errs, fmt.Errorf("vm %s/%s freeze: %w", vmi.GetNamespace(), vmi.GetName(), err))
+
+			continue
+		}
+
+		log.Info("vm frozen", "vm", vmi.GetName(), "namespace", vmi.GetNamespace(), "unfreezeTimeout", unfreezeTimeout)
+
+		frozen = append(frozen, *vmi)
+	}
+
+	if len(errs) == 0 {
+		return nil
+	}
+
+	if shouldOpHookBeFailedOnError(h.Hook) {
+		if err := h.unfreeze(ctx, freezer, frozen, &Result{}, log); err != nil {
+			errs = append(errs, err)
+		}
+	}
+
+	return errors.Join(errs...)
+}
+
+func (h VMHook) unfreeze(ctx context.Context, freezer VMFreezer, vmis []virtv1.VirtualMachineInstance,
+	result *Result, log logr.Logger,
+) error {
+	errs := make([]error, 0)
+
+	for i := range vmis {
+		vmi := &vmis[i]
+		result.Targets = append(result.Targets, vmi.GetNamespace()+"/"+vmi.GetName())
+
+		if err := freezer.Unfreeze(ctx, vmi.GetNamespace(), vmi.GetName()); err != nil {
+			errs = append(errs, fmt.Errorf("vm %s/%s unfreeze: %w", vmi.GetNamespace(), vmi.GetName(), err))
+
+			continue
+		}
+
+		log.Info("vm unfrozen", "vm", vmi.GetName(), "namespace", vmi.GetNamespace())
+	}
+
+	return errors.Join(errs...)
+}
+
+// vmisGet returns the instances of the selected VirtualMachines that are
+// running.
+func (h VMHook) vmisGet(ctx context.Context) ([]virtv1.VirtualMachineInstance, error) {
+	vms, err := h.vmsGet(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	vmis := make([]virtv1.VirtualMachineInstance, 0, len(vms))
+
+	for _, vm := range vms {
+		vmi := virtv1.VirtualMachineInstance{}
+
+		if err := h.Reader.Get(ctx, client.ObjectKeyFromObject(&vm), &vmi); err != nil {
+			if k8serrors.IsNotFound(err) {
+				continue
+			}
+
+			return nil, fmt.Errorf("vmi %s/%s get: %w", vm.GetNamespace(), vm.GetName(), err)
+		}
+
+		vmis = append(vmis, vmi)
+	}
+
+	return vmis, nil
+}
+
+// vmsGet returns the VirtualMachines its label selector, and those its name
+// selector, select.
+func (h VMHook) vmsGet(ctx context.Context) ([]virtv1.VirtualMachine, error) {
+	selected := make([]virtv1.VirtualMachine, 0)
+
+	for _, namespace := range h.namespaces() {
+		if h.Hook.LabelSelector != nil {
+			selector, err := metav1.LabelSelectorAsSelector(h.Hook.LabelSelector)
+			if err != nil {
+				return nil, fmt.Errorf("label selector %v convert: %w", h.Hook.LabelSelector, err)
+			}
+
+			vms := &virtv1.VirtualMachineList{}
+			if err := h.Reader.List(ctx, vms, client.InNamespace(namespace),
+				client.MatchingLabelsSelector{Selector: selector}); err != nil {
+				return nil, fmt.Errorf("vms list in namespace %s: %w", namespace, err)
+			}
+
+			selected = append(selected, vms.Items...)
+		}
+
+		if h.Hook.NameSelector != "" {
+			vms, err := h.vmsGetByName(ctx, namespace)
+			if err != nil {
+				return nil, err
+			}
+
+			selected = append(selected, vms...)
+		}
+	}
+
+	return selected, nil
+}
+
+func (h VMHook) vmsGetByName(ctx context.Context, namespace string) ([]virtv1.VirtualMachine, error) {
+	vms := &virtv1.VirtualMachineList{}
+	if err := h.Reader.List(ctx, vms, client.InNamespace(namespace)); err != nil {
+		return nil, fmt.Errorf("vms list in namespace %s: %w", namespace, err)
+	}
+
+	if isValidK8sName(h.Hook.NameSelector) {
+		for _, vm := range vms.Items {
+			if vm.GetName() == h.Hook.NameSelector {
+				return []virtv1.VirtualMachine{vm}, nil
+			}
+		}
+
+		return nil, nil
+	}
+
+	re, err := regexp.Compile(h.Hook.NameSelector)
+	if err != nil {
+		return nil, fmt.Errorf("name selector %s compile: %w", h.Hook.NameSelector, err)
+	}
+
+	selected := make([]virtv1.VirtualMachine, 0)
+
+	for _, vm := range vms.Items {
+		if re.MatchString(vm.GetName()) {
+			selected = append(selected, vm)
+		}
+	}
+
+	return selected, nil
+}
+
+func (h VMHook) namespaces() []string {
+	if h.Hook.Namespace != "" {
+		return []string{h.Hook.Namespace}
+	}
+
+	return h.RecipeElements.Parameters[core.ProtectedVMNamespace]
+}
+
+func (h VMHook) freezer() (VMFreezer, error) {
+	if h.Freezer != nil {
+		return h.Freezer, nil
+	}
+
+	restCfg, err := config.GetConfig()
+	if err != nil {
+		return nil, fmt.Errorf("error getting kubeconfig: %w", err)
+	}
+
+	restCfg = rest.CopyConfig(restCfg)
+	restCfg.GroupVersion = &schema.GroupVersion{Group: virtv1.SubresourceGroupName, Version: virtv1.ApiLatestVersion}
+	restCfg.APIPath = "/apis"
+	restCfg.NegotiatedSerializer = scheme.Codecs.WithoutConversion()
+
+	restClient, err := rest.RESTClientFor(restCfg)
+	if err != nil {
+		return nil, fmt.Errorf("error creating kubevirt subresources client: %w", err)
+	}
+
+	return restVMFreezer{restClient: restClient}, nil
+}
+
+// restVMFreezer freezes and unfreezes guests by way of the KubeVirt
+// VirtualMachineInstance freeze and unfreeze subresources.
+type restVMFreezer struct {
+	restClient rest.Interface
+}
+
+func (f restVMFreezer) Freeze(ctx context.Context, namespace, name string, unfreezeTimeout time.Duration) error {
+	body, err := json.Marshal(virtv1.FreezeUnfreezeTimeout{UnfreezeTimeout: &metav1.Duration{Duration: unfreezeTimeout}})
+	if err != nil {
+		return err
+	}
+
+	return f.restClient.Put().Namespace(namespace).Resource("virtualmachineinstances").Name(name).
+		SubResource(vmHookFreezeCommand).Body(body).Do(ctx).Error()
+}
+
+func (f restVMFreezer) Unfreeze(ctx context.Context, namespace, name string) error {
+	return f.restClient.Put().Namespace(namespace).Resource("virtualmachineinstances").Name(name).
+		SubResource(vmHookUnfreezeCommand).Do(ctx).Error()
+}
@@ -0,0 +1,158 @@
+package hooks_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/ramendr/ramen/internal/controller/core"
+	"github.com/ramendr/ramen/internal/controller/hooks"
+	"github.com/ramendr/ramen/internal/controller/kubeobjects"
+	"github.com/ramendr/ramen/internal/controller/util"
+	"github.com/stretchr/testify/assert"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	virtv1 "kubevirt.io/api/core/v1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/log/zap"
+)
+
+// fakeVMFreezer records the guests it freezes and unfreezes, and fails to
+// freeze those named to.
+type fakeVMFreezer struct {
+	frozen          []string
+	unfrozen        []string
+	unfreezeTimeout time.Duration
+	failFreeze      string
+}
+
+func (f *fakeVMFreezer) Freeze(_ context.Context, namespace, name string, unfreezeTimeout time.Duration) error {
+	if name == f.failFreeze {
+		return errors.New("guest agent not connected")
+	}
+
+	f.frozen = append(f.frozen, namespace+"/"+name)
+	f.unfreezeTimeout = unfreezeTimeout
+
+	return nil
+}
+
+func (f *fakeVMFreezer) Unfreeze(_ context.Context, namespace, name string) error {
+	f.unfrozen = append(f.unfrozen, namespace+"/"+name)
+
+	return nil
+}
+
+// setupForVMHook returns a client with a labeled VM in each of the given
+// namespaces, running unless named stopped, and an unlabeled running one.
+func setupForVMHook(t *testing.T, namespaces ...string) client.Client {
+	t.Helper()
+
+	objects := make([]client.Object, 0)
+
+	for _, namespace := range namespaces {
+		for _, name := range []string{"vm1", "stopped", "unlabeled"} {
+			labels := map[string]string{core.VMLabelSelector: "protected"}
+			if name == "unlabeled" {
+				labels = nil
+			}
+
+			objects = append(objects, &virtv1.VirtualMachine{
+				ObjectMeta: metav1.ObjectMeta{Name: name, Namespace: namespace, Labels: labels},
+			})
+
+			if name != "stopped" {
+				objects = append(objects, &virtv1.VirtualMachineInstance{
+					ObjectMeta: metav1.ObjectMeta{Name: name, Namespace: namespace},
+				})
+			}
+		}
+	}
+
+	return getHookTestClientBuilder(t, []func(*runtime.Scheme) error{virtv1.AddToScheme}, objects...).Build()
+}
+
+func getVMHookSpec(command string) *kubeobjects.HookSpec {
+	hookSpec := getHookTestSpec("vm", command, command)
+	hookSpec.Name = "vm-freeze"
+	hookSpec.Namespace = ""
+	hookSpec.LabelSelector = &metav1.LabelSelector{
+		MatchLabels: map[string]string{core.VMLabelSelector: "protected"},
+	}
+
+	return hookSpec
+}
+
+func TestVMHookFreezesRunningVMsInProtectedNamespaces(t *testing.T) {
+	c := setupForVMHook(t, "ns1", "ns2")
+	freezer := &fakeVMFreezer{}
+	recipeElements := util.RecipeElements{
+		Parameters: map[string][]string{core.ProtectedVMNamespace: {"ns1", "ns2"}},
+	}
+
+	result, err := hooks.VMHook{
+		Hook: getVMHookSpec("freeze 2m"), Reader: c, RecipeElements: recipeElements, Freezer: freezer,
+	}.ExecuteWithResult(zap.New())
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"ns1/vm1", "ns2/vm1"}, freezer.frozen)
+	assert.Equal(t, 2*time.Minute, freezer.unfreezeTimeout)
+	assert.Equal(t, []string{"ns1/vm1", "ns2/vm1"}, result.Targets)
+}
+
+func TestVMHookRefreezesFrozenVMsForTheirUnfreezeTimeout(t *testing.T) {
+	c := setupForVMHook(t, "ns1")
+	vmi := &virtv1.VirtualMachineInstance{}
+	assert.NoError(t, c.Get(context.Background(), client.ObjectKey{Namespace: "ns1", Name: "vm1"}, vmi))
+
+	vmi.Status.FSFreezeStatus = "frozen"
+	assert.NoError(t, c.Update(context.Background(), vmi))
+
+	freezer := &fakeVMFreezer{}
+	recipeElements := util.RecipeElements{Parameters: map[string][]string{core.ProtectedVMNamespace: {"ns1"}}}
+
+	err := hooks.VMHook{
+		Hook: getVMHookSpec("freeze 2m"), Reader: c, RecipeElements: recipeElements, Freezer: freezer,
+	}.Execute(zap.New())
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"ns1/vm1"}, freezer.unfrozen)
+	assert.Equal(t, []string{"ns1/vm1"}, freezer.frozen)
+	assert.Equal(t, 2*time.Minute, freezer.unfreezeTimeout)
+}
+
+func TestVMHookUnfreezesVMsInHookNamespace(t *testing.T) {
+	c := setupForVMHook(t, "ns1", "ns2")
+	freezer := &fakeVMFreezer{}
+
+	hookSpec := getVMHookSpec("unfreeze")
+	hookSpec.Namespace = "ns2"
+	hookSpec.LabelSelector = nil
+	hookSpec.NameSelector = "vm1|unlabeled"
+
+	err := hooks.VMHook{Hook: hookSpec, Reader: c, Freezer: freezer}.Execute(zap.New())
+	assert.NoError(t, err)
+	assert.ElementsMatch(t, []string{"ns2/vm1", "ns2/unlabeled"}, freezer.unfrozen)
+}
+
+func TestVMHookUnfreezesFrozenVMsWhenFreezeFails(t *testing.T) {
+	c := setupForVMHook(t, "ns1")
+	freezer := &fakeVMFreezer{failFreeze: "unlabeled"}
+
+	hookSpec := getVMHookSpec("freeze")
+	hookSpec.Namespace = "ns1"
+	hookSpec.LabelSelector = nil
+	hookSpec.NameSelector = ".*"
+
+	err := hooks.VMHook{Hook: hookSpec, Reader: c, Freezer: freezer}.Execute(zap.New())
+	assert.ErrorContains(t, err, "guest agent not connected")
+	assert.Equal(t, freezer.frozen, freezer.unfrozen)
+	assert.NotEmpty(t, freezer.frozen)
+
+	freezer = &fakeVMFreezer{failFreeze: "unlabeled"}
+	hookSpec.OnError = "continue"
+
+	result, err := hooks.VMHook{Hook: hookSpec, Reader: c, Freezer: freezer}.ExecuteWithResult(zap.New())
+	assert.NoError(t, err)
+	assert.Error(t, result.Error)
+	assert.Empty(t, freezer.unfrozen)
+}
@@ -0,0 +1,70 @@
+// SPDX-FileCopyrightText: The RamenDR authors
+// SPDX-License-Identifier: Apache-2.0
+
+package kubeobjects
+
+import (
+	"slices"
+)
+
+// Inventory maps the key of each object of a capture, its resource, namespace
+// and name, to a digest of its content, or to "" if its content is unknown, as
+// it is for Velero's captures.
+type Inventory map[string]string
+
+func InventoryKey(groupResource, namespaceName, name string) string {
+	return groupResource + "/" + namespaceName + "/" + name
+}
+
+// InventoryDiff lists the keys of the objects added, removed and modified
+// between two inventories, each sorted.  The objects modified are not listed if
+// the content of any object of either inventory is unknown.
+type InventoryDiff struct {
+	Added    []string `json:"added"`
+	Removed  []string `json:"removed"`
+	Modified []string `json:"modified,omitempty"`
+}
+
+func InventoryDiffGet(previous, current Inventory) InventoryDiff {
+	diff := InventoryDiff{Added: []string{}, Removed: []string{}}
+	if !previous.contentUnknown() && !current.contentUnknown() {
+		diff.Modified = []string{}
+	}
+
+	for key, digest := range current {
+		previousDigest, ok := previous[key]
+
+		switch {
+		case !ok:
+			diff.Added = append(diff.Added, key)
+		case diff.Modified != nil && digest != previousDigest:
+			diff.Modified = append(diff.Modified, key)
+		}
+	}
+
+	for key := range previous {
+		if _, ok := current[key]; !ok {
+			diff.Removed = append(diff.Removed, key)
+		}
+	}
+
+	slices.Sort(diff.Added)
+	slices.Sort(diff.Removed)
+	slices.Sort(diff.Modified)
+
+	return diff
+}
+
+func (i Inventory) contentUnknown() bool {
+	for _, digest := range i {
+		if digest == "" {
+			return true
+		}
+	}
+
+	return false
+}
+
+func (d InventoryDiff) Empty() bool {
+	return len(d.Added) == 0 && len(d.Removed) == 0 && len(d.Modified) == 0
+}
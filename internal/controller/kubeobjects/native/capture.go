@@ -0,0 +1,304 @@
+// SPDX-FileCopyrightText: The RamenDR authors
+// SPDX-License-Identifier: Apache-2.0
+
+package native
+
+import (
+	"context"
+	"fmt"
+	"slices"
+	"strings"
+
+	"github.com/go-logr/logr"
+	"github.com/ramendr/ramen/internal/controller/kubeobjects"
+	"github.com/ramendr/ramen/internal/controller/util"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/client-go/discovery"
+)
+
+// excludedResourcesDefault are never captured.  As with Velero, volume
+// replication resources, PVCs and PVs are excluded so that the VRG can create
+// them.  Events and endpoints are recreated by the cluster.
+var excludedResourcesDefault = []string{
+	"volumereplications.replication.storage.openshift.io",
+	"volumegroupreplications.replication.storage.openshift.io",
+	"replicationsources.volsync.backube",
+	"replicationdestinations.volsync.backube",
+	"persistentvolumeclaims",
+	"persistentvolumes",
+	"events",
+	"events.events.k8s.io",
+	"endpoints",
+	"endpointslices.discovery.k8s.io",
+}
+
+// resource identifies the resource of captured objects, and the names it may be
+// selected by.
+type resource struct {
+	Group        string   `json:"group,omitempty"`
+	Version      string   `json:"version"`
+	Name         string   `json:"name"`
+	SingularName string   `json:"singularName,omitempty"`
+	Kind         string   `json:"kind"`
+	ShortNames   []string `json:"shortNames,omitempty"`
+	Namespaced   bool     `json:"namespaced"`
+}
+
+func (r resource) groupVersionResource() schema.GroupVersionResource {
+	return schema.GroupVersionResource{Group: r.Group, Version: r.Version, Resource: r.Name}
+}
+
+// names returns the names the resource may be selected by: its plural,
+// singular, kind and short names, each alone and qualified by its group.
+func (r resource) names() []string {
+	names := append([]string{r.Name, r.SingularName, strings.ToLower(r.Kind)}, r.ShortNames...)
+	qualifiedNames := make([]string, 0, 2*len(names))
+
+	for _, name := range names {
+		if name == "" {
+			continue
+		}
+
+		qualifiedNames = append(qualifiedNames, name)
+		if r.Group != "" {
+			qualifiedNames = append(qualifiedNames, name+"."+r.Group)
+		}
+	}
+
+	return qualifiedNames
+}
+
+// matches returns true if any of the given names, or "*", selects the
+// resource.  Names are compared case-insensitively.
+func (r resource) matches(names []string) bool {
+	resourceNames := r.names()
+
+	for _, name := range names {
+		name = strings.ToLower(name)
+		if name == "*" || slices.Contains(resourceNames, name) {
+			return true
+		}
+	}
+
+	return false
+}
+
+// selected returns true if the resource is selected by the given included and
+// excluded resource names.  All resources are included if none are.
+func (r resource) selected(included, excluded []string) bool {
+	if r.matches(excluded) {
+		return false
+	}
+
+	return len(included) == 0 || r.matches(included)
+}
+
+type resourceObjects struct {
+	Resource resource                 `json:"resource"`
+	Objects  []map[string]interface{} `json:"objects"`
+}
+
+// capture is the content of the object a capture request stores.
+type capture struct {
+	Resources []resourceObjects `json:"resources"`
+	// Secrets excluded or redacted, if there are secret policies
+	Secrets *kubeobjects.SecretsReport `json:"secrets,omitempty"`
+}
+
+func (c *capture) objectCount() int {
+	count := 0
+	for _, resourceObjects := range c.Resources {
+		count += len(resourceObjects.Objects)
+	}
+
+	return count
+}
+
+// capture lists the objects selected by the given spec.  Objects managed by a
+// controller are skipped, as their controller recreates them.  Secrets are
+// excluded or redacted by the spec's secret policies.
+func (m RequestsManager) capture(
+	ctx context.Context, spec kubeobjects.Spec, log logr.Logger,
+) (*capture, error) {
+	resources, err := m.resources()
+	if err != nil {
+		return nil, err
+	}
+
+	selector, err := objectSelectorNew(spec)
+	if err != nil {
+		return nil, err
+	}
+
+	secretPolicies, err := secretPoliciesNew(spec.SecretPolicies)
+	if err != nil {
+		return nil, err
+	}
+
+	capture := &capture{Resources: []resourceObjects{}}
+	if len(secretPolicies) > 0 {
+		capture.Secrets = &kubeobjects.SecretsReport{Excluded: []string{}, Redacted: []string{}}
+	}
+
+	excluded := append(slices.Clone(spec.ExcludedResources), excludedResourcesDefault...)
+	includeClusterResources := spec.IncludeClusterResources != nil && *spec.IncludeClusterResources
+
+	for _, resource := range resources {
+		if !resource.selected(spec.IncludedResources, excluded) ||
+			(!resource.Namespaced && !includeClusterResources) {
+			continue
+		}
+
+		objects, err := m.objectsList(ctx, resource, spec.IncludedNamespaces, selector)
+		if err != nil {
+			return nil, err
+		}
+
+		if resource.isSecrets() {
+			objects = secretsPoliciesApply(secretPolicies, objects, capture.Secrets)
+		}
+
+		if len(objects) == 0 {
+			continue
+		}
+
+		log.Info("Kube objects captured", "resource", resource.groupVersionResource(), "count", len(objects))
+		capture.Resources = append(capture.Resources, resourceObjects{Resource: resource, Objects: objects})
+	}
+
+	return capture, nil
+}
+
+// resources returns the preferred version of each resource served that can be
+// listed and created.
+func (m RequestsManager) resources() ([]resource, error) {
+	resourceLists, err := discovery.ServerPreferredResources(m.discovery)
+	if err != nil {
+		// Resources of groups that are discovered are still returned
+		if !discovery.IsGroupDiscoveryFailedError(err) {
+			return nil, fmt.Errorf("resources discover: %w", err)
+		}
+	}
+
+	resourceLists = discovery.FilteredBy(
+		discovery.SupportsAllVerbs{Verbs: []string{"list", "create"}},
+		resourceLists,
+	)
+	resources := []resource{}
+
+	for _, resourceList := range resourceLists {
+		groupVersion, err := schema.ParseGroupVersion(resourceList.GroupVersion)
+		if err != nil {
+			return nil, fmt.Errorf("resources discover: %w", err)
+		}
+
+		for _, apiResource := range resourceList.APIResources {
+			// Subresources are captured with their resource
+			if strings.Contains(apiResource.Name, "/") {
+				continue
+			}
+
+			resources = append(resources, resource{
+				Group:        groupVersion.Group,
+				Version:      groupVersion.Version,
+				Name:         apiResource.Name,
+				SingularName: apiResource.SingularName,
+				Kind:         apiResource.Kind,
+				ShortNames:   apiResource.ShortNames,
+				Namespaced:   apiResource.Namespaced,
+			})
+		}
+	}
+
+	return resources, nil
+}
+
+func (m RequestsManager) objectsList(
+	ctx context.Context, resource resource, namespaceNames []string, selector objectSelector,
+) ([]map[string]interface{}, error) {
+	if !resource.Namespaced || len(namespaceNames) == 0 || slices.Contains(namespaceNames, "*") {
+		namespaceNames = []string{metav1.NamespaceAll}
+	}
+
+	objects := []map[string]interface{}{}
+
+	for _, namespaceName := range namespaceNames {
+		list, err := m.client.Resource(resource.groupVersionResource()).Namespace(namespaceName).
+			List(ctx, selector.listOptions())
+		if err != nil {
+			return nil, fmt.Errorf("%s list in namespace %q: %w",
+				resource.groupVersionResource(), namespaceName, err)
+		}
+
+		for i := range list.Items {
+			object := &list.Items[i]
+			if metav1.GetControllerOf(object) != nil || !selector.matches(object) {
+				continue
+			}
+
+			objects = append(objects, object.UnstructuredContent())
+		}
+	}
+
+	return objects, nil
+}
+
+// objectSelector selects objects by labels.  As with Velero, an object is
+// selected by any of the OR label selectors, if there are any, and otherwise
+// by the label selector.  Objects created by ramen are never selected.
+type objectSelector struct {
+	selectors []labels.Selector
+}
+
+func objectSelectorNew(spec kubeobjects.Spec) (objectSelector, error) {
+	labelSelectors := spec.OrLabelSelectors
+	if len(labelSelectors) == 0 {
+		labelSelectors = []*metav1.LabelSelector{spec.LabelSelector}
+	}
+
+	selectors := make([]labels.Selector, 0, len(labelSelectors))
+
+	for _, labelSelector := range labelSelectors {
+		// A nil label selector selects everything, as it does for Velero
+		if labelSelector == nil {
+			labelSelector = &metav1.LabelSelector{}
+		}
+
+		selector, err := metav1.LabelSelectorAsSelector(labelSelector)
+		if err != nil {
+			return objectSelector{}, fmt.Errorf("label selector %v: %w", labelSelector, err)
+		}
+
+		selectors = append(selectors, selector)
+	}
+
+	return objectSelector{selectors: selectors}, nil
+}
+
+// listOptions returns the options of a list that selects objects by the label
+// selector, if there is only one, as a list cannot select by OR label
+// selectors, which are matched by the lister.
+func (s objectSelector) listOptions() metav1.ListOptions {
+	if len(s.selectors) != 1 {
+		return metav1.ListOptions{}
+	}
+
+	return metav1.ListOptions{LabelSelector: s.selectors[0].String()}
+}
+
+func (s objectSelector) matches(object metav1.Object) bool {
+	objectLabels := labels.Set(object.GetLabels())
+	if objectLabels[util.CreatedByRamenLabel] == "true" {
+		return false
+	}
+
+	for _, selector := range s.selectors {
+		if selector.Matches(objectLabels) {
+			return true
+		}
+	}
+
+	return false
+}
@@ -0,0 +1,63 @@
+// SPDX-FileCopyrightText: The RamenDR authors
+// SPDX-License-Identifier: Apache-2.0
+
+package native
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+
+	"github.com/ramendr/ramen/internal/controller/kubeobjects"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+)
+
+// ProtectRequestInventoryGet returns the inventory of the objects the named
+// capture stored with the given key prefix.  An object's digest covers its
+// content that would be recovered, so that its status and the metadata the
+// cluster maintains do not modify it.
+func (RequestsManager) ProtectRequestInventoryGet(
+	objectDownloader kubeobjects.ObjectDownloader, s3KeyPrefix, captureName string,
+) (kubeobjects.Inventory, error) {
+	capture := &capture{}
+	if err := objectDownloader.DownloadObject(captureObjectKey(s3KeyPrefix, captureName), capture); err != nil {
+		return nil, fmt.Errorf("capture %s download: %w", captureName, err)
+	}
+
+	return capture.inventory()
+}
+
+func (c *capture) inventory() (kubeobjects.Inventory, error) {
+	inventory := kubeobjects.Inventory{}
+
+	for _, resourceObjects := range c.Resources {
+		resource := resourceObjects.Resource
+		groupResource := resource.groupVersionResource().GroupResource().String()
+
+		for _, content := range resourceObjects.Objects {
+			object := &unstructured.Unstructured{Object: content}
+
+			digest, err := objectDigest(resource, object)
+			if err != nil {
+				return nil, err
+			}
+
+			inventory[kubeobjects.InventoryKey(groupResource, object.GetNamespace(), object.GetName())] = digest
+		}
+	}
+
+	return inventory, nil
+}
+
+func objectDigest(resource resource, object *unstructured.Unstructured) (string, error) {
+	content, err := json.Marshal(objectForRecover(resource, object, func(name string) string { return name }))
+	if err != nil {
+		return "", fmt.Errorf("%s %s/%s encode: %w", resource.groupVersionResource(),
+			object.GetNamespace(), object.GetName(), err)
+	}
+
+	digest := sha256.Sum256(content)
+
+	return hex.EncodeToString(digest[:]), nil
+}
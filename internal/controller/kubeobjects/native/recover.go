@@ -0,0 +1,333 @@
+// SPDX-FileCopyrightText: The RamenDR authors
+// SPDX-License-Identifier: Apache-2.0
+
+package native
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"slices"
+
+	"github.com/go-logr/logr"
+	"github.com/ramendr/ramen/internal/controller/kubeobjects"
+	velero "github.com/vmware-tanzu/velero/pkg/apis/velero/v1"
+	k8serrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+)
+
+// recoverPriorities are the resources recovered first, in order, so that
+// objects that others depend on exist before them.  Other resources are
+// recovered in the order they were captured.
+var recoverPriorities = []string{
+	"customresourcedefinitions.apiextensions.k8s.io",
+	"namespaces",
+	"storageclasses.storage.k8s.io",
+	"serviceaccounts",
+	"secrets",
+	"configmaps",
+	"limitranges",
+	"resourcequotas",
+	"clusterroles.rbac.authorization.k8s.io",
+	"clusterrolebindings.rbac.authorization.k8s.io",
+	"roles.rbac.authorization.k8s.io",
+	"rolebindings.rbac.authorization.k8s.io",
+}
+
+func recoverPriority(resource resource) int {
+	for i, name := range recoverPriorities {
+		if resource.matches([]string{name}) {
+			return i
+		}
+	}
+
+	return len(recoverPriorities)
+}
+
+// metadataKeysRecovered are the metadata keys of a captured object that are
+// recovered.  Others, such as its uid and owner references, are specific to
+// the cluster it was captured from.
+var metadataKeysRecovered = []string{"name", "namespace", "labels", "annotations", "finalizers"}
+
+var namespaceGroupVersionResource = schema.GroupVersionResource{Version: "v1", Resource: "namespaces"}
+
+// recover creates the captured objects selected by the given spec, in the
+// namespaces they are mapped to and patched by the spec's transforms, and
+// creates those namespaces if they do not exist.  An object that exists is
+// updated if the existing resource policy is update, and is otherwise left as
+// is.  Status is recovered only for the resources selected by the restore
+// status spec.  The number of objects recovered is returned, along with the
+// errors of those that were not.  If a verification is given, objects are
+// recovered with a server-side dry run, and their errors are counted in it
+// instead, as are objects that cannot be verified as their namespace does not
+// exist.
+func (m RequestsManager) recover(
+	ctx context.Context, capture *capture, spec kubeobjects.RecoverSpec,
+	verification *kubeobjects.RecoverVerification, log logr.Logger,
+) (int, error) {
+	selector, err := objectSelectorNew(spec.Spec)
+	if err != nil {
+		return 0, err
+	}
+
+	transforms, err := transformsNew(spec.Transforms)
+	if err != nil {
+		return 0, err
+	}
+
+	resources := slices.Clone(capture.Resources)
+	slices.SortStableFunc(resources, func(a, b resourceObjects) int {
+		return recoverPriority(a.Resource) - recoverPriority(b.Resource)
+	})
+
+	r := recoverer{
+		RequestsManager: m,
+		ctx:             ctx,
+		spec:            spec,
+		transforms:      transforms,
+		dryRun:          verification != nil,
+		namespaces:      map[string]bool{},
+		log:             log,
+	}
+	includeClusterResources := spec.IncludeClusterResources != nil && *spec.IncludeClusterResources
+	count := 0
+	errs := []error{}
+
+	for _, resourceObjects := range resources {
+		resource := resourceObjects.Resource
+		if !resource.selected(spec.IncludedResources, spec.ExcludedResources) ||
+			(!resource.Namespaced && !includeClusterResources) {
+			continue
+		}
+
+		for _, content := range resourceObjects.Objects {
+			object := &unstructured.Unstructured{Object: content}
+			if !r.namespaceSelected(object.GetNamespace()) || !selector.matches(object) {
+				continue
+			}
+
+			if err := r.objectRecover(resource, object); err != nil {
+				if verification != nil && errors.Is(err, errObjectUnverifiable) {
+					verification.Unverifiable++
+
+					continue
+				}
+
+				if verification != nil {
+					verification.FailureAdd(resource.groupVersionResource().GroupResource().String(), err.Error())
+
+					continue
+				}
+
+				errs = append(errs, err)
+
+				continue
+			}
+
+			count++
+		}
+	}
+
+	return count, errors.Join(errs...)
+}
+
+// errObjectUnverifiable is returned by a dry run recover of an object whose
+// namespace does not exist
+var errObjectUnverifiable = errors.New("object unverifiable as its namespace does not exist")
+
+type recoverer struct {
+	RequestsManager
+	ctx        context.Context
+	spec       kubeobjects.RecoverSpec
+	transforms []transform
+	dryRun     bool
+	// namespaces that are known to exist, or, in a dry run, to not
+	namespaces map[string]bool
+	log        logr.Logger
+}
+
+func (r recoverer) namespaceSelected(namespaceName string) bool {
+	namespaceNames := r.spec.IncludedNamespaces
+
+	return namespaceName == "" || len(namespaceNames) == 0 ||
+		slices.Contains(namespaceNames, "*") || slices.Contains(namespaceNames, namespaceName)
+}
+
+func (r recoverer) namespaceMap(namespaceName string) string {
+	if mappedNamespaceName, ok := r.spec.NamespaceMapping[namespaceName]; ok {
+		return mappedNamespaceName
+	}
+
+	return namespaceName
+}
+
+func (r recoverer) objectRecover(resource resource, captured *unstructured.Unstructured) error {
+	object, err := transformsApply(r.transforms, resource, objectForRecover(resource, captured, r.namespaceMap))
+	if err != nil {
+		return fmt.Errorf("%s %s/%s transform: %w", resource.groupVersionResource(),
+			captured.GetNamespace(), captured.GetName(), err)
+	}
+
+	log := r.log.WithValues("resource", resource.groupVersionResource(),
+		"name", object.GetNamespace()+"/"+object.GetName())
+
+	if resource.Namespaced {
+		if err := r.namespaceCreate(object.GetNamespace()); err != nil {
+			return err
+		}
+	}
+
+	if resource.isSecrets() {
+		exists, err := r.secretDataRecover(object)
+		if err != nil {
+			return err
+		}
+
+		if exists {
+			log.Info("Kube object redacted, and exists")
+
+			return nil
+		}
+	}
+
+	client := r.client.Resource(resource.groupVersionResource()).Namespace(object.GetNamespace())
+
+	recovered, err := client.Create(r.ctx, object, metav1.CreateOptions{DryRun: r.dryRunOption()})
+	if err != nil {
+		// Objects are not created in a namespace whose creation was a dry run
+		if r.dryRun && k8serrors.IsNotFound(err) && resource.Namespaced && !r.namespaces[object.GetNamespace()] {
+			return errObjectUnverifiable
+		}
+
+		if !k8serrors.IsAlreadyExists(err) {
+			return fmt.Errorf("%s %s/%s create: %w", resource.groupVersionResource(),
+				object.GetNamespace(), object.GetName(), err)
+		}
+
+		if r.spec.ExistingResourcePolicy != velero.PolicyTypeUpdate {
+			log.Info("Kube object exists; not updated")
+
+			return nil
+		}
+
+		if recovered, err = r.objectUpdate(resource, object); err != nil {
+			return err
+		}
+
+		log.Info("Kube object updated")
+	} else {
+		log.Info("Kube object created")
+	}
+
+	if r.dryRun {
+		return nil
+	}
+
+	return r.statusRecover(resource, captured, recovered)
+}
+
+func (r recoverer) objectUpdate(
+	resource resource, object *unstructured.Unstructured,
+) (*unstructured.Unstructured, error) {
+	client := r.client.Resource(resource.groupVersionResource()).Namespace(object.GetNamespace())
+
+	existing, err := client.Get(r.ctx, object.GetName(), metav1.GetOptions{})
+	if err != nil {
+		return nil, fmt.Errorf("%s %s/%s get: %w", resource.groupVersionResource(),
+			object.GetNamespace(), object.GetName(), err)
+	}
+
+	object.SetResourceVersion(existing.GetResourceVersion())
+
+	updated, err := client.Update(r.ctx, object, metav1.UpdateOptions{DryRun: r.dryRunOption()})
+	if err != nil {
+		return nil, fmt.Errorf("%s %s/%s update: %w", resource.groupVersionResource(),
+			object.GetNamespace(), object.GetName(), err)
+	}
+
+	return updated, nil
+}
+
+func (r recoverer) statusRecover(resource resource, captured, recovered *unstructured.Unstructured) error {
+	status, ok := captured.Object["status"]
+	if !ok || r.spec.RestoreStatus == nil ||
+		!resource.selected(r.spec.RestoreStatus.IncludedResources, r.spec.RestoreStatus.ExcludedResources) {
+		return nil
+	}
+
+	recovered.Object["status"] = status
+
+	if _, err := r.client.Resource(resource.groupVersionResource()).Namespace(recovered.GetNamespace()).
+		UpdateStatus(r.ctx, recovered, metav1.UpdateOptions{}); err != nil {
+		return fmt.Errorf("%s %s/%s status update: %w", resource.groupVersionResource(),
+			recovered.GetNamespace(), recovered.GetName(), err)
+	}
+
+	return nil
+}
+
+func (r recoverer) namespaceCreate(namespaceName string) error {
+	if _, ok := r.namespaces[namespaceName]; ok {
+		return nil
+	}
+
+	namespace := &unstructured.Unstructured{}
+	namespace.SetAPIVersion("v1")
+	namespace.SetKind("Namespace")
+	namespace.SetName(namespaceName)
+
+	_, err := r.client.Resource(namespaceGroupVersionResource).Create(r.ctx, namespace,
+		metav1.CreateOptions{DryRun: r.dryRunOption()})
+	if err != nil && !k8serrors.IsAlreadyExists(err) {
+		return fmt.Errorf("namespace %s create: %w", namespaceName, err)
+	}
+
+	r.namespaces[namespaceName] = err != nil || !r.dryRun
+
+	return nil
+}
+
+func (r recoverer) dryRunOption() []string {
+	if r.dryRun {
+		return []string{metav1.DryRunAll}
+	}
+
+	return nil
+}
+
+// objectForRecover returns a copy of the given captured object without its
+// status and the metadata specific to the cluster it was captured from, in
+// the namespace it is mapped to.
+func objectForRecover(
+	resource resource, captured *unstructured.Unstructured, namespaceMap func(string) string,
+) *unstructured.Unstructured {
+	object := captured.DeepCopy()
+	delete(object.Object, "status")
+
+	if metadata, ok := object.Object["metadata"].(map[string]interface{}); ok {
+		for key := range metadata {
+			if !slices.Contains(metadataKeysRecovered, key) {
+				delete(metadata, key)
+			}
+		}
+	}
+
+	switch {
+	case resource.Namespaced:
+		object.SetNamespace(namespaceMap(object.GetNamespace()))
+	case resource.groupVersionResource().GroupResource() == namespaceGroupVersionResource.GroupResource():
+		object.SetName(namespaceMap(object.GetName()))
+	}
+
+	// Cluster IPs are allocated by the cluster a service is created in
+	if resource.Group == "" && resource.Name == "services" {
+		if clusterIP, _, _ := unstructured.NestedString(object.Object, "spec", "clusterIP"); clusterIP != "None" {
+			unstructured.RemoveNestedField(object.Object, "spec", "clusterIP")
+			unstructured.RemoveNestedField(object.Object, "spec", "clusterIPs")
+		}
+	}
+
+	return object
+}
@@ -0,0 +1,368 @@
+// SPDX-FileCopyrightText: The RamenDR authors
+// SPDX-License-Identifier: Apache-2.0
+
+// Objects of any resource may be captured and recovered, with the permissions
+// of config/dr-cluster/rbac-native, which are granted only if the native engine
+// is selected, and are therefore not declared here
+// +kubebuilder:rbac:groups="",resources=configmaps,verbs=create;delete;deletecollection;get;list;watch
+
+// Package native captures and recovers kube objects without Velero.  Objects
+// are listed with the dynamic client, stored as a single object per capture
+// request in the S3 profile, and created from there by recover requests.
+// Requests are processed synchronously as they are created, and are recorded
+// as config maps so that they can be queried and deleted like Velero's.
+package native
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"time"
+
+	"github.com/go-logr/logr"
+	"github.com/ramendr/ramen/internal/controller/kubeobjects"
+	corev1 "k8s.io/api/core/v1"
+	k8serrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/discovery"
+	"k8s.io/client-go/dynamic"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+const (
+	path         = "native/"
+	protectsPath = path + "captures/"
+	recoversPath = path + "recovers/"
+
+	// captureObjectName is the name of the object, under a capture request's
+	// path, that contains its kube objects
+	captureObjectName = "objects"
+
+	requestTypeLabel   = "ramendr.openshift.io/kube-objects-request"
+	requestTypeProtect = "protect"
+	requestTypeRecover = "recover"
+
+	startTimeKey   = "startTime"
+	endTimeKey     = "endTime"
+	objectCountKey = "objectCount"
+)
+
+// ObjectStorer is the subset of an S3 profile's object store used to store
+// and retrieve captures.
+type ObjectStorer interface {
+	UploadObject(key string, object interface{}) error
+	DownloadObject(key string, objectPointer interface{}) error
+}
+
+// ObjectStorerGetter returns the object store of the named S3 profile.
+type ObjectStorerGetter func(ctx context.Context, s3ProfileName string) (ObjectStorer, error)
+
+type request struct{ configMap *corev1.ConfigMap }
+
+type (
+	ProtectRequest struct{ request }
+	RecoverRequest struct{ request }
+)
+
+func (r request) Object() client.Object  { return r.configMap }
+func (r request) Name() string           { return r.configMap.Name }
+func (r request) StartTime() metav1.Time { return r.time(startTimeKey) }
+func (r request) EndTime() metav1.Time   { return r.time(endTimeKey) }
+
+func (r request) Deallocate(ctx context.Context, writer client.Writer, log logr.Logger) error {
+	return objectWriter{ctx: ctx, Writer: writer, log: log}.objectDelete(r.configMap)
+}
+
+func (r request) time(key string) metav1.Time {
+	t, err := time.Parse(time.RFC3339, r.configMap.Data[key])
+	if err != nil {
+		return metav1.Time{}
+	}
+
+	return metav1.NewTime(t)
+}
+
+// Status returns nil, as a request is complete once it is recorded.
+func (r request) Status(log logr.Logger) error {
+	log.Info("Kube objects request",
+		"type", r.configMap.Labels[requestTypeLabel],
+		"name", r.configMap.Name,
+		"objects", r.configMap.Data[objectCountKey],
+		"start", r.configMap.Data[startTimeKey],
+		"finish", r.configMap.Data[endTimeKey],
+	)
+
+	return nil
+}
+
+type Requests struct{ configMaps *corev1.ConfigMapList }
+
+func (r Requests) Count() int { return len(r.configMaps.Items) }
+
+func (r Requests) Get(i int) kubeobjects.Request {
+	return request{&r.configMaps.Items[i]}
+}
+
+type RequestsManager struct {
+	client          dynamic.Interface
+	discovery       discovery.DiscoveryInterface
+	objectStorerGet ObjectStorerGetter
+}
+
+func RequestsManagerNew(
+	client dynamic.Interface, discovery discovery.DiscoveryInterface, objectStorerGet ObjectStorerGetter,
+) RequestsManager {
+	return RequestsManager{client: client, discovery: discovery, objectStorerGet: objectStorerGet}
+}
+
+func (RequestsManager) ProtectsPath() string { return protectsPath }
+func (RequestsManager) RecoversPath() string { return recoversPath }
+
+func (RequestsManager) ProtectRequestNew() kubeobjects.ProtectRequest {
+	return ProtectRequest{request{configMap()}}
+}
+
+func (RequestsManager) RecoverRequestNew() kubeobjects.RecoverRequest {
+	return RecoverRequest{request{configMap()}}
+}
+
+func (RequestsManager) ProtectRequestsGet(
+	ctx context.Context, reader client.Reader, requestNamespaceName string, labels map[string]string,
+) (kubeobjects.Requests, error) {
+	return requestsGet(ctx, reader, requestNamespaceName, labels, requestTypeProtect)
+}
+
+func (RequestsManager) RecoverRequestsGet(
+	ctx context.Context, reader client.Reader, requestNamespaceName string, labels map[string]string,
+) (kubeobjects.Requests, error) {
+	return requestsGet(ctx, reader, requestNamespaceName, labels, requestTypeRecover)
+}
+
+func requestsGet(
+	ctx context.Context, reader client.Reader, requestNamespaceName string, labels map[string]string,
+	requestType string,
+) (kubeobjects.Requests, error) {
+	requests := Requests{&corev1.ConfigMapList{}}
+
+	return requests, reader.List(ctx, requests.configMaps,
+		client.InNamespace(requestNamespaceName),
+		client.MatchingLabels(requestLabels(labels, requestType)),
+	)
+}
+
+func (RequestsManager) ProtectRequestsDelete(
+	ctx context.Context, writer client.Writer, requestNamespaceName string, labels map[string]string,
+) error {
+	return requestsDelete(ctx, writer, requestNamespaceName, labels, requestTypeProtect)
+}
+
+func (RequestsManager) RecoverRequestsDelete(
+	ctx context.Context, writer client.Writer, requestNamespaceName string, labels map[string]string,
+) error {
+	if err := requestsDelete(ctx, writer, requestNamespaceName, labels, requestTypeRecover); err != nil {
+		return err
+	}
+
+	return requestsDelete(ctx, writer, requestNamespaceName, labels, requestTypeProtect)
+}
+
+func requestsDelete(
+	ctx context.Context, writer client.Writer, requestNamespaceName string, labels map[string]string,
+	requestType string,
+) error {
+	if err := writer.DeleteAllOf(ctx, &corev1.ConfigMap{},
+		client.InNamespace(requestNamespaceName),
+		client.MatchingLabels(requestLabels(labels, requestType)),
+	); err != nil {
+		return fmt.Errorf("%s requests delete: %w", requestType, err)
+	}
+
+	return nil
+}
+
+func (m RequestsManager) ProtectRequestCreate(
+	ctx context.Context,
+	writer client.Writer,
+	log logr.Logger,
+	s3ProfileName string,
+	s3Url string,
+	s3BucketName string,
+	s3RegionName string,
+	s3KeyPrefix string,
+	secretKeyRef *corev1.SecretKeySelector,
+	caCertificates []byte,
+	objectsSpec kubeobjects.Spec,
+	requestNamespaceName string,
+	captureName string,
+	labels map[string]string,
+	annotations map[string]string,
+) (kubeobjects.ProtectRequest, error) {
+	log.Info("Kube objects protect",
+		"s3 profile", s3ProfileName,
+		"s3 url", s3Url,
+		"s3 bucket", s3BucketName,
+		"s3 key prefix", s3KeyPrefix,
+		"source namespaces", objectsSpec.IncludedNamespaces,
+		"request namespace", requestNamespaceName,
+		"capture name", captureName,
+		"label set", labels,
+		"annotations", annotations,
+	)
+
+	startTime := time.Now()
+
+	objectStorer, err := m.objectStorerGet(ctx, s3ProfileName)
+	if err != nil {
+		return nil, fmt.Errorf("capture %s object store get: %w", captureName, err)
+	}
+
+	capture, err := m.capture(ctx, objectsSpec, log)
+	if err != nil {
+		return nil, fmt.Errorf("capture %s: %w", captureName, err)
+	}
+
+	if err := objectStorer.UploadObject(captureObjectKey(s3KeyPrefix, captureName), capture); err != nil {
+		return nil, fmt.Errorf("capture %s upload: %w", captureName, err)
+	}
+
+	configMap, err := requestRecord(objectWriter{ctx: ctx, Writer: writer, log: log},
+		requestNamespaceName, captureName, requestTypeProtect, labels, annotations,
+		startTime, capture.objectCount(),
+	)
+
+	return ProtectRequest{request{configMap}}, err
+}
+
+func (m RequestsManager) RecoverRequestCreate(
+	ctx context.Context,
+	writer client.Writer,
+	log logr.Logger,
+	s3ProfileName string,
+	s3Url string,
+	s3BucketName string,
+	s3RegionName string,
+	s3KeyPrefix string,
+	secretKeyRef *corev1.SecretKeySelector,
+	caCertificates []byte,
+	recoverSpec kubeobjects.RecoverSpec,
+	requestNamespaceName string,
+	captureName string,
+	captureRequest kubeobjects.ProtectRequest,
+	recoverName string,
+	labels map[string]string,
+	annotations map[string]string,
+) (kubeobjects.RecoverRequest, error) {
+	log.Info("Kube objects recover",
+		"s3 profile", s3ProfileName,
+		"s3 url", s3Url,
+		"s3 bucket", s3BucketName,
+		"s3 key prefix", s3KeyPrefix,
+		"request namespace", requestNamespaceName,
+		"capture name", captureName,
+		"recover name", recoverName,
+		"label set", labels,
+		"annotations", annotations,
+	)
+
+	startTime := time.Now()
+
+	objectStorer, err := m.objectStorerGet(ctx, s3ProfileName)
+	if err != nil {
+		return nil, fmt.Errorf("recover %s object store get: %w", recoverName, err)
+	}
+
+	capture := &capture{}
+	if err := objectStorer.DownloadObject(captureObjectKey(s3KeyPrefix, captureName), capture); err != nil {
+		return nil, fmt.Errorf("recover %s capture %s download: %w", recoverName, captureName, err)
+	}
+
+	count, err := m.recover(ctx, capture, recoverSpec, nil, log)
+	if err != nil {
+		return nil, fmt.Errorf("recover %s: %w", recoverName, err)
+	}
+
+	configMap, err := requestRecord(objectWriter{ctx: ctx, Writer: writer, log: log},
+		requestNamespaceName, recoverName, requestTypeRecover, labels, annotations,
+		startTime, count,
+	)
+
+	return RecoverRequest{request{configMap}}, err
+}
+
+func captureObjectKey(s3KeyPrefix, captureName string) string {
+	return s3KeyPrefix + protectsPath + captureName + "/" + captureObjectName
+}
+
+func requestLabels(labels map[string]string, requestType string) map[string]string {
+	requestLabels := make(map[string]string, len(labels)+1)
+	for key, value := range labels {
+		requestLabels[key] = value
+	}
+
+	requestLabels[requestTypeLabel] = requestType
+
+	return requestLabels
+}
+
+func configMap() *corev1.ConfigMap {
+	return &corev1.ConfigMap{TypeMeta: metav1.TypeMeta{APIVersion: "v1", Kind: "ConfigMap"}}
+}
+
+// requestRecord records a processed request.  A request recorded previously
+// is retained, as its objects were processed again idempotently.
+func requestRecord(
+	w objectWriter,
+	namespaceName, name, requestType string,
+	labels, annotations map[string]string,
+	startTime time.Time,
+	objectCount int,
+) (*corev1.ConfigMap, error) {
+	configMap := configMap()
+	configMap.ObjectMeta = metav1.ObjectMeta{
+		Namespace:   namespaceName,
+		Name:        name,
+		Labels:      requestLabels(labels, requestType),
+		Annotations: annotations,
+	}
+	configMap.Data = map[string]string{
+		startTimeKey:   startTime.UTC().Format(time.RFC3339),
+		endTimeKey:     time.Now().UTC().Format(time.RFC3339),
+		objectCountKey: strconv.Itoa(objectCount),
+	}
+
+	return configMap, w.objectCreate(configMap)
+}
+
+type objectWriter struct {
+	ctx context.Context
+	client.Writer
+	log logr.Logger
+}
+
+func (w objectWriter) objectCreate(o client.Object) error {
+	if err := w.Create(w.ctx, o); err != nil {
+		if !k8serrors.IsAlreadyExists(err) {
+			return fmt.Errorf("object create: %w", err)
+		}
+
+		w.log.Info("Object created previously", "type", o.GetObjectKind(), "name", o.GetName())
+	} else {
+		w.log.Info("Object created successfully", "type", o.GetObjectKind(), "name", o.GetName())
+	}
+
+	return nil
+}
+
+func (w objectWriter) objectDelete(o client.Object) error {
+	if err := w.Delete(w.ctx, o); err != nil {
+		if !k8serrors.IsNotFound(err) {
+			return fmt.Errorf("object delete: %w", err)
+		}
+
+		w.log.Info("Object deleted previously", "type", o.GetObjectKind(), "name", o.GetName())
+	} else {
+		w.log.Info("Object deleted successfully", "type", o.GetObjectKind(), "name", o.GetName())
+	}
+
+	return nil
+}
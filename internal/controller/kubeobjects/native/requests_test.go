@@ -0,0 +1,570 @@
+// SPDX-FileCopyrightText: The RamenDR authors
+// SPDX-License-Identifier: Apache-2.0
+
+package native_test
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+
+	jsonpatch "github.com/evanphx/json-patch/v5"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	. "github.com/onsi/gomega/gstruct"
+	"github.com/ramendr/ramen/internal/controller/kubeobjects"
+	"github.com/ramendr/ramen/internal/controller/kubeobjects/native"
+	"github.com/ramendr/ramen/internal/controller/util"
+	velero "github.com/vmware-tanzu/velero/pkg/apis/velero/v1"
+	corev1 "k8s.io/api/core/v1"
+	k8serrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/types"
+	fakediscovery "k8s.io/client-go/discovery/fake"
+	"k8s.io/client-go/dynamic"
+	dynamicfake "k8s.io/client-go/dynamic/fake"
+	clienttesting "k8s.io/client-go/testing"
+	"k8s.io/utils/ptr"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+	"sigs.k8s.io/controller-runtime/pkg/log/zap"
+)
+
+// objectStore stores objects in memory, encoded as JSON as they are in an S3
+// store
+type objectStore map[string][]byte
+
+func (s objectStore) UploadObject(key string, object interface{}) (err error) {
+	s[key], err = json.Marshal(object)
+
+	return err
+}
+
+func (s objectStore) DownloadObject(key string, objectPointer interface{}) error {
+	data, ok := s[key]
+	if !ok {
+		return fmt.Errorf("%s not found", key)
+	}
+
+	return json.Unmarshal(data, objectPointer)
+}
+
+var (
+	configMaps = schema.GroupVersionResource{Version: "v1", Resource: "configmaps"}
+	namespaces = schema.GroupVersionResource{Version: "v1", Resource: "namespaces"}
+	secrets    = schema.GroupVersionResource{Version: "v1", Resource: "secrets"}
+)
+
+func cluster(objects ...runtime.Object) (dynamic.Interface, *fakediscovery.FakeDiscovery) {
+	verbs := metav1.Verbs{"create", "get", "list", "update"}
+
+	client := dynamicfake.NewSimpleDynamicClientWithCustomListKinds(runtime.NewScheme(),
+		map[schema.GroupVersionResource]string{
+			configMaps: "ConfigMapList",
+			namespaces: "NamespaceList",
+			secrets:    "SecretList",
+			{Version: "v1", Resource: "persistentvolumeclaims"}: "PersistentVolumeClaimList",
+		},
+		objects...,
+	)
+	discovery := &fakediscovery.FakeDiscovery{Fake: &clienttesting.Fake{Resources: []*metav1.APIResourceList{{
+		GroupVersion: "v1",
+		APIResources: []metav1.APIResource{
+			{Name: "configmaps", SingularName: "configmap", Kind: "ConfigMap", Namespaced: true, Verbs: verbs,
+				ShortNames: []string{"cm"}},
+			{Name: "namespaces", SingularName: "namespace", Kind: "Namespace", Verbs: verbs},
+			{Name: "secrets", SingularName: "secret", Kind: "Secret", Namespaced: true, Verbs: verbs},
+			{Name: "persistentvolumeclaims", SingularName: "persistentvolumeclaim", Kind: "PersistentVolumeClaim",
+				Namespaced: true, Verbs: verbs, ShortNames: []string{"pvc"}},
+		},
+	}}}}
+
+	return client, discovery
+}
+
+func configMap(namespaceName, name string, labels map[string]string, data string) *unstructured.Unstructured {
+	object := &unstructured.Unstructured{Object: map[string]interface{}{"data": map[string]interface{}{"key": data}}}
+	object.SetAPIVersion("v1")
+	object.SetKind("ConfigMap")
+	object.SetNamespace(namespaceName)
+	object.SetName(name)
+	object.SetLabels(labels)
+	object.SetUID(types.UID("uid-" + name))
+
+	return object
+}
+
+func secret(namespaceName, name, secretType string, labels map[string]string, data string) *unstructured.Unstructured {
+	object := configMap(namespaceName, name, labels, data)
+	object.SetKind("Secret")
+	object.Object["type"] = secretType
+	object.SetAnnotations(map[string]string{corev1.LastAppliedConfigAnnotation: data})
+
+	return object
+}
+
+func persistentVolumeClaim(namespaceName, name string) *unstructured.Unstructured {
+	object := &unstructured.Unstructured{}
+	object.SetAPIVersion("v1")
+	object.SetKind("PersistentVolumeClaim")
+	object.SetNamespace(namespaceName)
+	object.SetName(name)
+
+	return object
+}
+
+// veleroPatch patches an object as a Velero resource modifier rule with the
+// given JSON patches does, whose implementation is internal to Velero: see
+// https://github.com/vmware-tanzu/velero/blob/v1.15.0/internal/resourcemodifiers/json_patch.go
+func veleroPatch(object *unstructured.Unstructured, patches []kubeobjects.Patch) *unstructured.Unstructured {
+	operations := make([]string, len(patches))
+
+	for i, patch := range patches {
+		value := patch.Value
+
+		switch _, err := strconv.ParseFloat(value, 64); {
+		case value == "" || strings.HasPrefix(value, `"`) && strings.HasSuffix(value, `"`):
+			value = `"` + strings.Trim(value, `"`) + `"`
+		case value == "null", strings.EqualFold(value, "true"), strings.EqualFold(value, "false"),
+			strings.HasPrefix(value, "{"), strings.HasPrefix(value, "["), err == nil:
+		default:
+			value = `"` + value + `"`
+		}
+
+		operations[i] = fmt.Sprintf(`{"op": "%s", "from": "%s", "path": "%s", "value": %s}`,
+			patch.Operation, patch.From, patch.Path, value)
+	}
+
+	patch, err := jsonpatch.DecodePatch([]byte("[" + strings.Join(operations, ",") + "]"))
+	Expect(err).ToNot(HaveOccurred())
+
+	document, err := object.MarshalJSON()
+	Expect(err).ToNot(HaveOccurred())
+
+	document, err = patch.Apply(document)
+	if errors.Is(err, jsonpatch.ErrTestFailed) {
+		return object
+	}
+
+	Expect(err).ToNot(HaveOccurred())
+
+	patched := &unstructured.Unstructured{}
+	Expect(patched.UnmarshalJSON(document)).To(Succeed())
+
+	return patched
+}
+
+var _ = Describe("native requests manager", func() {
+	const (
+		requestNamespaceName = "ramen-system"
+		s3ProfileName        = "profile"
+		s3Url                = "http://s3"
+		s3BucketName         = "bucket"
+		s3KeyPrefix          = "ns/vrg/kube-objects/0/"
+		captureName          = "ns--vrg--0--group--profile"
+		recoverName          = "ns--vrg--0"
+	)
+
+	var (
+		ctx     context.Context
+		store   objectStore
+		writer  client.Client
+		labels  map[string]string
+		manager func(dynamic.Interface, *fakediscovery.FakeDiscovery) native.RequestsManager
+	)
+
+	BeforeEach(func() {
+		ctx = context.TODO()
+		store = objectStore{}
+		writer = fake.NewClientBuilder().Build()
+		labels = map[string]string{"owner": "vrg"}
+		manager = func(client dynamic.Interface, discovery *fakediscovery.FakeDiscovery) native.RequestsManager {
+			return native.RequestsManagerNew(client, discovery,
+				func(_ context.Context, profileName string) (native.ObjectStorer, error) {
+					Expect(profileName).To(Equal(s3ProfileName))
+
+					return store, nil
+				},
+			)
+		}
+	})
+
+	captureRequestCreate := func(m native.RequestsManager, spec kubeobjects.Spec) {
+		request, err := m.ProtectRequestCreate(ctx, writer, zap.New(), s3ProfileName, s3Url, s3BucketName, "", s3KeyPrefix,
+			nil, nil, spec, requestNamespaceName, captureName, labels, map[string]string{"generation": "1"})
+		Expect(err).ToNot(HaveOccurred())
+		Expect(request.Status(zap.New())).To(Succeed())
+		Expect(store).To(HaveKey(s3KeyPrefix + m.ProtectsPath() + captureName + "/objects"))
+	}
+
+	recoverRequestCreate := func(m native.RequestsManager, spec kubeobjects.RecoverSpec) error {
+		_, err := m.RecoverRequestCreate(ctx, writer, zap.New(), s3ProfileName, s3Url, s3BucketName, "", s3KeyPrefix,
+			nil, nil, spec, requestNamespaceName, captureName, nil, recoverName, labels, nil)
+
+		return err
+	}
+
+	configMapGet := func(client dynamic.Interface, namespaceName, name string) *unstructured.Unstructured {
+		object, err := client.Resource(configMaps).Namespace(namespaceName).Get(ctx, name, metav1.GetOptions{})
+		Expect(err).ToNot(HaveOccurred())
+
+		return object
+	}
+
+	It("captures and recovers selected objects into mapped namespaces", func() {
+		source, sourceDiscovery := cluster(
+			configMap("app", "selected", map[string]string{"app": "a"}, "a"),
+			configMap("app", "unlabeled", nil, "b"),
+			configMap("app", "ramen", map[string]string{"app": "a", util.CreatedByRamenLabel: "true"}, "c"),
+			configMap("other", "other", map[string]string{"app": "a"}, "d"),
+			persistentVolumeClaim("app", "pvc"),
+		)
+		captureRequestCreate(manager(source, sourceDiscovery), kubeobjects.Spec{
+			KubeResourcesSpec: kubeobjects.KubeResourcesSpec{IncludedNamespaces: []string{"app"}},
+			LabelSelector:     &metav1.LabelSelector{MatchLabels: map[string]string{"app": "a"}},
+		})
+
+		target, targetDiscovery := cluster()
+		m := manager(target, targetDiscovery)
+		Expect(recoverRequestCreate(m, kubeobjects.RecoverSpec{
+			NamespaceMapping: map[string]string{"app": "app-dr"},
+		})).To(Succeed())
+
+		list, err := target.Resource(configMaps).Namespace("").List(ctx, metav1.ListOptions{})
+		Expect(err).ToNot(HaveOccurred())
+		Expect(list.Items).To(HaveLen(1))
+		Expect(list.Items[0].GetNamespace()).To(Equal("app-dr"))
+		Expect(list.Items[0].GetName()).To(Equal("selected"))
+		Expect(list.Items[0].GetUID()).To(BeEmpty())
+
+		_, err = target.Resource(namespaces).Get(ctx, "app-dr", metav1.GetOptions{})
+		Expect(err).ToNot(HaveOccurred())
+
+		requests, err := m.RecoverRequestsGet(ctx, writer, requestNamespaceName, labels)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(requests.Count()).To(Equal(1))
+		Expect(requests.Get(0).Name()).To(Equal(recoverName))
+	})
+
+	It("lists objects by the label selector", func() {
+		source, sourceDiscovery := cluster(configMap("app", "a", map[string]string{"app": "a"}, "a"))
+		captureRequestCreate(manager(source, sourceDiscovery), kubeobjects.Spec{
+			KubeResourcesSpec: kubeobjects.KubeResourcesSpec{IncludedResources: []string{"cm"}},
+			LabelSelector:     &metav1.LabelSelector{MatchLabels: map[string]string{"app": "a"}},
+		})
+
+		fake, ok := source.(*dynamicfake.FakeDynamicClient)
+		Expect(ok).To(BeTrue())
+
+		lists := 0
+
+		for _, action := range fake.Actions() {
+			if list, ok := action.(clienttesting.ListAction); ok && list.GetResource() == configMaps {
+				Expect(list.GetListRestrictions().Labels.String()).To(Equal("app=a"))
+
+				lists++
+			}
+		}
+
+		Expect(lists).To(Equal(1))
+	})
+
+	It("selects objects by any of the OR label selectors and resource names", func() {
+		source, sourceDiscovery := cluster(
+			configMap("app", "a", map[string]string{"app": "a"}, "a"),
+			configMap("app", "b", map[string]string{"app": "b"}, "b"),
+			configMap("app", "c", map[string]string{"app": "c"}, "c"),
+		)
+		captureRequestCreate(manager(source, sourceDiscovery), kubeobjects.Spec{
+			KubeResourcesSpec: kubeobjects.KubeResourcesSpec{IncludedResources: []string{"cm"}},
+			OrLabelSelectors: []*metav1.LabelSelector{
+				{MatchLabels: map[string]string{"app": "a"}},
+				{MatchLabels: map[string]string{"app": "b"}},
+			},
+		})
+
+		target, targetDiscovery := cluster()
+		Expect(recoverRequestCreate(manager(target, targetDiscovery), kubeobjects.RecoverSpec{})).To(Succeed())
+
+		list, err := target.Resource(configMaps).Namespace("app").List(ctx, metav1.ListOptions{})
+		Expect(err).ToNot(HaveOccurred())
+		Expect(list.Items).To(HaveLen(2))
+	})
+
+	DescribeTable("recovers existing objects as per the existing resource policy",
+		func(policy velero.PolicyType, data string) {
+			source, sourceDiscovery := cluster(configMap("app", "cm", nil, "captured"))
+			captureRequestCreate(manager(source, sourceDiscovery), kubeobjects.Spec{})
+
+			target, targetDiscovery := cluster(configMap("app", "cm", nil, "existing"))
+			Expect(recoverRequestCreate(manager(target, targetDiscovery), kubeobjects.RecoverSpec{
+				ExistingResourcePolicy: policy,
+			})).To(Succeed())
+
+			Expect(configMapGet(target, "app", "cm").Object["data"]).To(HaveKeyWithValue("key", data))
+		},
+		Entry("none", velero.PolicyTypeNone, "existing"),
+		Entry("update", velero.PolicyTypeUpdate, "captured"),
+	)
+
+	It("patches the recovered objects each transform matches", func() {
+		source, sourceDiscovery := cluster(
+			configMap("app", "a", map[string]string{"app": "a"}, "a"),
+			configMap("app", "b", map[string]string{"app": "b"}, "b"),
+		)
+		captureRequestCreate(manager(source, sourceDiscovery), kubeobjects.Spec{})
+
+		target, targetDiscovery := cluster()
+		Expect(recoverRequestCreate(manager(target, targetDiscovery), kubeobjects.RecoverSpec{
+			NamespaceMapping: map[string]string{"app": "app-dr"},
+			Transforms: []kubeobjects.Transform{
+				{
+					GroupResource: "configmaps",
+					Namespaces:    []string{"app-dr"},
+					LabelSelector: &metav1.LabelSelector{MatchLabels: map[string]string{"app": "a"}},
+					Patches:       []kubeobjects.Patch{{Operation: "replace", Path: "/data/key", Value: "transformed"}},
+				},
+				{
+					GroupResource:     "*",
+					ResourceNameRegex: "^b$",
+					Patches: []kubeobjects.Patch{
+						{Operation: "add", Path: "/metadata/labels/count", Value: `"1"`},
+						{Operation: "add", Path: "/data/json", Value: "1"},
+					},
+				},
+			},
+		})).To(Succeed())
+
+		Expect(configMapGet(target, "app-dr", "a").Object["data"]).To(HaveKeyWithValue("key", "transformed"))
+		b := configMapGet(target, "app-dr", "b")
+		Expect(b.GetLabels()).To(HaveKeyWithValue("count", "1"))
+		Expect(b.Object["data"]).To(HaveKeyWithValue("key", "b"))
+		Expect(b.Object["data"]).To(HaveKeyWithValue("json", BeNumerically("==", 1)))
+	})
+
+	DescribeTable("patches the recovered objects as Velero resource modifiers do",
+		func(patches ...kubeobjects.Patch) {
+			object := configMap("app", "cm", map[string]string{"app": "a"}, "captured")
+			patched := veleroPatch(object.DeepCopy(), patches)
+
+			source, sourceDiscovery := cluster(object)
+			captureRequestCreate(manager(source, sourceDiscovery), kubeobjects.Spec{})
+
+			target, targetDiscovery := cluster()
+			Expect(recoverRequestCreate(manager(target, targetDiscovery), kubeobjects.RecoverSpec{
+				Transforms: []kubeobjects.Transform{{GroupResource: "configmaps", Patches: patches}},
+			})).To(Succeed())
+
+			recovered := configMapGet(target, "app", "cm")
+			Expect(recovered.GetLabels()).To(Equal(patched.GetLabels()))
+			Expect(recovered.Object["data"]).To(Equal(patched.Object["data"]))
+		},
+		Entry("string", kubeobjects.Patch{Operation: "replace", Path: "/data/key", Value: "transformed"}),
+		Entry("quoted", kubeobjects.Patch{Operation: "add", Path: "/metadata/labels/enabled", Value: `"true"`}),
+		Entry("JSON", kubeobjects.Patch{Operation: "add", Path: "/data/json", Value: `{"a":[1,null]}`}),
+		Entry("test passes",
+			kubeobjects.Patch{Operation: "test", Path: "/metadata/labels/app", Value: "a"},
+			kubeobjects.Patch{Operation: "replace", Path: "/data/key", Value: "transformed"},
+		),
+		Entry("test fails",
+			kubeobjects.Patch{Operation: "test", Path: "/metadata/labels/app", Value: "b"},
+			kubeobjects.Patch{Operation: "replace", Path: "/data/key", Value: "transformed"},
+		),
+	)
+
+	It("fails to recover objects a transform fails to patch", func() {
+		source, sourceDiscovery := cluster(configMap("app", "cm", nil, "captured"))
+		captureRequestCreate(manager(source, sourceDiscovery), kubeobjects.Spec{})
+
+		target, targetDiscovery := cluster()
+		Expect(recoverRequestCreate(manager(target, targetDiscovery), kubeobjects.RecoverSpec{
+			Transforms: []kubeobjects.Transform{{
+				GroupResource: "cm",
+				Patches:       []kubeobjects.Patch{{Operation: "remove", Path: "/spec/absent"}},
+			}},
+		})).ToNot(Succeed())
+	})
+
+	It("verifies captured objects are recoverable with a dry run, counting failures by resource", func() {
+		source, sourceDiscovery := cluster(
+			configMap("app", "accepted", nil, "a"),
+			configMap("app", "rejected", nil, "r"),
+		)
+		captureRequestCreate(manager(source, sourceDiscovery), kubeobjects.Spec{})
+
+		// The fake client neither passes nor honors dry run options, so a webhook
+		// that denies an object is simulated by a reactor that creates none
+		target, targetDiscovery := cluster()
+		target.(*dynamicfake.FakeDynamicClient).PrependReactor("create", "*",
+			func(action clienttesting.Action) (bool, runtime.Object, error) {
+				object := action.(clienttesting.CreateAction).GetObject().(*unstructured.Unstructured)
+				if object.GetName() == "rejected" {
+					return true, nil, fmt.Errorf("denied by webhook")
+				}
+
+				return true, object, nil
+			},
+		)
+
+		verification, err := manager(target, targetDiscovery).RecoverVerify(ctx, s3ProfileName, s3KeyPrefix,
+			captureName, kubeobjects.RecoverSpec{}, zap.New())
+		Expect(err).ToNot(HaveOccurred())
+		Expect(verification.ObjectCount).To(Equal(1))
+		Expect(verification.Failures).To(HaveExactElements(
+			MatchFields(IgnoreExtras, Fields{
+				"Resource": Equal("configmaps"),
+				"Count":    Equal(1),
+				"Message":  ContainSubstring("denied by webhook"),
+			}),
+		))
+	})
+
+	It("counts captured objects whose namespace does not exist as unverifiable", func() {
+		source, sourceDiscovery := cluster(configMap("app", "a", nil, "a"), configMap("app", "b", nil, "b"))
+		captureRequestCreate(manager(source, sourceDiscovery), kubeobjects.Spec{})
+
+		target, targetDiscovery := cluster()
+		target.(*dynamicfake.FakeDynamicClient).PrependReactor("create", "configmaps",
+			func(action clienttesting.Action) (bool, runtime.Object, error) {
+				return true, nil, k8serrors.NewNotFound(schema.GroupResource{Resource: "namespaces"}, "app")
+			},
+		)
+
+		verification, err := manager(target, targetDiscovery).RecoverVerify(ctx, s3ProfileName, s3KeyPrefix,
+			captureName, kubeobjects.RecoverSpec{}, zap.New())
+		Expect(err).ToNot(HaveOccurred())
+		Expect(verification.ObjectCount).To(BeZero())
+		Expect(verification.Unverifiable).To(Equal(2))
+		Expect(verification.Failures).To(BeEmpty())
+	})
+
+	It("excludes and redacts secrets as the first policy that selects each directs", func() {
+		source, sourceDiscovery := cluster(
+			secret("app", "tls", string(corev1.SecretTypeTLS), nil, "tls data"),
+			secret("app", "excluded", string(corev1.SecretTypeOpaque), map[string]string{"dr": "exclude"}, "e data"),
+			secret("app", "redacted", string(corev1.SecretTypeOpaque), map[string]string{"dr": "redact"}, "r data"),
+			secret("app", "captured", string(corev1.SecretTypeOpaque), nil, "c"),
+		)
+		m := manager(source, sourceDiscovery)
+		spec := kubeobjects.Spec{SecretPolicies: []kubeobjects.SecretPolicy{
+			{Types: []string{string(corev1.SecretTypeTLS)}, Action: kubeobjects.SecretPolicyActionRedact},
+			{
+				LabelSelector: &metav1.LabelSelector{MatchLabels: map[string]string{"dr": "exclude"}},
+				Action:        kubeobjects.SecretPolicyActionExclude,
+			},
+			{
+				LabelSelector:  &metav1.LabelSelector{MatchLabels: map[string]string{"dr": "redact"}},
+				Action:         kubeobjects.SecretPolicyActionRedact,
+				DataSourceName: "source",
+			},
+		}}
+		captureRequestCreate(m, spec)
+		Expect(string(store[s3KeyPrefix+m.ProtectsPath()+captureName+"/objects"])).ToNot(
+			SatisfyAny(ContainSubstring("tls data"), ContainSubstring("e data"), ContainSubstring("r data")))
+
+		report, err := m.ProtectRequestSecretsReportGet(store, s3KeyPrefix, captureName)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(report.Excluded).To(ConsistOf("secrets/app/excluded"))
+		Expect(report.Redacted).To(ConsistOf("secrets/app/tls", "secrets/app/redacted"))
+
+		target, targetDiscovery := cluster(
+			secret("app", "tls", string(corev1.SecretTypeTLS), nil, "target key"),
+			secret("app", "source", string(corev1.SecretTypeOpaque), nil, "target r"),
+		)
+		Expect(recoverRequestCreate(manager(target, targetDiscovery), kubeobjects.RecoverSpec{
+			ExistingResourcePolicy: velero.PolicyTypeUpdate,
+		})).To(Succeed())
+
+		secretGet := func(name string) (*unstructured.Unstructured, error) {
+			return target.Resource(secrets).Namespace("app").Get(ctx, name, metav1.GetOptions{})
+		}
+		_, err = secretGet("excluded")
+		Expect(err).To(HaveOccurred())
+
+		for name, data := range map[string]string{"tls": "target key", "redacted": "target r", "captured": "c"} {
+			object, err := secretGet(name)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(object.Object["data"]).To(HaveKeyWithValue("key", data))
+			Expect(object.GetAnnotations()).ToNot(HaveKey(kubeobjects.SecretDataSourceAnnotation))
+		}
+
+		other, otherDiscovery := cluster()
+		Expect(recoverRequestCreate(manager(other, otherDiscovery), kubeobjects.RecoverSpec{})).To(
+			MatchError(ContainSubstring("data source source get")))
+	})
+
+	It("inventories captured objects by content, excluding status and cluster metadata", func() {
+		modified := configMap("app", "b", nil, "b")
+		modified.SetResourceVersion("2")
+		modified.Object["status"] = map[string]interface{}{"phase": "modified"}
+		source, sourceDiscovery := cluster(configMap("app", "a", nil, "a"), modified)
+		spec := kubeobjects.Spec{KubeResourcesSpec: kubeobjects.KubeResourcesSpec{IncludedNamespaces: []string{"app"}}}
+		m := manager(source, sourceDiscovery)
+		captureRequestCreate(m, spec)
+
+		inventory, err := m.ProtectRequestInventoryGet(store, s3KeyPrefix, captureName)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(inventory).To(HaveLen(2))
+		Expect(inventory).To(HaveKey("configmaps/app/a"))
+
+		target, targetDiscovery := cluster(configMap("app", "a", nil, "modified"), configMap("app", "b", nil, "b"))
+		captureRequestCreate(manager(target, targetDiscovery), spec)
+		other, err := m.ProtectRequestInventoryGet(store, s3KeyPrefix, captureName)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(kubeobjects.InventoryDiffGet(inventory, other).Modified).To(Equal([]string{"configmaps/app/a"}))
+
+		_, err = m.ProtectRequestInventoryGet(store, s3KeyPrefix, "absent")
+		Expect(err).To(MatchError(ContainSubstring("capture absent download")))
+	})
+
+	It("selects the objects a capture would, to watch them", func() {
+		source, sourceDiscovery := cluster()
+		selector, err := manager(source, sourceDiscovery).SelectorNew([]kubeobjects.Spec{{
+			KubeResourcesSpec: kubeobjects.KubeResourcesSpec{IncludedNamespaces: []string{"app"}},
+			LabelSelector:     &metav1.LabelSelector{MatchLabels: map[string]string{"app": "a"}},
+		}})
+		Expect(err).ToNot(HaveOccurred())
+		Expect(selector.Resources()).To(Equal([]schema.GroupVersionResource{configMaps, secrets}))
+		Expect(selector.Namespaces(configMaps)).To(Equal([]string{"app"}))
+
+		Expect(selector.Selects(configMaps, configMap("app", "a", map[string]string{"app": "a"}, ""))).To(BeTrue())
+		Expect(selector.Selects(configMaps, configMap("app", "b", map[string]string{"app": "b"}, ""))).To(BeFalse())
+		Expect(selector.Selects(configMaps, configMap("other", "a", map[string]string{"app": "a"}, ""))).To(BeFalse())
+		Expect(selector.Selects(namespaces, configMap("app", "a", map[string]string{"app": "a"}, ""))).To(BeFalse())
+
+		controlled := configMap("app", "c", map[string]string{"app": "a"}, "")
+		controlled.SetOwnerReferences([]metav1.OwnerReference{{Name: "owner", Controller: ptr.To(true)}})
+		Expect(selector.Selects(configMaps, controlled)).To(BeFalse())
+
+		unrestricted, err := manager(source, sourceDiscovery).SelectorNew([]kubeobjects.Spec{
+			{IncludeClusterResources: ptr.To(true)},
+		})
+		Expect(err).ToNot(HaveOccurred())
+		Expect(unrestricted.Namespaces(configMaps)).To(Equal([]string{metav1.NamespaceAll}))
+		Expect(unrestricted.Namespaces(namespaces)).To(Equal([]string{metav1.NamespaceAll}))
+	})
+
+	It("deletes requests by type", func() {
+		source, sourceDiscovery := cluster()
+		m := manager(source, sourceDiscovery)
+		captureRequestCreate(m, kubeobjects.Spec{})
+		Expect(recoverRequestCreate(m, kubeobjects.RecoverSpec{})).To(Succeed())
+
+		Expect(m.ProtectRequestsDelete(ctx, writer, requestNamespaceName, labels)).To(Succeed())
+
+		configMaps := &corev1.ConfigMapList{}
+		Expect(writer.List(ctx, configMaps)).To(Succeed())
+		Expect(configMaps.Items).To(HaveLen(1))
+		Expect(configMaps.Items[0].Name).To(Equal(recoverName))
+
+		Expect(m.RecoverRequestsDelete(ctx, writer, requestNamespaceName, labels)).To(Succeed())
+		Expect(writer.List(ctx, configMaps)).To(Succeed())
+		Expect(configMaps.Items).To(BeEmpty())
+	})
+})
@@ -0,0 +1,174 @@
+// SPDX-FileCopyrightText: The RamenDR authors
+// SPDX-License-Identifier: Apache-2.0
+
+package native
+
+import (
+	"fmt"
+	"slices"
+
+	"github.com/ramendr/ramen/internal/controller/kubeobjects"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/labels"
+)
+
+var secretsResource = resource{
+	Version:      "v1",
+	Name:         "secrets",
+	SingularName: "secret",
+	Kind:         "Secret",
+	Namespaced:   true,
+}
+
+func (r resource) isSecrets() bool {
+	return r.Group == "" && r.Name == secretsResource.Name
+}
+
+type secretPolicy struct {
+	kubeobjects.SecretPolicy
+	selector labels.Selector
+}
+
+func secretPoliciesNew(specs []kubeobjects.SecretPolicy) ([]secretPolicy, error) {
+	policies := make([]secretPolicy, len(specs))
+
+	for i, spec := range specs {
+		selector := labels.Everything()
+
+		if spec.LabelSelector != nil {
+			var err error
+
+			selector, err = metav1.LabelSelectorAsSelector(spec.LabelSelector)
+			if err != nil {
+				return nil, fmt.Errorf("secret policy %d label selector %v: %w", i, spec.LabelSelector, err)
+			}
+		}
+
+		policies[i] = secretPolicy{SecretPolicy: spec, selector: selector}
+	}
+
+	return policies, nil
+}
+
+// secretPolicyGet returns the first of the given policies that selects the
+// given Secret, if any.
+func secretPolicyGet(policies []secretPolicy, secret *unstructured.Unstructured) *secretPolicy {
+	secretType, _, _ := unstructured.NestedString(secret.Object, "type")
+	if secretType == "" {
+		secretType = string(corev1.SecretTypeOpaque)
+	}
+
+	for i := range policies {
+		policy := &policies[i]
+		if (len(policy.Types) == 0 || slices.Contains(policy.Types, secretType)) &&
+			policy.selector.Matches(labels.Set(secret.GetLabels())) {
+			return policy
+		}
+	}
+
+	return nil
+}
+
+// secretsPoliciesApply excludes or redacts the given Secrets as the first
+// policy that selects each directs, and reports those it does.  A redacted
+// Secret is annotated with the name of the Secret its data is recovered from,
+// and its last applied configuration, which may include its data, is removed.
+func secretsPoliciesApply(
+	policies []secretPolicy, objects []map[string]interface{}, report *kubeobjects.SecretsReport,
+) []map[string]interface{} {
+	if len(policies) == 0 {
+		return objects
+	}
+
+	groupResource := secretsResource.groupVersionResource().GroupResource().String()
+	applied := make([]map[string]interface{}, 0, len(objects))
+
+	for _, content := range objects {
+		secret := &unstructured.Unstructured{Object: content}
+		key := kubeobjects.InventoryKey(groupResource, secret.GetNamespace(), secret.GetName())
+
+		policy := secretPolicyGet(policies, secret)
+		switch {
+		case policy == nil:
+		case policy.Action == kubeobjects.SecretPolicyActionExclude:
+			report.Excluded = append(report.Excluded, key)
+
+			continue
+		case policy.Action == kubeobjects.SecretPolicyActionRedact:
+			dataSourceName := policy.DataSourceName
+			if dataSourceName == "" {
+				dataSourceName = secret.GetName()
+			}
+
+			unstructured.RemoveNestedField(secret.Object, "data")
+			unstructured.RemoveNestedField(secret.Object, "stringData")
+
+			annotations := secret.GetAnnotations()
+			if annotations == nil {
+				annotations = map[string]string{}
+			}
+
+			delete(annotations, corev1.LastAppliedConfigAnnotation)
+			annotations[kubeobjects.SecretDataSourceAnnotation] = dataSourceName
+			secret.SetAnnotations(annotations)
+			report.Redacted = append(report.Redacted, key)
+		}
+
+		applied = append(applied, secret.Object)
+	}
+
+	return applied
+}
+
+// ProtectRequestSecretsReportGet returns the Secrets that the named capture,
+// stored with the given key prefix, excluded or redacted.
+func (RequestsManager) ProtectRequestSecretsReportGet(
+	objectDownloader kubeobjects.ObjectDownloader, s3KeyPrefix, captureName string,
+) (kubeobjects.SecretsReport, error) {
+	report := kubeobjects.SecretsReport{Excluded: []string{}, Redacted: []string{}}
+
+	capture := &capture{}
+	if err := objectDownloader.DownloadObject(captureObjectKey(s3KeyPrefix, captureName), capture); err != nil {
+		return report, fmt.Errorf("capture %s download: %w", captureName, err)
+	}
+
+	if capture.Secrets != nil {
+		report.Add(*capture.Secrets)
+	}
+
+	return report, nil
+}
+
+// secretDataRecover sets the data of the given redacted Secret to that of its
+// data source, in the namespace it is recovered to.  A Secret that is its own
+// data source must exist, and is not recovered, as true is returned.
+func (r recoverer) secretDataRecover(secret *unstructured.Unstructured) (bool, error) {
+	annotations := secret.GetAnnotations()
+
+	dataSourceName, ok := annotations[kubeobjects.SecretDataSourceAnnotation]
+	if !ok {
+		return false, nil
+	}
+
+	delete(annotations, kubeobjects.SecretDataSourceAnnotation)
+	secret.SetAnnotations(annotations)
+
+	dataSource, err := r.client.Resource(secretsResource.groupVersionResource()).Namespace(secret.GetNamespace()).
+		Get(r.ctx, dataSourceName, metav1.GetOptions{})
+	if err != nil {
+		return false, fmt.Errorf("secret %s/%s data source %s get: %w",
+			secret.GetNamespace(), secret.GetName(), dataSourceName, err)
+	}
+
+	if dataSourceName == secret.GetName() {
+		return true, nil
+	}
+
+	if data, ok := dataSource.Object["data"]; ok {
+		secret.Object["data"] = data
+	}
+
+	return false, nil
+}
@@ -0,0 +1,124 @@
+// SPDX-FileCopyrightText: The RamenDR authors
+// SPDX-License-Identifier: Apache-2.0
+
+package native
+
+import (
+	"slices"
+	"strings"
+
+	"github.com/ramendr/ramen/internal/controller/kubeobjects"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+)
+
+// Selector selects the objects that captures of the specs it is created from
+// would, so that they can be watched for changes.
+type Selector struct {
+	resources map[schema.GroupVersionResource][]specSelector
+	// clusterScoped resources are not namespaced
+	clusterScoped map[schema.GroupVersionResource]bool
+}
+
+type specSelector struct {
+	namespaceNames []string
+	objectSelector objectSelector
+}
+
+func (m RequestsManager) SelectorNew(specs []kubeobjects.Spec) (Selector, error) {
+	resources, err := m.resources()
+	if err != nil {
+		return Selector{}, err
+	}
+
+	s := Selector{
+		resources:     map[schema.GroupVersionResource][]specSelector{},
+		clusterScoped: map[schema.GroupVersionResource]bool{},
+	}
+
+	for _, spec := range specs {
+		objectSelector, err := objectSelectorNew(spec)
+		if err != nil {
+			return Selector{}, err
+		}
+
+		excluded := append(slices.Clone(spec.ExcludedResources), excludedResourcesDefault...)
+		includeClusterResources := spec.IncludeClusterResources != nil && *spec.IncludeClusterResources
+
+		for _, resource := range resources {
+			if !resource.selected(spec.IncludedResources, excluded) ||
+				(!resource.Namespaced && !includeClusterResources) {
+				continue
+			}
+
+			groupVersionResource := resource.groupVersionResource()
+			s.clusterScoped[groupVersionResource] = !resource.Namespaced
+			s.resources[groupVersionResource] = append(s.resources[groupVersionResource], specSelector{
+				namespaceNames: spec.IncludedNamespaces,
+				objectSelector: objectSelector,
+			})
+		}
+	}
+
+	return s, nil
+}
+
+// Resources returns the resources of the objects selected, sorted.
+func (s Selector) Resources() []schema.GroupVersionResource {
+	resources := make([]schema.GroupVersionResource, 0, len(s.resources))
+	for resource := range s.resources {
+		resources = append(resources, resource)
+	}
+
+	slices.SortFunc(resources, func(a, b schema.GroupVersionResource) int {
+		return strings.Compare(a.String(), b.String())
+	})
+
+	return resources
+}
+
+// Namespaces returns the names of the namespaces of the objects of the given
+// resource selected, sorted, or only metav1.NamespaceAll if objects in any
+// namespace, or not in one, are.
+func (s Selector) Namespaces(resource schema.GroupVersionResource) []string {
+	if s.clusterScoped[resource] {
+		return []string{metav1.NamespaceAll}
+	}
+
+	namespaceNames := []string{}
+
+	for _, specSelector := range s.resources[resource] {
+		if len(specSelector.namespaceNames) == 0 || slices.Contains(specSelector.namespaceNames, "*") {
+			return []string{metav1.NamespaceAll}
+		}
+
+		namespaceNames = append(namespaceNames, specSelector.namespaceNames...)
+	}
+
+	slices.Sort(namespaceNames)
+
+	return slices.Compact(namespaceNames)
+}
+
+// Selects returns true if any spec selects the given object of the given
+// resource.  Objects managed by a controller are not selected, as they are not
+// captured.
+func (s Selector) Selects(resource schema.GroupVersionResource, object metav1.Object) bool {
+	if metav1.GetControllerOf(object) != nil {
+		return false
+	}
+
+	for _, specSelector := range s.resources[resource] {
+		namespaceNames := specSelector.namespaceNames
+		if object.GetNamespace() != "" && len(namespaceNames) != 0 && !slices.Contains(namespaceNames, "*") &&
+			!slices.Contains(namespaceNames, object.GetNamespace()) {
+			continue
+		}
+
+		if specSelector.objectSelector.matches(object) {
+			return true
+		}
+	}
+
+	return false
+}
@@ -0,0 +1,144 @@
+// SPDX-FileCopyrightText: The RamenDR authors
+// SPDX-License-Identifier: Apache-2.0
+
+package native
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"regexp"
+	"slices"
+
+	jsonpatch "github.com/evanphx/json-patch/v5"
+	"github.com/ramendr/ramen/internal/controller/kubeobjects"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/labels"
+)
+
+// transform is a recover spec transform, compiled to match and patch objects.
+type transform struct {
+	groupResource string
+	namespaces    []string
+	nameRegexp    *regexp.Regexp
+	selector      labels.Selector
+	patch         jsonpatch.Patch
+}
+
+func transformsNew(specs []kubeobjects.Transform) ([]transform, error) {
+	transforms := make([]transform, 0, len(specs))
+
+	for i, spec := range specs {
+		transform, err := transformNew(spec)
+		if err != nil {
+			return nil, fmt.Errorf("transform %d: %w", i, err)
+		}
+
+		transforms = append(transforms, transform)
+	}
+
+	return transforms, nil
+}
+
+func transformNew(spec kubeobjects.Transform) (transform, error) {
+	t := transform{groupResource: spec.GroupResource, namespaces: spec.Namespaces, selector: labels.Everything()}
+
+	if spec.ResourceNameRegex != "" {
+		nameRegexp, err := regexp.Compile(spec.ResourceNameRegex)
+		if err != nil {
+			return t, fmt.Errorf("resource name regex: %w", err)
+		}
+
+		t.nameRegexp = nameRegexp
+	}
+
+	if spec.LabelSelector != nil {
+		selector, err := metav1.LabelSelectorAsSelector(spec.LabelSelector)
+		if err != nil {
+			return t, fmt.Errorf("label selector %v: %w", spec.LabelSelector, err)
+		}
+
+		t.selector = selector
+	}
+
+	operations := make([]map[string]interface{}, 0, len(spec.Patches))
+
+	for _, patch := range spec.Patches {
+		operation := map[string]interface{}{"op": patch.Operation, "path": patch.Path}
+
+		if patch.From != "" {
+			operation["from"] = patch.From
+		}
+
+		switch patch.Operation {
+		case "add", "replace", "test":
+			operation["value"] = patchValue(patch.Value)
+		}
+
+		operations = append(operations, operation)
+	}
+
+	document, err := json.Marshal(operations)
+	if err != nil {
+		return t, fmt.Errorf("patches encode: %w", err)
+	}
+
+	if t.patch, err = jsonpatch.DecodePatch(document); err != nil {
+		return t, fmt.Errorf("patches decode: %w", err)
+	}
+
+	return t, nil
+}
+
+// patchValue returns the value a patch's JSON denotes, or else the patch's
+// text as a string, as Velero does.
+func patchValue(text string) json.RawMessage {
+	if text != "" && json.Valid([]byte(text)) {
+		return json.RawMessage(text)
+	}
+
+	value, _ := json.Marshal(text)
+
+	return value
+}
+
+func (t transform) matches(resource resource, object *unstructured.Unstructured) bool {
+	return resource.matches([]string{t.groupResource}) &&
+		(len(t.namespaces) == 0 || slices.Contains(t.namespaces, object.GetNamespace())) &&
+		(t.nameRegexp == nil || t.nameRegexp.MatchString(object.GetName())) &&
+		t.selector.Matches(labels.Set(object.GetLabels()))
+}
+
+// transformsApply patches the given object with each transform that matches it,
+// in order.  A transform whose test operation fails is skipped, as a Velero
+// resource modifier rule is.
+func transformsApply(
+	transforms []transform, resource resource, object *unstructured.Unstructured,
+) (*unstructured.Unstructured, error) {
+	for i, transform := range transforms {
+		if !transform.matches(resource, object) {
+			continue
+		}
+
+		document, err := object.MarshalJSON()
+		if err != nil {
+			return nil, err
+		}
+
+		if document, err = transform.patch.Apply(document); err != nil {
+			if errors.Is(err, jsonpatch.ErrTestFailed) {
+				continue
+			}
+
+			return nil, fmt.Errorf("transform %d apply: %w", i, err)
+		}
+
+		object = &unstructured.Unstructured{}
+		if err := object.UnmarshalJSON(document); err != nil {
+			return nil, fmt.Errorf("transform %d result decode: %w", i, err)
+		}
+	}
+
+	return object, nil
+}
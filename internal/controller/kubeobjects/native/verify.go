@@ -0,0 +1,49 @@
+// SPDX-FileCopyrightText: The RamenDR authors
+// SPDX-License-Identifier: Apache-2.0
+
+package native
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/go-logr/logr"
+	"github.com/ramendr/ramen/internal/controller/kubeobjects"
+)
+
+// RecoverVerify verifies that the objects of the named capture that the given
+// recover spec selects can be recovered, by recovering them with a server-side
+// dry run, so that objects the cluster would reject, for example because their
+// resource is not defined or a webhook denies them, are reported before they
+// are to be recovered.  An object whose namespace does not exist is counted as
+// unverifiable, as objects are not created in a namespace whose creation was a
+// dry run, and status is not verified.
+func (m RequestsManager) RecoverVerify(
+	ctx context.Context,
+	s3ProfileName string,
+	s3KeyPrefix string,
+	captureName string,
+	recoverSpec kubeobjects.RecoverSpec,
+	log logr.Logger,
+) (kubeobjects.RecoverVerification, error) {
+	verification := kubeobjects.RecoverVerification{Failures: []kubeobjects.RecoverFailure{}}
+
+	objectStorer, err := m.objectStorerGet(ctx, s3ProfileName)
+	if err != nil {
+		return verification, fmt.Errorf("verify object store get: %w", err)
+	}
+
+	capture := &capture{}
+	if err := objectStorer.DownloadObject(captureObjectKey(s3KeyPrefix, captureName), capture); err != nil {
+		return verification, fmt.Errorf("verify capture %s download: %w", captureName, err)
+	}
+
+	count, err := m.recover(ctx, capture, recoverSpec, &verification, log.WithValues("dry run", true))
+	if err != nil {
+		return verification, fmt.Errorf("verify capture %s: %w", captureName, err)
+	}
+
+	verification.ObjectCount = count
+
+	return verification, nil
+}
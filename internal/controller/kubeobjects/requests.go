@@ -61,6 +61,31 @@ type RecoverSpec struct {
 	RestoreStatus *velero.RestoreStatusSpec `json:"restoreStatus,omitempty"`
 	//+optional
 	ExistingResourcePolicy velero.PolicyType `json:"existingResourcePolicy,omitempty"`
+	//+optional
+	Transforms []Transform `json:"transforms,omitempty"`
+}
+
+// Transform patches the recovered objects it matches, as a Velero resource
+// modifier rule does.
+type Transform struct {
+	GroupResource string `json:"groupResource"`
+	//+optional
+	ResourceNameRegex string `json:"resourceNameRegex,omitempty"`
+	//+optional
+	Namespaces []string `json:"namespaces,omitempty"`
+	//+optional
+	LabelSelector *metav1.LabelSelector `json:"labelSelector,omitempty"`
+	Patches       []Patch               `json:"patches"`
+}
+
+// Patch is a JSON patch operation.  Its value is JSON, or else a string.
+type Patch struct {
+	Operation string `json:"operation"`
+	Path      string `json:"path"`
+	//+optional
+	From string `json:"from,omitempty"`
+	//+optional
+	Value string `json:"value,omitempty"`
 }
 
 type Spec struct {
@@ -73,6 +98,9 @@ type Spec struct {
 
 	//+optional
 	IncludeClusterResources *bool `json:"includeClusterResources,omitempty"`
+
+	//+optional
+	SecretPolicies []SecretPolicy `json:"secretPolicies,omitempty"`
 }
 
 type KubeResourcesSpec struct {
@@ -141,6 +169,20 @@ type Operation struct {
 	InverseOp string `json:"inverseOp,omitempty"`
 }
 
+// OperationOutput is the criteria an exec hook operation's command output must
+// meet for it to succeed, and the parameter to store it in, as specified by a
+// Recipe annotation.
+type OperationOutput struct {
+	// Regular expression the command's stdout must match
+	StdoutPattern string `json:"stdoutPattern,omitempty"`
+	// Regular expression the command's stderr must match
+	StderrPattern string `json:"stderrPattern,omitempty"`
+	// Check condition, JSONPath or CEL, that must be true of the command's stdout, in JSON
+	Condition string `json:"condition,omitempty"`
+	// Name of the recipe parameter whose values later steps of the workflow expand to the command's stdout
+	Parameter string `json:"parameter,omitempty"`
+}
+
 func RequestProcessingErrorCreate(s string) RequestProcessingError { return RequestProcessingError{s} }
 func (e RequestProcessingError) Error() string                     { return e.string }
 
@@ -151,6 +193,12 @@ func (RequestProcessingError) Is(target error) bool {
 	return ok
 }
 
+// ObjectDownloader downloads the objects a protect request stores in an s3
+// store.
+type ObjectDownloader interface {
+	DownloadObject(key string, objectPointer interface{}) error
+}
+
 type RequestsManager interface {
 	ProtectsPath() string
 	RecoversPath() string
@@ -158,6 +206,7 @@ type RequestsManager interface {
 	RecoverRequestNew() RecoverRequest
 	ProtectRequestCreate(
 		c context.Context, w client.Writer, l logr.Logger,
+		s3ProfileName string,
 		s3Url string,
 		s3BucketName string,
 		s3RegionName string,
@@ -172,6 +221,7 @@ type RequestsManager interface {
 	) (ProtectRequest, error)
 	RecoverRequestCreate(
 		c context.Context, w client.Writer, l logr.Logger,
+		s3ProfileName string,
 		s3Url string,
 		s3BucketName string,
 		s3RegionName string,
@@ -186,6 +236,11 @@ type RequestsManager interface {
 		labels map[string]string,
 		annotations map[string]string,
 	) (RecoverRequest, error)
+	ProtectRequestInventoryGet(
+		d ObjectDownloader,
+		s3KeyPrefix string,
+		protectRequestName string,
+	) (Inventory, error)
 	ProtectRequestsGet(
 		c context.Context, r client.Reader, requestNamespaceName string, labels map[string]string,
 	) (Requests, error)
@@ -27,4 +27,50 @@ var _ = Describe("kubeobjects", func() {
 			Expect(errors.Is(err, errors.New("error"))).To(Equal(false))
 		})
 	})
+	Context("comparing inventories", func() {
+		It("lists the objects added, removed and modified, sorted", func() {
+			diff := kubeobjects.InventoryDiffGet(
+				kubeobjects.Inventory{"configmaps/a/same": "1", "configmaps/a/modified": "1", "configmaps/a/removed": "1"},
+				kubeobjects.Inventory{"configmaps/a/same": "1", "configmaps/a/modified": "2",
+					"configmaps/b/added": "1", "configmaps/a/added": "1"},
+			)
+			Expect(diff.Added).To(Equal([]string{"configmaps/a/added", "configmaps/b/added"}))
+			Expect(diff.Removed).To(Equal([]string{"configmaps/a/removed"}))
+			Expect(diff.Modified).To(Equal([]string{"configmaps/a/modified"}))
+			Expect(diff.Empty()).To(BeFalse())
+		})
+		It("does not list the objects modified if the content of any is unknown", func() {
+			diff := kubeobjects.InventoryDiffGet(
+				kubeobjects.Inventory{"configmaps/a/same": "", "configmaps/a/removed": ""},
+				kubeobjects.Inventory{"configmaps/a/same": "", "configmaps/a/added": ""},
+			)
+			Expect(diff.Added).To(Equal([]string{"configmaps/a/added"}))
+			Expect(diff.Removed).To(Equal([]string{"configmaps/a/removed"}))
+			Expect(diff.Modified).To(BeNil())
+		})
+		It("is empty for the same inventory", func() {
+			inventory := kubeobjects.Inventory{kubeobjects.InventoryKey("configmaps", "a", "b"): "1"}
+			Expect(kubeobjects.InventoryDiffGet(inventory, inventory).Empty()).To(BeTrue())
+		})
+	})
+	Context("adding recover verifications", func() {
+		It("sums object counts and failure counts by resource, sorted, keeping the first message", func() {
+			verification := kubeobjects.RecoverVerification{ObjectCount: 1}
+			verification.FailureAdd("deployments.apps", "first")
+
+			other := kubeobjects.RecoverVerification{ObjectCount: 2, Unverifiable: 1}
+			other.FailureAdd("deployments.apps", "second")
+			other.FailureAdd("configmaps", "denied")
+			other.FailureAdd("configmaps", "denied again")
+
+			verification.Add(other)
+			Expect(verification.ObjectCount).To(Equal(3))
+			Expect(verification.Unverifiable).To(Equal(1))
+			Expect(verification.FailureCount()).To(Equal(4))
+			Expect(verification.Failures).To(Equal([]kubeobjects.RecoverFailure{
+				{Resource: "configmaps", Count: 2, Message: "denied"},
+				{Resource: "deployments.apps", Count: 2, Message: "first"},
+			}))
+		})
+	})
 })
@@ -0,0 +1,42 @@
+// SPDX-FileCopyrightText: The RamenDR authors
+// SPDX-License-Identifier: Apache-2.0
+
+package kubeobjects
+
+import (
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+const (
+	SecretPolicyActionExclude = "Exclude"
+	SecretPolicyActionRedact  = "Redact"
+
+	// SecretDataSourceAnnotation names, on a redacted Secret, the Secret whose
+	// data it is recovered with, in the namespace it is recovered to
+	SecretDataSourceAnnotation = "ramendr.openshift.io/secret-data-source"
+)
+
+// SecretPolicy excludes the Secrets it selects from a capture, or redacts
+// their data, as a RamenConfig or VRG secret policy does.
+type SecretPolicy struct {
+	//+optional
+	Types []string `json:"types,omitempty"`
+	//+optional
+	LabelSelector *metav1.LabelSelector `json:"labelSelector,omitempty"`
+	Action        string                `json:"action"`
+	//+optional
+	DataSourceName string `json:"dataSourceName,omitempty"`
+}
+
+// SecretsReport lists the inventory keys of the Secrets a capture excluded and
+// redacted.
+type SecretsReport struct {
+	Excluded []string `json:"excluded"`
+	Redacted []string `json:"redacted"`
+}
+
+// Add adds the Secrets of another report to the report.
+func (r *SecretsReport) Add(other SecretsReport) {
+	r.Excluded = append(r.Excluded, other.Excluded...)
+	r.Redacted = append(r.Redacted, other.Redacted...)
+}
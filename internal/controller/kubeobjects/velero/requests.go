@@ -7,6 +7,7 @@
 // +kubebuilder:rbac:groups=velero.io,resources=backupstoragelocations,verbs=create;delete;deletecollection;get;patch;update
 // +kubebuilder:rbac:groups=velero.io,resources=restores,verbs=create;delete;deletecollection;get;list;patch;update;watch
 // +kubebuilder:rbac:groups=velero.io,resources=restores/status,verbs=get
+// +kubebuilder:rbac:groups=core,resources=configmaps,verbs=create;delete;deletecollection;get;list;watch
 
 package velero
 
@@ -14,6 +15,7 @@ import (
 	"context"
 	"errors"
 	"fmt"
+	"strings"
 
 	"github.com/go-logr/logr"
 	"github.com/ramendr/ramen/internal/controller/kubeobjects"
@@ -23,6 +25,7 @@ import (
 	k8serrors "k8s.io/apimachinery/pkg/api/errors"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/yaml"
 )
 
 const (
@@ -70,6 +73,37 @@ func (RequestsManager) RecoverRequestNew() kubeobjects.RecoverRequest {
 	return RestoreRequest{&velero.Restore{TypeMeta: restoreTypeMeta()}}
 }
 
+// ProtectRequestInventoryGet returns the inventory of the objects the named
+// backup stored with the given key prefix, from the resource list Velero
+// stores with it.  The list records neither their content nor its digest, so
+// an inventory only reveals objects added and removed, not ones modified, and
+// maps each object to "", as its content is unknown.
+func (RequestsManager) ProtectRequestInventoryGet(
+	objectDownloader kubeobjects.ObjectDownloader, s3KeyPrefix, backupName string,
+) (kubeobjects.Inventory, error) {
+	resourceList := map[string][]string{}
+	if err := objectDownloader.DownloadObject(
+		s3KeyPrefix+protectsPath+backupName+"/"+backupName+"-resource-list.json.gz", &resourceList,
+	); err != nil {
+		return nil, fmt.Errorf("backup %s resource list download: %w", backupName, err)
+	}
+
+	inventory := kubeobjects.Inventory{}
+
+	for groupVersionKind, objects := range resourceList {
+		for _, object := range objects {
+			namespaceName, name, found := strings.Cut(object, "/")
+			if !found {
+				namespaceName, name = "", object
+			}
+
+			inventory[kubeobjects.InventoryKey(groupVersionKind, namespaceName, name)] = ""
+		}
+	}
+
+	return inventory, nil
+}
+
 func (RequestsManager) ProtectRequestsGet(
 	ctx context.Context,
 	reader client.Reader,
@@ -129,6 +163,13 @@ func (r RequestsManager) RecoverRequestsDelete(
 		return fmt.Errorf("restore requests delete: %w", err)
 	}
 
+	if err := writer.DeleteAllOf(ctx, &corev1.ConfigMap{},
+		client.InNamespace(requestNamespaceName),
+		client.MatchingLabels(labels),
+	); err != nil {
+		return fmt.Errorf("restore resource modifiers delete: %w", err)
+	}
+
 	return r.ProtectRequestsDelete(ctx, writer, requestNamespaceName, labels)
 }
 
@@ -136,6 +177,7 @@ func (RequestsManager) RecoverRequestCreate(
 	ctx context.Context,
 	writer client.Writer,
 	log logr.Logger,
+	s3ProfileName string,
 	s3Url string,
 	s3BucketName string,
 	s3RegionName string,
@@ -268,6 +310,23 @@ func backupRestore(
 	labels map[string]string,
 ) (*velero.Restore, error) {
 	restore := restore(backup.Namespace, restoreName, recoverSpec, backup.Name, labels)
+
+	if len(recoverSpec.Transforms) > 0 {
+		resourceModifiers, err := resourceModifiers(backup.Namespace, restoreName, recoverSpec.Transforms, labels)
+		if err != nil {
+			return nil, err
+		}
+
+		if err := w.objectCreate(resourceModifiers); err != nil {
+			return nil, err
+		}
+
+		restore.Spec.ResourceModifier = &corev1.TypedLocalObjectReference{
+			Kind: "ConfigMap",
+			Name: resourceModifiers.Name,
+		}
+	}
+
 	if err := w.objectCreate(restore); err != nil {
 		return nil, err
 	}
@@ -275,6 +334,55 @@ func backupRestore(
 	return restore, nil
 }
 
+// resourceModifierRule is a Velero resource modifier rule: see
+// https://velero.io/docs/v1.15/restore-resource-modifiers/
+type resourceModifierRule struct {
+	Conditions struct {
+		GroupResource     string                `json:"groupResource"`
+		ResourceNameRegex string                `json:"resourceNameRegex,omitempty"`
+		Namespaces        []string              `json:"namespaces,omitempty"`
+		LabelSelector     *metav1.LabelSelector `json:"labelSelector,omitempty"`
+	} `json:"conditions"`
+	Patches []kubeobjects.Patch `json:"patches"`
+}
+
+type resourceModifierRules struct {
+	Version string                 `json:"version"`
+	Rules   []resourceModifierRule `json:"resourceModifierRules"`
+}
+
+// resourceModifiers returns a config map of the Velero resource modifier rules
+// equivalent to the given transforms, for a restore to reference.
+func resourceModifiers(
+	namespaceName, name string, transforms []kubeobjects.Transform, labels map[string]string,
+) (*corev1.ConfigMap, error) {
+	rules := resourceModifierRules{Version: "v1", Rules: make([]resourceModifierRule, len(transforms))}
+
+	for i, transform := range transforms {
+		rule := &rules.Rules[i]
+		rule.Conditions.GroupResource = transform.GroupResource
+		rule.Conditions.ResourceNameRegex = transform.ResourceNameRegex
+		rule.Conditions.Namespaces = transform.Namespaces
+		rule.Conditions.LabelSelector = transform.LabelSelector
+		rule.Patches = transform.Patches
+	}
+
+	data, err := yaml.Marshal(rules)
+	if err != nil {
+		return nil, fmt.Errorf("resource modifiers encode: %w", err)
+	}
+
+	return &corev1.ConfigMap{
+		TypeMeta: metav1.TypeMeta{APIVersion: "v1", Kind: "ConfigMap"},
+		ObjectMeta: metav1.ObjectMeta{
+			Namespace: namespaceName,
+			Name:      name,
+			Labels:    labels,
+		},
+		Data: map[string]string{"resource-modifiers.yaml": string(data)},
+	}, nil
+}
+
 func restoreStatusProcess(
 	restore *velero.Restore,
 	log logr.Logger,
@@ -304,6 +412,7 @@ func (RequestsManager) ProtectRequestCreate(
 	ctx context.Context,
 	writer client.Writer,
 	log logr.Logger,
+	s3ProfileName string,
 	s3Url string,
 	s3BucketName string,
 	s3RegionName string,
@@ -449,8 +558,17 @@ func (r RestoreRequest) Deallocate(
 	log logr.Logger,
 ) error {
 	backupObjectMeta := metav1.ObjectMeta{Namespace: r.restore.Namespace, Name: r.restore.Spec.BackupName}
+	w := objectWriter{ctx: ctx, Writer: writer, log: log}
+
+	if resourceModifier := r.restore.Spec.ResourceModifier; resourceModifier != nil {
+		if err := w.objectDelete(&corev1.ConfigMap{ObjectMeta: metav1.ObjectMeta{
+			Namespace: r.restore.Namespace, Name: resourceModifier.Name,
+		}}); err != nil {
+			return err
+		}
+	}
 
-	return objectWriter{ctx: ctx, Writer: writer, log: log}.restoreObjectsDelete(
+	return w.restoreObjectsDelete(
 		&velero.BackupStorageLocation{ObjectMeta: backupObjectMeta},
 		&velero.Backup{ObjectMeta: backupObjectMeta},
 		r.restore,
@@ -0,0 +1,65 @@
+// SPDX-FileCopyrightText: The RamenDR authors
+// SPDX-License-Identifier: Apache-2.0
+
+package kubeobjects
+
+import (
+	"slices"
+	"strings"
+)
+
+// RecoverVerification is the result of recovering the objects of a capture
+// with a server-side dry run: the number of objects that would be recovered,
+// the number that cannot be verified as their namespace does not exist, and the
+// failures of those that would not be recovered, sorted by resource.
+type RecoverVerification struct {
+	ObjectCount  int              `json:"objectCount"`
+	Unverifiable int              `json:"unverifiable"`
+	Failures     []RecoverFailure `json:"failures"`
+}
+
+// RecoverFailure counts the objects of a resource that would not be recovered,
+// and describes the error of the first.
+type RecoverFailure struct {
+	Resource string `json:"resource"`
+	Count    int    `json:"count"`
+	Message  string `json:"message"`
+}
+
+// FailureAdd counts the failure of an object of the given resource.
+func (v *RecoverVerification) FailureAdd(resource, message string) {
+	v.failuresAdd(resource, message, 1)
+}
+
+// FailureCount returns the number of objects that would not be recovered.
+func (v RecoverVerification) FailureCount() int {
+	count := 0
+	for _, failure := range v.Failures {
+		count += failure.Count
+	}
+
+	return count
+}
+
+// Add adds the counts of another verification to the verification.
+func (v *RecoverVerification) Add(other RecoverVerification) {
+	v.ObjectCount += other.ObjectCount
+	v.Unverifiable += other.Unverifiable
+
+	for _, failure := range other.Failures {
+		v.failuresAdd(failure.Resource, failure.Message, failure.Count)
+	}
+}
+
+func (v *RecoverVerification) failuresAdd(resource, message string, count int) {
+	for i := range v.Failures {
+		if v.Failures[i].Resource == resource {
+			v.Failures[i].Count += count
+
+			return
+		}
+	}
+
+	v.Failures = append(v.Failures, RecoverFailure{Resource: resource, Count: count, Message: message})
+	slices.SortFunc(v.Failures, func(a, b RecoverFailure) int { return strings.Compare(a.Resource, b.Resource) })
+}
@@ -4,6 +4,10 @@
 package controllers
 
 import (
+	"errors"
+	"time"
+
+	"github.com/aws/aws-sdk-go/aws/awserr"
 	"github.com/prometheus/client_golang/prometheus"
 	rmn "github.com/ramendr/ramen/api/v1alpha1"
 	"sigs.k8s.io/controller-runtime/pkg/metrics"
@@ -24,6 +28,22 @@ const (
 	WorkloadProtectionStatus = "workload_protection_status"
 )
 
+const (
+	S3OperationDurationSeconds    = "s3_operation_duration_seconds"
+	S3OperationErrorsTotal        = "s3_operation_errors_total"
+	S3TransferredBytesTotal       = "s3_transferred_bytes_total"
+	S3LastSuccessTimestampSeconds = "s3_last_success_timestamp_seconds"
+)
+
+const (
+	s3OperationUpload   = "upload"
+	s3OperationDownload = "download"
+	s3OperationList     = "list"
+	s3OperationDelete   = "delete"
+
+	s3ErrorCodeUnknown = "Unknown"
+)
+
 type SyncTimeMetrics struct {
 	LastSyncTime prometheus.Gauge
 }
@@ -56,6 +76,9 @@ const (
 	ObjNamespace       = "obj_namespace"
 	Policyname         = "policyname"
 	SchedulingInterval = "scheduling_interval"
+	S3Profile          = "s3_profile"
+	S3Operation        = "operation"
+	S3ErrorCode        = "error_code"
 )
 
 var (
@@ -90,6 +113,17 @@ var (
 		ObjName,      // Name of the resoure [drpc-name]
 		ObjNamespace, // DRPC namespace
 	}
+
+	s3OperationLabels = []string{
+		S3Profile,   // Name of the S3 store profile
+		S3Operation, // Object store operation [upload|download|list|delete]
+	}
+
+	s3OperationErrorLabels = []string{
+		S3Profile,   // Name of the S3 store profile
+		S3Operation, // Object store operation [upload|download|list|delete]
+		S3ErrorCode, // AWS error code, or Unknown for non-AWS errors
+	}
 )
 
 var (
@@ -137,6 +171,43 @@ var (
 		},
 		workloadProtectionStatusLabels,
 	)
+
+	s3OperationDuration = prometheus.NewHistogramVec(
+		prometheus.HistogramOpts{
+			Name:      S3OperationDurationSeconds,
+			Namespace: metricNamespace,
+			Help:      "Latency of S3 object store operations in seconds",
+			Buckets:   []float64{0.01, 0.05, 0.1, 0.25, 0.5, 1, 2.5, 5, 10, 15},
+		},
+		s3OperationLabels,
+	)
+
+	s3OperationErrors = prometheus.NewCounterVec(
+		prometheus.CounterOpts{
+			Name:      S3OperationErrorsTotal,
+			Namespace: metricNamespace,
+			Help:      "Total number of failed S3 object store operations",
+		},
+		s3OperationErrorLabels,
+	)
+
+	s3TransferredBytes = prometheus.NewCounterVec(
+		prometheus.CounterOpts{
+			Name:      S3TransferredBytesTotal,
+			Namespace: metricNamespace,
+			Help:      "Total bytes uploaded to or downloaded from S3 object stores",
+		},
+		s3OperationLabels,
+	)
+
+	s3LastSuccessTimestamp = prometheus.NewGaugeVec(
+		prometheus.GaugeOpts{
+			Name:      S3LastSuccessTimestampSeconds,
+			Namespace: metricNamespace,
+			Help:      "Timestamp of the last successful S3 object store operation",
+		},
+		s3OperationLabels,
+	)
 )
 
 // lastSyncTime metrics reports value from lastGrpupSyncTime taken from DRPC status
@@ -234,6 +305,52 @@ func DeleteWorkloadProtectionStatusMetric(labels prometheus.Labels) bool {
 	return workloadProtectionStatus.Delete(labels)
 }
 
+// s3 operation metrics report latency, errors, transferred bytes and last
+// success time of the object store accesses made through an S3 profile
+func s3OperationMetricLabels(s3ProfileName, operation string) prometheus.Labels {
+	return prometheus.Labels{
+		S3Profile:   s3ProfileName,
+		S3Operation: operation,
+	}
+}
+
+func s3OperationSucceeded(s3ProfileName, operation string, start time.Time, bytes int64) {
+	labels := s3OperationMetricLabels(s3ProfileName, operation)
+
+	s3OperationDuration.With(labels).Observe(time.Since(start).Seconds())
+	s3LastSuccessTimestamp.With(labels).SetToCurrentTime()
+
+	if bytes > 0 {
+		s3TransferredBytes.With(labels).Add(float64(bytes))
+	}
+}
+
+func s3OperationFailed(s3ProfileName, operation string, start time.Time, err error) {
+	labels := s3OperationMetricLabels(s3ProfileName, operation)
+
+	s3OperationDuration.With(labels).Observe(time.Since(start).Seconds())
+
+	labels[S3ErrorCode] = s3ErrorCode(err)
+	s3OperationErrors.With(labels).Inc()
+}
+
+func s3ErrorCode(err error) string {
+	var aerr awserr.Error
+	if errors.As(err, &aerr) && aerr.Code() != "" {
+		return aerr.Code()
+	}
+
+	if code, ok := azureErrorCode(err); ok {
+		return code
+	}
+
+	if code, ok := gcsErrorCode(err); ok {
+		return code
+	}
+
+	return s3ErrorCodeUnknown
+}
+
 func init() {
 	// Register custom metrics with the global prometheus registry
 	metrics.Registry.MustRegister(dRPolicySyncInterval)
@@ -241,4 +358,8 @@ func init() {
 	metrics.Registry.MustRegister(lastSyncDuration)
 	metrics.Registry.MustRegister(lastSyncDataBytes)
 	metrics.Registry.MustRegister(workloadProtectionStatus)
+	metrics.Registry.MustRegister(s3OperationDuration)
+	metrics.Registry.MustRegister(s3OperationErrors)
+	metrics.Registry.MustRegister(s3TransferredBytes)
+	metrics.Registry.MustRegister(s3LastSuccessTimestamp)
 }
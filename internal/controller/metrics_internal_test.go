@@ -0,0 +1,55 @@
+// SPDX-FileCopyrightText: The RamenDR authors
+// SPDX-License-Identifier: Apache-2.0
+
+package controllers
+
+import (
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/aws/aws-sdk-go/aws/awserr"
+	"github.com/aws/aws-sdk-go/service/s3"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+)
+
+var _ = Describe("S3 operation metrics", func() {
+	const s3ProfileName = "metrics-profile"
+
+	DescribeTable("s3ErrorCode",
+		func(err error, code string) {
+			Expect(s3ErrorCode(err)).To(Equal(code))
+		},
+		Entry("AWS error", awserr.New(s3.ErrCodeNoSuchBucket, "no bucket", nil), s3.ErrCodeNoSuchBucket),
+		Entry("wrapped AWS error",
+			fmt.Errorf("wrapped, %w", awserr.New(s3.ErrCodeNoSuchKey, "no key", nil)), s3.ErrCodeNoSuchKey),
+		Entry("non-AWS error", errors.New("plain"), s3ErrorCodeUnknown),
+	)
+
+	It("records bytes and last success time for successful operations", func() {
+		labels := s3OperationMetricLabels(s3ProfileName, s3OperationUpload)
+		bytesBefore := testutil.ToFloat64(s3TransferredBytes.With(labels))
+
+		s3OperationSucceeded(s3ProfileName, s3OperationUpload, time.Now(), 42)
+
+		Expect(testutil.ToFloat64(s3TransferredBytes.With(labels))).To(Equal(bytesBefore + 42))
+		Expect(testutil.ToFloat64(s3LastSuccessTimestamp.With(labels))).To(BeNumerically(">", 0))
+	})
+
+	It("counts failed operations by error code", func() {
+		labels := prometheus.Labels{
+			S3Profile:   s3ProfileName,
+			S3Operation: s3OperationList,
+			S3ErrorCode: s3.ErrCodeNoSuchBucket,
+		}
+		errorsBefore := testutil.ToFloat64(s3OperationErrors.With(labels))
+
+		s3OperationFailed(s3ProfileName, s3OperationList, time.Now(),
+			awserr.New(s3.ErrCodeNoSuchBucket, "no bucket", nil))
+
+		Expect(testutil.ToFloat64(s3OperationErrors.With(labels))).To(Equal(errorsBefore + 1))
+	})
+})
@@ -0,0 +1,146 @@
+// SPDX-FileCopyrightText: The RamenDR authors
+// SPDX-License-Identifier: Apache-2.0
+
+package controllers
+
+import (
+	"context"
+	"os"
+
+	"github.com/Azure/azure-sdk-for-go/sdk/storage/azblob/bloberror"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	ramen "github.com/ramendr/ramen/api/v1alpha1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// Object stores of the azure and gcs providers are tested against emulators,
+// when their endpoints are set in the environment, e.g.:
+//
+//	docker run -p 10000:10000 mcr.microsoft.com/azure-storage/azurite azurite-blob --blobHost 0.0.0.0
+//	RAMEN_TEST_AZURITE_ENDPOINT=http://127.0.0.1:10000/devstoreaccount1
+//
+//	docker run -p 4443:4443 fsouza/fake-gcs-server -scheme http
+//	RAMEN_TEST_FAKE_GCS_ENDPOINT=http://127.0.0.1:4443/storage/v1/
+const (
+	azuriteEndpointEnvName = "RAMEN_TEST_AZURITE_ENDPOINT"
+	fakeGCSEndpointEnvName = "RAMEN_TEST_FAKE_GCS_ENDPOINT"
+
+	// Well-known Azurite development storage account
+	azuriteAccountName = "devstoreaccount1"
+	azuriteAccountKey  = "Eby8vdM02xNOcqFlqUwJPLlmEtlCDXJ1OUzFT50uSRZ6IFsuFq2UVErCz4I6tq/K1SZFPTOtr/KBHBeksoGMGw=="
+)
+
+var _ = Describe("object store providers", func() {
+	profile := func(provider ramen.ObjectStoreProvider, endpoint string) ramen.S3StoreProfile {
+		return ramen.S3StoreProfile{
+			S3ProfileName:        "object-store-" + string(provider),
+			S3Bucket:             "ramen-test",
+			S3CompatibleEndpoint: endpoint,
+			Provider:             provider,
+		}
+	}
+
+	secret := func(data map[string][]byte) *corev1.Secret {
+		return &corev1.Secret{ObjectMeta: metav1.ObjectMeta{Name: "object-store-secret"}, Data: data}
+	}
+
+	It("rejects an unsupported provider", func() {
+		s3StoreProfile := profile("unsupported", "http://127.0.0.1:1")
+
+		Expect(s3StoreProfileFormatCheck(&s3StoreProfile)).To(MatchError(ContainSubstring(
+			"unsupported object store provider unsupported")))
+
+		_, err := newObjectStore(s3StoreProfile.S3ProfileName, s3StoreProfile, secret(nil))
+		Expect(err).To(MatchError(ContainSubstring("unsupported object store provider unsupported")))
+	})
+
+	It("does not require a gcs endpoint", func() {
+		s3StoreProfile := profile(ramen.ObjectStoreProviderGCS, "")
+		Expect(s3StoreProfileFormatCheck(&s3StoreProfile)).To(Succeed())
+	})
+
+	It("requires azure storage account credentials", func() {
+		s3StoreProfile := profile(ramen.ObjectStoreProviderAzure, "http://127.0.0.1:1")
+
+		_, err := newObjectStore(s3StoreProfile.S3ProfileName, s3StoreProfile, secret(nil))
+		Expect(err).To(MatchError(ContainSubstring("lacks AZURE_STORAGE_ACCOUNT_NAME")))
+	})
+
+	It("requires a gcs service account key without an endpoint", func() {
+		s3StoreProfile := profile(ramen.ObjectStoreProviderGCS, "")
+
+		_, err := newObjectStore(s3StoreProfile.S3ProfileName, s3StoreProfile, secret(nil))
+		Expect(err).To(MatchError(ContainSubstring("lacks GOOGLE_SERVICE_ACCOUNT_KEY")))
+	})
+
+	objectStoreTest := func(objectStore ObjectStorer) {
+		const keyPrefix = "object-store-test/vrg/"
+
+		pv := &corev1.PersistentVolume{ObjectMeta: metav1.ObjectMeta{Name: "pv0"}}
+
+		Expect(UploadPV(objectStore, keyPrefix, pv.Name, *pv)).To(Succeed())
+
+		keys, err := objectStore.ListKeys(keyPrefix)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(keys).To(ContainElement(TypedObjectKey(keyPrefix, pv.Name, *pv)))
+
+		pvs, err := downloadPVs(objectStore, keyPrefix)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(pvs).To(HaveLen(1))
+		Expect(pvs[0].Name).To(Equal(pv.Name))
+
+		Expect(objectStore.DeleteObjectsWithKeyPrefix(keyPrefix)).To(Succeed())
+		Expect(objectStore.DeleteObject(TypedObjectKey(keyPrefix, pv.Name, *pv))).To(Succeed())
+
+		keys, err = objectStore.ListKeys(keyPrefix)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(keys).To(BeEmpty())
+	}
+
+	It("stores objects in azure blob storage", func() {
+		endpoint := os.Getenv(azuriteEndpointEnvName)
+		if endpoint == "" {
+			Skip(azuriteEndpointEnvName + " not set")
+		}
+
+		s3StoreProfile := profile(ramen.ObjectStoreProviderAzure, endpoint)
+
+		objectStore, err := newObjectStore(s3StoreProfile.S3ProfileName, s3StoreProfile, secret(map[string][]byte{
+			"AZURE_STORAGE_ACCOUNT_NAME": []byte(azuriteAccountName),
+			"AZURE_STORAGE_ACCOUNT_KEY":  []byte(azuriteAccountKey),
+		}))
+		Expect(err).ToNot(HaveOccurred())
+
+		azureStore, ok := objectStore.(*azureObjectStore)
+		Expect(ok).To(BeTrue())
+
+		if _, err := azureStore.client.Create(context.TODO(), nil); err != nil {
+			Expect(bloberror.HasCode(err, bloberror.ContainerAlreadyExists)).To(BeTrue())
+		}
+
+		objectStoreTest(objectStore.withCallerTag("test"))
+	})
+
+	It("stores objects in google cloud storage", func() {
+		endpoint := os.Getenv(fakeGCSEndpointEnvName)
+		if endpoint == "" {
+			Skip(fakeGCSEndpointEnvName + " not set")
+		}
+
+		s3StoreProfile := profile(ramen.ObjectStoreProviderGCS, endpoint)
+
+		objectStore, err := newObjectStore(s3StoreProfile.S3ProfileName, s3StoreProfile, secret(nil))
+		Expect(err).ToNot(HaveOccurred())
+
+		gcsStore, ok := objectStore.(*gcsObjectStore)
+		Expect(ok).To(BeTrue())
+
+		if _, err := gcsStore.bucket.Attrs(context.TODO()); err != nil {
+			Expect(gcsStore.bucket.Create(context.TODO(), "ramen-test", nil)).To(Succeed())
+		}
+
+		objectStoreTest(objectStore.withCallerTag("test"))
+	})
+})
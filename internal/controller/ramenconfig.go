@@ -160,8 +160,25 @@ func RamenConfigS3StoreProfilePointerGet(ramenConfig *ramendrv1alpha1.RamenConfi
 }
 
 func s3StoreProfileFormatCheck(s3StoreProfile *ramendrv1alpha1.S3StoreProfile) (err error) {
+	switch objectStoreProvider(s3StoreProfile) {
+	case ramendrv1alpha1.ObjectStoreProviderS3, ramendrv1alpha1.ObjectStoreProviderAzure,
+		ramendrv1alpha1.ObjectStoreProviderGCS:
+	default:
+		return fmt.Errorf("unsupported object store provider %s in s3 profile %s",
+			s3StoreProfile.Provider, s3StoreProfile.S3ProfileName)
+	}
+
+	if err := s3ObjectLockFormatCheck(s3StoreProfile); err != nil {
+		return err
+	}
+
 	s3Endpoint := s3StoreProfile.S3CompatibleEndpoint
 	if s3Endpoint == "" {
+		// GCS endpoint defaults to that of the Google Cloud Storage JSON API
+		if objectStoreProvider(s3StoreProfile) == ramendrv1alpha1.ObjectStoreProviderGCS {
+			return checkS3Bucket(s3StoreProfile)
+		}
+
 		err = fmt.Errorf("s3 endpoint has not been configured in s3 profile %s",
 			s3StoreProfile.S3ProfileName)
 
@@ -176,12 +193,38 @@ func s3StoreProfileFormatCheck(s3StoreProfile *ramendrv1alpha1.S3StoreProfile) (
 		return err
 	}
 
-	s3Bucket := s3StoreProfile.S3Bucket
-	if s3Bucket == "" {
-		err = fmt.Errorf("s3 bucket has not been configured in s3 profile %s",
+	return checkS3Bucket(s3StoreProfile)
+}
+
+func s3ObjectLockFormatCheck(s3StoreProfile *ramendrv1alpha1.S3StoreProfile) error {
+	objectLock := s3StoreProfile.ObjectLock
+	if objectLock == nil {
+		return nil
+	}
+
+	if objectStoreProvider(s3StoreProfile) != ramendrv1alpha1.ObjectStoreProviderS3 {
+		return fmt.Errorf("object lock is not supported by provider %s of s3 profile %s",
+			s3StoreProfile.Provider, s3StoreProfile.S3ProfileName)
+	}
+
+	if objectLock.Mode != ramendrv1alpha1.S3ObjectLockModeGovernance &&
+		objectLock.Mode != ramendrv1alpha1.S3ObjectLockModeCompliance {
+		return fmt.Errorf("invalid object lock mode %s in s3 profile %s",
+			objectLock.Mode, s3StoreProfile.S3ProfileName)
+	}
+
+	if objectLock.RetentionPeriod.Duration <= 0 {
+		return fmt.Errorf("object lock retention period has not been configured in s3 profile %s",
 			s3StoreProfile.S3ProfileName)
+	}
 
-		return err
+	return nil
+}
+
+func checkS3Bucket(s3StoreProfile *ramendrv1alpha1.S3StoreProfile) error {
+	if s3StoreProfile.S3Bucket == "" {
+		return fmt.Errorf("s3 bucket has not been configured in s3 profile %s",
+			s3StoreProfile.S3ProfileName)
 	}
 
 	return nil
@@ -337,3 +380,12 @@ func volSyncDestinationCopyMethodOrDefault(ramenConfig *ramendrv1alpha1.RamenCon
 
 	return ramenConfig.VolSync.DestinationCopyMethod
 }
+
+func kubeObjectProtectionEngineOrDefault(ramenConfig *ramendrv1alpha1.RamenConfig,
+) ramendrv1alpha1.KubeObjectProtectionEngine {
+	if ramenConfig.KubeObjectProtection.Engine == "" {
+		return ramendrv1alpha1.KubeObjectProtectionEngineVelero
+	}
+
+	return ramenConfig.KubeObjectProtection.Engine
+}
@@ -0,0 +1,175 @@
+// SPDX-FileCopyrightText: The RamenDR authors
+// SPDX-License-Identifier: Apache-2.0
+
+package controllers
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+
+	"github.com/go-logr/logr"
+	"github.com/ramendr/ramen/internal/controller/hooks"
+	"github.com/ramendr/ramen/internal/controller/util"
+	recipev1 "github.com/ramendr/recipe/api/v1alpha1"
+	"golang.org/x/exp/slices"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/util/sets"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/builder"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/predicate"
+)
+
+// RecipeReconciler validates Recipes as they are created and updated, rather
+// than only once a VRG executes their workflows, and records whether each is
+// valid in an event of the Recipe. Whether a Recipe is valid with the
+// parameters of a VRG that refers to it is recorded, by the VRG reconciler, in
+// the VRG's RecipeValidated condition.
+type RecipeReconciler struct {
+	client.Client
+	Scheme        *runtime.Scheme
+	Log           logr.Logger
+	eventRecorder *util.EventReporter
+}
+
+// +kubebuilder:rbac:groups=ramendr.openshift.io,resources=recipes,verbs=get;list;watch
+// +kubebuilder:rbac:groups=core,resources=events,verbs=get;create;patch;update
+
+func (r *RecipeReconciler) Reconcile(ctx context.Context, req ctrl.Request) (ctrl.Result, error) {
+	log := r.Log.WithValues("recipe", req.NamespacedName.String(), "rid", util.GetRID())
+	log.Info("reconcile enter")
+
+	defer log.Info("reconcile exit")
+
+	recipe := &recipev1.Recipe{}
+	if err := r.Client.Get(ctx, req.NamespacedName, recipe); err != nil {
+		return ctrl.Result{}, client.IgnoreNotFound(err)
+	}
+
+	err := recipeValidate(ctx, *recipe, nil)
+	if err != nil {
+		log.Info("recipe invalid", "error", err.Error())
+	}
+
+	r.recipeValidatedEventReport(recipe, err)
+
+	return ctrl.Result{}, nil
+}
+
+// recipeValidate returns an error if the given Recipe refers to parameters
+// that are not given, if given any, or if its hooks or workflows, with the
+// parameters expanded, are not valid.
+func recipeValidate(ctx context.Context, recipe recipev1.Recipe, parameters map[string][]string) error {
+	if parameters != nil {
+		missingParameterNames, err := recipeParametersMissing(recipe, parameters)
+		if err != nil {
+			return err
+		}
+
+		if len(missingParameterNames) > 0 {
+			return fmt.Errorf("recipe parameters %v are not specified", missingParameterNames)
+		}
+	}
+
+	// expanded in a copy, since the expansion updates the hooks and groups in place
+	recipe = *recipe.DeepCopy()
+
+	if err := RecipeParametersExpand(ctx, &recipe, parameters, logr.Discard()); err != nil {
+		return fmt.Errorf("parameters expansion error: %w", err)
+	}
+
+	if err := recipeHooksValidate(recipe); err != nil {
+		return fmt.Errorf("hooks validation error: %w", err)
+	}
+
+	if err := recipeWorkflowHooksValidate(recipe); err != nil {
+		return fmt.Errorf("workflow hooks validation error: %w", err)
+	}
+
+	if _, _, err := getCaptureGroups(recipe); err != nil && !errors.Is(err, ErrWorkflowNotFound) {
+		return fmt.Errorf("capture workflow error: %w", err)
+	}
+
+	if _, _, err := getRecoverGroups(recipe); err != nil && !errors.Is(err, ErrWorkflowNotFound) {
+		return fmt.Errorf("recover workflow error: %w", err)
+	}
+
+	return nil
+}
+
+// recipeWorkflowHooksValidate returns an error if a workflow of the given
+// Recipe refers to a hook, or a hook operation or check, that it does not have.
+func recipeWorkflowHooksValidate(recipe recipev1.Recipe) error {
+	for _, workflow := range recipe.Spec.Workflows {
+		for _, step := range workflow.Sequence {
+			name, ok := step["hook"]
+			if !ok {
+				continue
+			}
+
+			hookName, opName, err := validateAndGetHookDetails(name)
+			if err != nil {
+				return fmt.Errorf("workflow %s hook %q: %w", workflow.Name, name, err)
+			}
+
+			hook, err := getHookFromRecipe(&recipe, hookName)
+			if err != nil {
+				return fmt.Errorf("workflow %s hook %q: %w", workflow.Name, name, err)
+			}
+
+			if getHookSpecFromHook(*hook, opName).Name == "" {
+				return fmt.Errorf("workflow %s hook %q: hook %s of type %q has no operation or check %s",
+					workflow.Name, name, hookName, hook.Type, opName)
+			}
+		}
+	}
+
+	return nil
+}
+
+// recipeParametersMissing returns the names of the parameters the given Recipe
+// refers to that are neither given, nor the output of its exec hooks.
+func recipeParametersMissing(recipe recipev1.Recipe, parameters map[string][]string) ([]string, error) {
+	bytes, err := json.Marshal(recipe.Spec)
+	if err != nil {
+		return nil, fmt.Errorf("recipe %s json marshal error: %w", recipe.GetName(), err)
+	}
+
+	outputParameterNames := hooks.ExecHookOutputParameterNames(recipe.GetAnnotations())
+	missingParameterNames := sets.New[string]()
+
+	os.Expand(string(bytes), func(key string) string {
+		if _, ok := parameters[key]; !ok && !slices.Contains(outputParameterNames, key) {
+			missingParameterNames.Insert(key)
+		}
+
+		return ""
+	})
+
+	return sets.List(missingParameterNames), nil
+}
+
+func (r *RecipeReconciler) recipeValidatedEventReport(recipe *recipev1.Recipe, err error) {
+	if err != nil {
+		util.ReportIfNotPresent(r.eventRecorder, recipe, corev1.EventTypeWarning,
+			util.EventReasonRecipeValidationFailed, err.Error())
+
+		return
+	}
+
+	util.ReportIfNotPresent(r.eventRecorder, recipe, corev1.EventTypeNormal,
+		util.EventReasonRecipeValidated, "recipe hooks and workflows are valid")
+}
+
+// SetupWithManager sets up the controller with the Manager.
+func (r *RecipeReconciler) SetupWithManager(mgr ctrl.Manager) error {
+	r.eventRecorder = util.NewEventReporter(mgr.GetEventRecorderFor("controller_Recipe"))
+
+	return ctrl.NewControllerManagedBy(mgr).
+		For(&recipev1.Recipe{}, builder.WithPredicates(predicate.GenerationChangedPredicate{})).
+		Complete(r)
+}
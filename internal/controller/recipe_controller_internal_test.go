@@ -0,0 +1,92 @@
+// SPDX-FileCopyrightText: The RamenDR authors
+// SPDX-License-Identifier: Apache-2.0
+
+package controllers
+
+import (
+	"context"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	"github.com/ramendr/ramen/internal/controller/hooks"
+	recipev1 "github.com/ramendr/recipe/api/v1alpha1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+var _ = Describe("recipeValidate", func() {
+	var recipe recipev1.Recipe
+
+	BeforeEach(func() {
+		recipe = recipev1.Recipe{
+			ObjectMeta: metav1.ObjectMeta{Name: "r", Namespace: "ns"},
+			Spec: recipev1.RecipeSpec{
+				Groups:  []*recipev1.Group{{Name: "resources", Type: "resource", IncludedNamespaces: []string{"$NS"}}},
+				Volumes: &recipev1.Group{Name: "volumes", Type: "volume"},
+				Hooks: []*recipev1.Hook{{
+					Name: "db", Namespace: "${NS}", Type: "exec", NameSelector: "db",
+					Ops:  []*recipev1.Operation{{Name: "quiesce", Command: "/quiesce $MODE"}},
+					Chks: []*recipev1.Check{{Name: "ready", Condition: "true"}},
+				}},
+				Workflows: []*recipev1.Workflow{{
+					Name:     recipev1.BackupWorkflowName,
+					Sequence: []map[string]string{{"hook": "db/quiesce"}, {"group": "resources"}},
+				}},
+			},
+		}
+	})
+
+	It("returns the parameters a recipe refers to that are not given", func() {
+		Expect(recipeParametersMissing(recipe, nil)).To(Equal([]string{"MODE", "NS"}))
+		Expect(recipeParametersMissing(recipe, map[string][]string{"NS": {"app"}})).To(Equal([]string{"MODE"}))
+		Expect(recipeParametersMissing(recipe, map[string][]string{"NS": {"app"}, "MODE": {"fs"}})).To(BeEmpty())
+	})
+
+	It("does not return the output parameters of exec hooks as missing", func() {
+		recipe.Annotations = map[string]string{
+			hooks.ExecHookOutputAnnotationPrefix + "db.quiesce": `{"parameter":"MODE"}`,
+		}
+		Expect(recipeParametersMissing(recipe, map[string][]string{"NS": {"app"}})).To(BeEmpty())
+	})
+
+	It("validates a recipe whose hooks and workflows are valid", func() {
+		Expect(recipeValidate(context.TODO(), recipe, nil)).To(Succeed())
+		Expect(recipeValidate(context.TODO(), recipe, map[string][]string{"NS": {"app"}, "MODE": {"fs"}})).
+			To(Succeed())
+	})
+
+	It("returns an error for parameters that are not given, if given any", func() {
+		Expect(recipeValidate(context.TODO(), recipe, map[string][]string{"NS": {"app"}})).
+			To(MatchError(ContainSubstring("recipe parameters [MODE] are not specified")))
+	})
+
+	It("returns an error for a workflow hook that is not of the form hook/op", func() {
+		recipe.Spec.Workflows[0].Sequence[0]["hook"] = "db"
+		Expect(recipeValidate(context.TODO(), recipe, nil)).To(MatchError(ContainSubstring("invalid format")))
+	})
+
+	It("returns an error for a workflow hook that does not exist", func() {
+		recipe.Spec.Workflows[0].Sequence[0]["hook"] = "cache/quiesce"
+		Expect(recipeValidate(context.TODO(), recipe, nil)).To(MatchError(ContainSubstring("workflow hooks")))
+	})
+
+	It("returns an error for a workflow hook operation that does not exist", func() {
+		recipe.Spec.Workflows[0].Sequence[0]["hook"] = "db/unquiesce"
+		Expect(recipeValidate(context.TODO(), recipe, nil)).
+			To(MatchError(ContainSubstring("has no operation or check unquiesce")))
+	})
+
+	It("returns an error for a check whose condition does not compile", func() {
+		recipe.Spec.Hooks[0].Chks[0].Condition = hooks.CELConditionPrefix + "("
+		Expect(recipeValidate(context.TODO(), recipe, nil)).To(MatchError(ContainSubstring("hooks validation")))
+	})
+
+	It("returns an error for an exec hook annotation that names no hook operation", func() {
+		recipe.Annotations = map[string]string{hooks.ExecHookOutputAnnotationPrefix + "db.quiesced": `{}`}
+		Expect(recipeValidate(context.TODO(), recipe, nil)).To(MatchError(ContainSubstring("names no hook operation")))
+	})
+
+	It("returns an error for a workflow group that does not exist", func() {
+		recipe.Spec.Workflows[0].Sequence[1]["group"] = "config"
+		Expect(recipeValidate(context.TODO(), recipe, nil)).To(MatchError(ContainSubstring("capture workflow")))
+	})
+})
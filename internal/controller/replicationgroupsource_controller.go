@@ -115,6 +115,13 @@ func (r *ReplicationGroupSourceReconciler) Reconcile(ctx context.Context, req ct
 
 	defaultCephFSCSIDriverName := cephFSCSIDriverNameOrDefault(ramenConfig)
 
+	freezer, err := r.volumeGroupFreezer(ctx, vrg, ramenConfig, logger)
+	if err != nil {
+		logger.Error(err, "Failed to get volume group freezer")
+
+		return ctrl.Result{}, err
+	}
+
 	logger.Info("Run ReplicationGroupSource state machine", "DefaultCephFSCSIDriverName", defaultCephFSCSIDriverName)
 	result, err := statemachine.Run(
 		ctx,
@@ -124,6 +131,7 @@ func (r *ReplicationGroupSourceReconciler) Reconcile(ctx context.Context, req ct
 				volSyncDestinationCopyMethodOrDefault(ramenConfig), adminNamespaceVRG,
 			),
 			cephfscg.NewVolumeGroupSourceHandler(r.Client, rgs, defaultCephFSCSIDriverName, logger),
+			freezer,
 			logger,
 		),
 		logger,
@@ -141,6 +149,19 @@ func (r *ReplicationGroupSourceReconciler) Reconcile(ctx context.Context, req ct
 	return result, err
 }
 
+// volumeGroupFreezer returns a freezer of the VRG's recipe's VM freeze hook, or
+// nil if it has none, to freeze the VMs for their volume group snapshot.
+func (r *ReplicationGroupSourceReconciler) volumeGroupFreezer(ctx context.Context,
+	vrg *ramendrv1alpha1.VolumeReplicationGroup, ramenConfig *ramendrv1alpha1.RamenConfig, log logr.Logger,
+) (volsync.Freezer, error) {
+	recipeElements, err := RecipeElementsGet(ctx, r.Client, *vrg, *ramenConfig, log)
+	if err != nil {
+		return nil, err
+	}
+
+	return recipeFreezer(recipeElements, r.APIReader, r.Client, r.Scheme, log), nil
+}
+
 // SetupWithManager sets up the controller with the Manager.
 func (r *ReplicationGroupSourceReconciler) SetupWithManager(mgr ctrl.Manager) error {
 	if util.IsCRDInstalled(context.TODO(), r.APIReader, util.VGSCRDName) {
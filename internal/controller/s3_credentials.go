@@ -0,0 +1,142 @@
+// SPDX-FileCopyrightText: The RamenDR authors
+// SPDX-License-Identifier: Apache-2.0
+
+package controllers
+
+import (
+	"fmt"
+	"net/http"
+	"os"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/aws/credentials"
+	"github.com/aws/aws-sdk-go/aws/credentials/stscreds"
+	"github.com/aws/aws-sdk-go/aws/session"
+	"github.com/aws/aws-sdk-go/service/sts"
+	ramen "github.com/ramendr/ramen/api/v1alpha1"
+	corev1 "k8s.io/api/core/v1"
+)
+
+// s3CredentialsProvider obtains the credentials of the S3 profiles that select
+// it by type.  Credentials are obtained once per connection; providers of
+// short-lived credentials must therefore return credentials that refresh
+// themselves as they expire.
+type s3CredentialsProvider struct {
+	// usesSecret is true if the credentials are read from the secret
+	// referenced by the profile's S3SecretRef, which is then passed to
+	// credentials, watched for changes, and distributed to the DR clusters
+	usesSecret bool
+
+	// credentials returns the credentials of the given profile.  A nil return
+	// selects the default credential chain of the AWS SDK.  httpClient, if not
+	// nil, trusts the profile's CA certificates.
+	credentials func(s3StoreProfile ramen.S3StoreProfile, secret *corev1.Secret,
+		httpClient *http.Client) (*credentials.Credentials, error)
+}
+
+// s3CredentialsProviders are the S3 credentials providers by type.  Register
+// additional credential sources here.
+var s3CredentialsProviders = map[ramen.S3CredentialsProviderType]s3CredentialsProvider{
+	ramen.S3CredentialsProviderStatic: {
+		usesSecret:  true,
+		credentials: s3StaticCredentials,
+	},
+	ramen.S3CredentialsProviderWebIdentity: {
+		credentials: s3WebIdentityCredentials,
+	},
+	ramen.S3CredentialsProviderDefault: {
+		credentials: func(ramen.S3StoreProfile, *corev1.Secret, *http.Client) (*credentials.Credentials, error) {
+			return nil, nil
+		},
+	},
+}
+
+func s3CredentialsProviderType(s3StoreProfile *ramen.S3StoreProfile) ramen.S3CredentialsProviderType {
+	if s3StoreProfile.S3CredentialsProvider == nil || s3StoreProfile.S3CredentialsProvider.Type == "" {
+		return ramen.S3CredentialsProviderStatic
+	}
+
+	return s3StoreProfile.S3CredentialsProvider.Type
+}
+
+func s3CredentialsProviderGet(s3StoreProfile *ramen.S3StoreProfile) (s3CredentialsProvider, error) {
+	providerType := s3CredentialsProviderType(s3StoreProfile)
+
+	provider, ok := s3CredentialsProviders[providerType]
+	if !ok {
+		return provider, fmt.Errorf("unsupported s3 credentials provider type %s in s3 profile %s",
+			providerType, s3StoreProfile.S3ProfileName)
+	}
+
+	return provider, nil
+}
+
+// s3StoreProfileUsesSecret returns true if the credentials of the given profile
+// are read from the secret referenced by its S3SecretRef, as is always the case
+// for non-S3 object stores.  Profiles of unknown credentials provider types are
+// assumed to, so that their secret, if any, is neither left behind nor withheld.
+func s3StoreProfileUsesSecret(s3StoreProfile *ramen.S3StoreProfile) bool {
+	if objectStoreProvider(s3StoreProfile) != ramen.ObjectStoreProviderS3 {
+		return true
+	}
+
+	provider, err := s3CredentialsProviderGet(s3StoreProfile)
+
+	return err != nil || provider.usesSecret
+}
+
+func s3StaticCredentials(_ ramen.S3StoreProfile, secret *corev1.Secret, _ *http.Client,
+) (*credentials.Credentials, error) {
+	return credentials.NewStaticCredentials(string(secret.Data["AWS_ACCESS_KEY_ID"]),
+		string(secret.Data["AWS_SECRET_ACCESS_KEY"]), ""), nil
+}
+
+// s3WebIdentityCredentials returns credentials that assume the profile's IAM
+// role with the web identity token in the profile's token file, which is
+// re-read, as the kubelet rotates it, whenever the credentials expire.
+func s3WebIdentityCredentials(s3StoreProfile ramen.S3StoreProfile, _ *corev1.Secret, httpClient *http.Client,
+) (*credentials.Credentials, error) {
+	webIdentity := s3StoreProfile.S3CredentialsProvider.WebIdentity
+	if webIdentity == nil || webIdentity.RoleARN == "" {
+		return nil, fmt.Errorf("web identity role ARN has not been configured in s3 profile %s",
+			s3StoreProfile.S3ProfileName)
+	}
+
+	tokenFile := webIdentity.TokenFile
+	if tokenFile == "" {
+		tokenFile = os.Getenv("AWS_WEB_IDENTITY_TOKEN_FILE")
+	}
+
+	if tokenFile == "" {
+		return nil, fmt.Errorf("web identity token file has not been configured in s3 profile %s",
+			s3StoreProfile.S3ProfileName)
+	}
+
+	roleSessionName := webIdentity.RoleSessionName
+	if roleSessionName == "" {
+		roleSessionName = s3StoreProfile.S3ProfileName
+	}
+
+	stsConfig := &aws.Config{
+		Region:      aws.String(s3StoreProfile.S3Region),
+		Credentials: credentials.AnonymousCredentials,
+	}
+
+	if webIdentity.STSEndpoint != "" {
+		stsConfig.Endpoint = aws.String(webIdentity.STSEndpoint)
+	}
+
+	if httpClient != nil {
+		stsConfig.HTTPClient = httpClient
+	}
+
+	stsSession, err := session.NewSession(stsConfig)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create sts session for s3 profile %s, %w",
+			s3StoreProfile.S3ProfileName, err)
+	}
+
+	return credentials.NewCredentials(stscreds.NewWebIdentityRoleProviderWithOptions(
+		sts.New(stsSession), webIdentity.RoleARN, roleSessionName, stscreds.FetchTokenPath(tokenFile),
+	)), nil
+}
@@ -0,0 +1,89 @@
+// SPDX-FileCopyrightText: The RamenDR authors
+// SPDX-License-Identifier: Apache-2.0
+
+package controllers
+
+import (
+	"path/filepath"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	ramen "github.com/ramendr/ramen/api/v1alpha1"
+	corev1 "k8s.io/api/core/v1"
+)
+
+var _ = Describe("s3CredentialsProviders", func() {
+	profile := func(provider *ramen.S3CredentialsProvider) *ramen.S3StoreProfile {
+		return &ramen.S3StoreProfile{
+			S3ProfileName:         "s3-credentials-profile",
+			S3Region:              "us-east-1",
+			S3CredentialsProvider: provider,
+		}
+	}
+
+	DescribeTable("s3StoreProfileUsesSecret",
+		func(provider *ramen.S3CredentialsProvider, usesSecret bool) {
+			Expect(s3StoreProfileUsesSecret(profile(provider))).To(Equal(usesSecret))
+		},
+		Entry("unspecified", nil, true),
+		Entry("empty type", &ramen.S3CredentialsProvider{}, true),
+		Entry("static", &ramen.S3CredentialsProvider{Type: ramen.S3CredentialsProviderStatic}, true),
+		Entry("webIdentity", &ramen.S3CredentialsProvider{Type: ramen.S3CredentialsProviderWebIdentity}, false),
+		Entry("default", &ramen.S3CredentialsProvider{Type: ramen.S3CredentialsProviderDefault}, false),
+		Entry("unknown", &ramen.S3CredentialsProvider{Type: "unknown"}, true),
+	)
+
+	It("rejects an unknown provider type", func() {
+		_, err := s3CredentialsProviderGet(profile(&ramen.S3CredentialsProvider{Type: "unknown"}))
+		Expect(err).To(MatchError(ContainSubstring("unsupported s3 credentials provider type unknown")))
+	})
+
+	It("requires a role ARN for web identity", func() {
+		s3StoreProfile := profile(&ramen.S3CredentialsProvider{Type: ramen.S3CredentialsProviderWebIdentity})
+
+		_, err := s3WebIdentityCredentials(*s3StoreProfile, nil, nil)
+		Expect(err).To(MatchError(ContainSubstring("role ARN has not been configured")))
+	})
+
+	It("creates refreshable web identity credentials", func() {
+		s3StoreProfile := profile(&ramen.S3CredentialsProvider{
+			Type: ramen.S3CredentialsProviderWebIdentity,
+			WebIdentity: &ramen.S3WebIdentity{
+				RoleARN:     "arn:aws:iam::123456789012:role/ramen",
+				TokenFile:   filepath.Join(GinkgoT().TempDir(), "token"),
+				STSEndpoint: "http://127.0.0.1:1",
+			},
+		})
+
+		credentials, err := s3WebIdentityCredentials(*s3StoreProfile, nil, nil)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(credentials.IsExpired()).To(BeTrue())
+	})
+
+	DescribeTable("s3SecretKeys",
+		func(providers []ramen.ObjectStoreProvider, secretKeys []string) {
+			ramenConfig := &ramen.RamenConfig{}
+			for _, provider := range providers {
+				ramenConfig.S3StoreProfiles = append(ramenConfig.S3StoreProfiles, ramen.S3StoreProfile{
+					S3ProfileName: "s3-profile-" + string(provider),
+					S3SecretRef:   corev1.SecretReference{Name: "s3-secret"},
+					Provider:      provider,
+				})
+			}
+
+			ramenConfig.S3StoreProfiles = append(ramenConfig.S3StoreProfiles, ramen.S3StoreProfile{
+				S3ProfileName: "s3-profile-other-secret",
+				S3SecretRef:   corev1.SecretReference{Name: "s3-secret-other"},
+				Provider:      ramen.ObjectStoreProviderGCS,
+			})
+
+			Expect(s3SecretKeys("s3-secret", ramenConfig)).To(ConsistOf(secretKeys))
+		},
+		Entry("s3", []ramen.ObjectStoreProvider{ramen.ObjectStoreProviderS3},
+			[]string{"AWS_ACCESS_KEY_ID", "AWS_SECRET_ACCESS_KEY"}),
+		Entry("azure", []ramen.ObjectStoreProvider{ramen.ObjectStoreProviderAzure},
+			[]string{"AZURE_STORAGE_ACCOUNT_NAME", "AZURE_STORAGE_ACCOUNT_KEY"}),
+		Entry("s3 and gcs", []ramen.ObjectStoreProvider{ramen.ObjectStoreProviderS3, ramen.ObjectStoreProviderGCS},
+			[]string{"AWS_ACCESS_KEY_ID", "AWS_SECRET_ACCESS_KEY", "GOOGLE_SERVICE_ACCOUNT_KEY"}),
+	)
+})
@@ -0,0 +1,375 @@
+// SPDX-FileCopyrightText: The RamenDR authors
+// SPDX-License-Identifier: Apache-2.0
+
+package controllers
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"maps"
+	"reflect"
+	"runtime/debug"
+	"slices"
+	"sync"
+
+	ctrl "sigs.k8s.io/controller-runtime"
+)
+
+// s3FormatVersion is the version of the encoding of the typed objects that
+// ramen stores under a VRG's key prefix, and of the manifest describing them.
+// Bump it, and register a migration from the previous version in
+// s3FormatMigrations, whenever a change to a stored type would prevent the
+// current release from decoding objects that an older release uploaded.
+//   - 0: objects stored without a manifest
+//   - 1: manifest with format version, ramen version, inventory and checksums
+//
+// A newer format version is decoded as is; format changes must therefore stay
+// readable by the previous release (e.g. only add fields), so that upgrading
+// ramen on one cluster of a DR pair does not break restores on its peer.
+const s3FormatVersion = 1
+
+// s3ManifestKeySuffix is appended to a VRG's key prefix to form the key of its
+// manifest object.  It is deliberately not derived from the manifest's Go type
+// name, so that the manifest remains locatable across releases.
+const s3ManifestKeySuffix = "ramen-manifest"
+
+// s3Manifest describes the typed objects stored under a VRG's key prefix.
+type s3Manifest struct {
+	// Format version of the manifest itself
+	FormatVersion int `json:"formatVersion"`
+
+	// Version of the ramen release that last updated the manifest
+	RamenVersion string `json:"ramenVersion"`
+
+	// Inventory of stored objects, by object key
+	Objects map[string]s3ManifestObject `json:"objects,omitempty"`
+}
+
+type s3ManifestObject struct {
+	// Go type name of the object, as used in its key
+	Type string `json:"type"`
+
+	// Format version the object was encoded with
+	FormatVersion int `json:"formatVersion"`
+
+	// Checksum of the object's json encoding, in "sha256:<hex>" form
+	Checksum string `json:"checksum"`
+}
+
+// s3FormatMigration converts the json encoding of an object of the named type
+// from the format version it is registered for to the next format version.
+type s3FormatMigration func(typeName string, data []byte) ([]byte, error)
+
+var s3FormatMigrations = map[int]s3FormatMigration{
+	// Version 1 introduced the manifest; object encodings are unchanged
+	0: func(_ string, data []byte) ([]byte, error) { return data, nil },
+}
+
+func s3ManifestKey(keyPrefix string) string {
+	return keyPrefix + s3ManifestKeySuffix
+}
+
+func ramenVersion() string {
+	if buildInfo, ok := debug.ReadBuildInfo(); ok && buildInfo.Main.Version != "" {
+		return buildInfo.Main.Version
+	}
+
+	return "unknown"
+}
+
+func s3ObjectChecksum(data []byte) string {
+	sum := sha256.Sum256(data)
+
+	return "sha256:" + hex.EncodeToString(sum[:])
+}
+
+// s3ManifestDownload returns the manifest stored with the given key prefix.
+// Returns an empty manifest of format version 0 if none is stored, as is the
+// case for objects uploaded by releases that predate the manifest.
+func s3ManifestDownload(s ObjectStorer, keyPrefix string) (*s3Manifest, error) {
+	key := s3ManifestKey(keyPrefix)
+
+	keys, err := s.ListKeys(key)
+	if err != nil {
+		return nil, fmt.Errorf("unable to ListKeys of manifest %s, %w", key, err)
+	}
+
+	manifest := &s3Manifest{}
+
+	if !slices.Contains(keys, key) {
+		return manifest, nil
+	}
+
+	if err := s.DownloadObject(key, manifest); err != nil {
+		return nil, fmt.Errorf("unable to DownloadObject of manifest %s, %w", key, err)
+	}
+
+	return manifest, nil
+}
+
+// s3ManifestGet returns the manifest stored with the given key prefix, once
+// per reconcile if the given ObjectStorer is the reconcile's manifest batch.
+func s3ManifestGet(s ObjectStorer, keyPrefix string) (*s3Manifest, error) {
+	if batch, ok := s.(*s3ManifestBatch); ok {
+		return batch.manifest(keyPrefix)
+	}
+
+	return s3ManifestDownload(s, keyPrefix)
+}
+
+// s3ManifestUpdate applies the given change to the manifest stored with the
+// given key prefix, and uploads it if the change reports that it modified it.
+// The manifest of a VRG's key prefix is only updated by the reconciles of that
+// VRG, which are serialized, on the cluster where it is primary, and therefore
+// has a single writer.
+func s3ManifestUpdate(s ObjectStorer, keyPrefix string, change func(*s3Manifest) bool) error {
+	if batch, ok := s.(*s3ManifestBatch); ok {
+		defer batch.manifestForget(keyPrefix)
+	}
+
+	manifest, err := s3ManifestDownload(s, keyPrefix)
+	if err != nil {
+		return err
+	}
+
+	if !change(manifest) {
+		return nil
+	}
+
+	manifest.FormatVersion = s3FormatVersion
+	manifest.RamenVersion = ramenVersion()
+
+	// The manifest is rewritten by each capture that changes it, and can be
+	// rebuilt, so it is not retained by object lock like the objects it lists
+	upload := s.UploadObject
+	if uploader, ok := s.(s3ObjectUnlockedUploader); ok {
+		upload = uploader.uploadObjectUnlocked
+	}
+
+	if err := upload(s3ManifestKey(keyPrefix), manifest); err != nil {
+		return fmt.Errorf("unable to UploadObject of manifest %s, %w", s3ManifestKey(keyPrefix), err)
+	}
+
+	return nil
+}
+
+// s3ManifestObjectRecord adds to the manifest of the given key prefix the
+// object uploaded with the given key.
+func s3ManifestObjectRecord(s ObjectStorer, keyPrefix, key string, object interface{}) error {
+	data, err := json.Marshal(object)
+	if err != nil {
+		return fmt.Errorf("failed to json encode %s for manifest, %w", key, err)
+	}
+
+	entries := map[string]s3ManifestObject{
+		key: {
+			Type:          reflect.TypeOf(object).String(),
+			FormatVersion: s3FormatVersion,
+			Checksum:      s3ObjectChecksum(data),
+		},
+	}
+
+	if batch, ok := s.(*s3ManifestBatch); ok {
+		batch.record(keyPrefix, entries)
+
+		return nil
+	}
+
+	// An object uploaded on its own is its own batch
+	batch := newS3ManifestBatch(s)
+	batch.record(keyPrefix, entries)
+
+	return batch.flush()
+}
+
+func s3ManifestObjectsRecord(s ObjectStorer, keyPrefix string, entries map[string]s3ManifestObject) error {
+	return s3ManifestUpdate(s, keyPrefix, func(manifest *s3Manifest) bool {
+		changed := false
+
+		for key, entry := range entries {
+			if manifest.Objects[key] == entry {
+				continue
+			}
+
+			if manifest.Objects == nil {
+				manifest.Objects = make(map[string]s3ManifestObject)
+			}
+
+			manifest.Objects[key] = entry
+			changed = true
+		}
+
+		return changed
+	})
+}
+
+// s3ManifestBatch is an ObjectStorer that defers the manifest updates of the
+// typed objects uploaded through it until flushed, so that a capture updates
+// each manifest once, rather than once per object, and that downloads each
+// manifest once for the typed objects downloaded through it.
+type s3ManifestBatch struct {
+	ObjectStorer
+	mutex     sync.Mutex
+	entries   map[string]map[string]s3ManifestObject
+	manifests map[string]*s3Manifest
+}
+
+func newS3ManifestBatch(s ObjectStorer) *s3ManifestBatch {
+	return &s3ManifestBatch{
+		ObjectStorer: s,
+		entries:      make(map[string]map[string]s3ManifestObject),
+		manifests:    make(map[string]*s3Manifest),
+	}
+}
+
+// manifest returns the manifest of the given key prefix, downloaded once, with
+// the entries the batch has yet to record in it.
+func (b *s3ManifestBatch) manifest(keyPrefix string) (*s3Manifest, error) {
+	b.mutex.Lock()
+	defer b.mutex.Unlock()
+
+	manifest, ok := b.manifests[keyPrefix]
+	if !ok {
+		var err error
+
+		manifest, err = s3ManifestDownload(b.ObjectStorer, keyPrefix)
+		if err != nil {
+			return nil, err
+		}
+
+		b.manifests[keyPrefix] = manifest
+	}
+
+	if len(b.entries[keyPrefix]) == 0 {
+		return manifest, nil
+	}
+
+	recorded := *manifest
+	recorded.Objects = make(map[string]s3ManifestObject, len(manifest.Objects)+len(b.entries[keyPrefix]))
+	maps.Copy(recorded.Objects, manifest.Objects)
+	maps.Copy(recorded.Objects, b.entries[keyPrefix])
+
+	return &recorded, nil
+}
+
+func (b *s3ManifestBatch) manifestForget(keyPrefix string) {
+	b.mutex.Lock()
+	defer b.mutex.Unlock()
+
+	delete(b.manifests, keyPrefix)
+}
+
+func (b *s3ManifestBatch) record(keyPrefix string, entries map[string]s3ManifestObject) {
+	b.mutex.Lock()
+	defer b.mutex.Unlock()
+
+	if b.entries[keyPrefix] == nil {
+		b.entries[keyPrefix] = make(map[string]s3ManifestObject)
+	}
+
+	for key, entry := range entries {
+		b.entries[keyPrefix][key] = entry
+	}
+}
+
+// recordIfAbsent records those of the given entries, of a previous batch, that
+// this batch does not record a newer entry for.
+func (b *s3ManifestBatch) recordIfAbsent(entries map[string]map[string]s3ManifestObject) {
+	b.mutex.Lock()
+	defer b.mutex.Unlock()
+
+	for keyPrefix, prefixEntries := range entries {
+		if b.entries[keyPrefix] == nil {
+			b.entries[keyPrefix] = make(map[string]s3ManifestObject, len(prefixEntries))
+		}
+
+		for key, entry := range prefixEntries {
+			if _, ok := b.entries[keyPrefix][key]; !ok {
+				b.entries[keyPrefix][key] = entry
+			}
+		}
+	}
+}
+
+// flush records the deferred entries in the manifests of their key prefixes.
+// Entries that fail to be recorded are retained.
+func (b *s3ManifestBatch) flush() error {
+	b.mutex.Lock()
+	defer b.mutex.Unlock()
+
+	for keyPrefix, entries := range b.entries {
+		if err := s3ManifestObjectsRecord(b.ObjectStorer, keyPrefix, entries); err != nil {
+			return err
+		}
+
+		delete(b.entries, keyPrefix)
+		delete(b.manifests, keyPrefix)
+	}
+
+	return nil
+}
+
+// s3ManifestObjectForget removes from the manifest of the given key prefix the
+// object with the given key.
+func s3ManifestObjectForget(s ObjectStorer, keyPrefix, key string) error {
+	return s3ManifestUpdate(s, keyPrefix, func(manifest *s3Manifest) bool {
+		if _, ok := manifest.Objects[key]; !ok {
+			return false
+		}
+
+		delete(manifest.Objects, key)
+
+		return true
+	})
+}
+
+// downloadManifestedObject downloads the object with the given key, migrates
+// its encoding from the format version the manifest records it was uploaded
+// with, if its checksum matches the manifest's, to the current one, and decodes
+// it into objectPointer.
+func downloadManifestedObject(s ObjectStorer, manifest *s3Manifest, key string, objectPointer interface{},
+) error {
+	var data json.RawMessage
+
+	if err := s.DownloadObject(key, &data); err != nil {
+		return err
+	}
+
+	formatVersion := 0
+
+	if entry, ok := manifest.Objects[key]; ok {
+		// The manifest lags the objects until a reconcile's uploads are
+		// recorded, and older releases rewrite objects without recording
+		// them, so an object that does not match it is decoded as unmanifested
+		if checksum := s3ObjectChecksum(data); checksum != entry.Checksum {
+			ctrl.Log.WithName("s3manifest").Info("Object checksum mismatch, decoding it as format version 0",
+				"key", key, "expected", entry.Checksum, "found", checksum)
+		} else {
+			formatVersion = entry.FormatVersion
+		}
+	}
+
+	typeName := reflect.TypeOf(objectPointer).Elem().String()
+
+	for version := formatVersion; version < s3FormatVersion; version++ {
+		migrate, ok := s3FormatMigrations[version]
+		if !ok {
+			return fmt.Errorf("no migration of %s from format version %d", key, version)
+		}
+
+		migrated, err := migrate(typeName, data)
+		if err != nil {
+			return fmt.Errorf("failed to migrate %s from format version %d, %w", key, version, err)
+		}
+
+		data = migrated
+	}
+
+	if err := json.Unmarshal(data, objectPointer); err != nil {
+		return fmt.Errorf("failed to decode %s, %w", key, err)
+	}
+
+	return nil
+}
@@ -0,0 +1,132 @@
+// SPDX-FileCopyrightText: The RamenDR authors
+// SPDX-License-Identifier: Apache-2.0
+
+package controllers
+
+import (
+	"encoding/json"
+	"strings"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// s3ManifestTestStore is an in-memory ObjectStorer that counts its manifest
+// downloads.
+type s3ManifestTestStore struct {
+	objects           map[string][]byte
+	manifestDownloads int
+}
+
+func (s *s3ManifestTestStore) UploadObject(key string, object interface{}) error {
+	data, err := json.Marshal(object)
+	if err != nil {
+		return err
+	}
+
+	s.objects[key] = data
+
+	return nil
+}
+
+func (s *s3ManifestTestStore) DownloadObject(key string, objectPointer interface{}) error {
+	if strings.HasSuffix(key, s3ManifestKeySuffix) {
+		s.manifestDownloads++
+	}
+
+	return json.Unmarshal(s.objects[key], objectPointer)
+}
+
+func (s *s3ManifestTestStore) ListKeys(keyPrefix string) ([]string, error) {
+	keys := []string{}
+
+	for key := range s.objects {
+		if strings.HasPrefix(key, keyPrefix) {
+			keys = append(keys, key)
+		}
+	}
+
+	return keys, nil
+}
+
+func (s *s3ManifestTestStore) DeleteObject(key string) error {
+	delete(s.objects, key)
+
+	return nil
+}
+
+func (s *s3ManifestTestStore) DeleteObjects(keys ...string) error {
+	for _, key := range keys {
+		delete(s.objects, key)
+	}
+
+	return nil
+}
+
+func (s *s3ManifestTestStore) DeleteObjectsWithKeyPrefix(keyPrefix string) error {
+	keys, _ := s.ListKeys(keyPrefix)
+
+	return s.DeleteObjects(keys...)
+}
+
+var _ = Describe("s3ManifestBatch", func() {
+	const keyPrefix = "ns/vrg/"
+
+	var store *s3ManifestTestStore
+
+	pv := func(name string) corev1.PersistentVolume {
+		return corev1.PersistentVolume{ObjectMeta: metav1.ObjectMeta{Name: name}}
+	}
+
+	BeforeEach(func() {
+		store = &s3ManifestTestStore{objects: map[string][]byte{}}
+		Expect(UploadPV(store, keyPrefix, "pv1", pv("pv1"))).To(Succeed())
+		Expect(UploadPVC(store, keyPrefix, "pvc1", corev1.PersistentVolumeClaim{
+			ObjectMeta: metav1.ObjectMeta{Name: "pvc1"},
+		})).To(Succeed())
+
+		store.manifestDownloads = 0
+	})
+
+	It("downloads a manifest once for the objects downloaded through it", func() {
+		batch := newS3ManifestBatch(store)
+
+		pvs, err := downloadPVs(batch, keyPrefix)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(pvs).To(HaveLen(1))
+
+		pvcs, err := downloadPVCs(batch, keyPrefix)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(pvcs).To(HaveLen(1))
+
+		Expect(store.manifestDownloads).To(Equal(1))
+	})
+
+	It("verifies objects uploaded through it before it is flushed", func() {
+		batch := newS3ManifestBatch(store)
+		_, err := downloadPVs(batch, keyPrefix)
+		Expect(err).ToNot(HaveOccurred())
+
+		Expect(UploadPV(batch, keyPrefix, "pv1", pv("pv1"))).To(Succeed())
+
+		manifest, err := batch.manifest(keyPrefix)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(manifest.Objects).To(HaveKey(TypedObjectKey(keyPrefix, "pv1", corev1.PersistentVolume{})))
+
+		Expect(batch.flush()).To(Succeed())
+		_, err = downloadPVs(batch, keyPrefix)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(store.manifestDownloads).To(Equal(3))
+	})
+
+	It("decodes an object that does not match the manifest as unmanifested", func() {
+		key := TypedObjectKey(keyPrefix, "pv1", corev1.PersistentVolume{})
+		Expect(store.UploadObject(key, pv("pv1-rewritten"))).To(Succeed())
+
+		var downloaded corev1.PersistentVolume
+		Expect(DownloadTypedObject(newS3ManifestBatch(store), keyPrefix, "pv1", &downloaded)).To(Succeed())
+		Expect(downloaded.Name).To(Equal("pv1-rewritten"))
+	})
+})
@@ -0,0 +1,63 @@
+// SPDX-FileCopyrightText: The RamenDR authors
+// SPDX-License-Identifier: Apache-2.0
+
+package controllers_test
+
+import (
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	controllers "github.com/ramendr/ramen/internal/controller"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+var _ = Describe("S3 manifest", func() {
+	const keyPrefix = "s3-manifest/vrg/"
+
+	var objectStorer controllers.ObjectStorer
+
+	pv := func(name string) corev1.PersistentVolume {
+		return corev1.PersistentVolume{ObjectMeta: metav1.ObjectMeta{Name: name}}
+	}
+
+	BeforeEach(func() {
+		objectStorer = objectStorers[objS3ProfileNumber]
+	})
+
+	AfterEach(func() {
+		Expect(objectStorer.DeleteObjectsWithKeyPrefix(keyPrefix)).To(Succeed())
+	})
+
+	It("records uploaded objects and downloads them", func() {
+		Expect(controllers.UploadPV(objectStorer, keyPrefix, "pv1", pv("pv1"))).To(Succeed())
+
+		keys, err := objectStorer.ListKeys(keyPrefix + "ramen-manifest")
+		Expect(err).ToNot(HaveOccurred())
+		Expect(keys).To(HaveLen(1))
+
+		var pvs []corev1.PersistentVolume
+		Expect(controllers.DownloadTypedObjects(objectStorer, keyPrefix, &pvs)).To(Succeed())
+		Expect(pvs).To(HaveLen(1))
+		Expect(pvs[0].Name).To(Equal("pv1"))
+	})
+
+	It("downloads objects uploaded without a manifest", func() {
+		key := controllers.TypedObjectKey(keyPrefix, "pv2", corev1.PersistentVolume{})
+		Expect(objectStorer.UploadObject(key, pv("pv2"))).To(Succeed())
+
+		var downloaded corev1.PersistentVolume
+		Expect(controllers.DownloadTypedObject(objectStorer, keyPrefix, "pv2", &downloaded)).To(Succeed())
+		Expect(downloaded.Name).To(Equal("pv2"))
+	})
+
+	It("downloads an object that does not match its recorded checksum as unmanifested", func() {
+		Expect(controllers.UploadPV(objectStorer, keyPrefix, "pv3", pv("pv3"))).To(Succeed())
+
+		key := controllers.TypedObjectKey(keyPrefix, "pv3", corev1.PersistentVolume{})
+		Expect(objectStorer.UploadObject(key, pv("pv3-modified"))).To(Succeed())
+
+		var downloaded corev1.PersistentVolume
+		Expect(controllers.DownloadTypedObject(objectStorer, keyPrefix, "pv3", &downloaded)).To(Succeed())
+		Expect(downloaded.Name).To(Equal("pv3-modified"))
+	})
+})
@@ -0,0 +1,218 @@
+// SPDX-FileCopyrightText: The RamenDR authors
+// SPDX-License-Identifier: Apache-2.0
+
+package controllers
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/aws/awserr"
+	"github.com/aws/aws-sdk-go/service/s3"
+	"github.com/aws/aws-sdk-go/service/s3/s3manager"
+)
+
+// s3DeleteObjectsMaxKeys is the maximum number of objects that a single S3
+// DeleteObjects request may delete.
+const s3DeleteObjectsMaxKeys = 1000
+
+// s3ObjectsLockedError reports objects whose versions were retained by their
+// deletion because they are under object lock retention or legal hold.
+type s3ObjectsLockedError struct {
+	keys []string
+}
+
+func (e *s3ObjectsLockedError) Error() string {
+	return fmt.Sprintf("versions of %d objects are retained by object lock: %s", len(e.keys),
+		strings.Join(e.keys, ", "))
+}
+
+// s3ObjectUnlockedUploader is implemented by object stores that can upload an
+// object without the object lock retention of their profile.
+type s3ObjectUnlockedUploader interface {
+	uploadObjectUnlocked(key string, uploadContent interface{}) error
+}
+
+// s3ObjectsLocked returns the locked object keys reported by the given error,
+// if it reports any.
+func s3ObjectsLocked(err error) ([]string, bool) {
+	var lockedErr *s3ObjectsLockedError
+	if !errors.As(err, &lockedErr) {
+		return nil, false
+	}
+
+	return lockedErr.keys, true
+}
+
+// objectLockApply sets the object lock retention of the profile, if any, on
+// the given upload.
+func (s *s3ObjectStore) objectLockApply(input *s3manager.UploadInput) {
+	if s.objectLock == nil {
+		return
+	}
+
+	input.ObjectLockMode = aws.String(string(s.objectLock.Mode))
+	input.ObjectLockRetainUntilDate = aws.Time(time.Now().Add(s.objectLock.RetentionPeriod.Duration))
+}
+
+// s3ErrCodeObjectLockConfigurationNotFound is the code of the error returned
+// for the object lock configuration of a bucket without one.
+const s3ErrCodeObjectLockConfigurationNotFound = "ObjectLockConfigurationNotFoundError"
+
+// s3BucketObjectLock caches whether the bucket of an object store, and of its
+// copies for other callers, has object lock enabled.
+type s3BucketObjectLock struct {
+	mutex   sync.Mutex
+	checked bool
+	enabled bool
+}
+
+// bucketObjectLockEnabled returns whether the bucket has object lock enabled.
+// A bucket's object lock configuration is got once, as object lock cannot be
+// disabled once enabled, and is enabled on a bucket only at its creation, or
+// rarely, thereafter.
+func (s *s3ObjectStore) bucketObjectLockEnabled() (bool, error) {
+	s.bucketObjectLock.mutex.Lock()
+	defer s.bucketObjectLock.mutex.Unlock()
+
+	if s.bucketObjectLock.checked {
+		return s.bucketObjectLock.enabled, nil
+	}
+
+	ctx, cancel := context.WithDeadline(context.TODO(), time.Now().Add(s3Timeout))
+	defer cancel()
+
+	output, err := s.client.GetObjectLockConfigurationWithContext(ctx, &s3.GetObjectLockConfigurationInput{
+		Bucket: aws.String(s.s3Bucket),
+	})
+	if err != nil {
+		var aerr awserr.Error
+		if !errors.As(err, &aerr) ||
+			(aerr.Code() != s3ErrCodeObjectLockConfigurationNotFound && aerr.Code() != "NotImplemented") {
+			return false, processAwsError(
+				fmt.Errorf("failed to get object lock configuration of bucket %s", s.s3Bucket), err)
+		}
+	}
+
+	s.bucketObjectLock.checked = true
+	s.bucketObjectLock.enabled = output != nil && output.ObjectLockConfiguration != nil &&
+		aws.StringValue(output.ObjectLockConfiguration.ObjectLockEnabled) == s3.ObjectLockEnabledEnabled
+
+	return s.bucketObjectLock.enabled, nil
+}
+
+// objectsLocked returns an s3ObjectsLockedError reporting those of the given
+// keys whose objects are under retention or legal hold, if any.  The objects
+// are not checked if the bucket does not have object lock enabled.
+func (s *s3ObjectStore) objectsLocked(keys []string) (lockedErr, err error) {
+	enabled, err := s.bucketObjectLockEnabled()
+	if err != nil || !enabled {
+		return nil, err
+	}
+
+	locked := []string{}
+
+	for _, key := range keys {
+		isLocked, err := s.objectLocked(key)
+		if err != nil {
+			return nil, err
+		}
+
+		if isLocked {
+			locked = append(locked, key)
+		}
+	}
+
+	if len(locked) != 0 {
+		lockedErr = &s3ObjectsLockedError{keys: locked}
+	}
+
+	return lockedErr, nil
+}
+
+func (s *s3ObjectStore) objectLocked(key string) (bool, error) {
+	ctx, cancel := context.WithDeadline(context.TODO(), time.Now().Add(s3Timeout))
+	defer cancel()
+
+	head, err := s.client.HeadObjectWithContext(ctx, &s3.HeadObjectInput{
+		Bucket: aws.String(s.s3Bucket),
+		Key:    aws.String(key),
+	})
+	if err != nil {
+		errMsgPrefix := fmt.Errorf("failed to get object lock status of %s:%s", s.s3Bucket, key)
+
+		return false, processAwsError(errMsgPrefix, err)
+	}
+
+	if aws.StringValue(head.ObjectLockLegalHoldStatus) == s3.ObjectLockLegalHoldStatusOn {
+		return true, nil
+	}
+
+	return head.ObjectLockRetainUntilDate != nil && head.ObjectLockRetainUntilDate.After(time.Now()), nil
+}
+
+// deleteObjectVersions deletes all the versions, and delete markers, of all
+// the objects in the bucket.  Versions under object lock retention or legal
+// hold are retained, and reported with an s3ObjectsLockedError.
+func (s *s3ObjectStore) deleteObjectVersions(bucket string) error {
+	ctx, cancel := context.WithDeadline(context.TODO(), time.Now().Add(s3Timeout))
+	defer cancel()
+
+	objects := []*s3.ObjectIdentifier{}
+
+	if err := s.client.ListObjectVersionsPagesWithContext(ctx, &s3.ListObjectVersionsInput{Bucket: &bucket},
+		func(page *s3.ListObjectVersionsOutput, _ bool) bool {
+			for _, version := range page.Versions {
+				objects = append(objects, &s3.ObjectIdentifier{Key: version.Key, VersionId: version.VersionId})
+			}
+
+			for _, marker := range page.DeleteMarkers {
+				objects = append(objects, &s3.ObjectIdentifier{Key: marker.Key, VersionId: marker.VersionId})
+			}
+
+			return true
+		},
+	); err != nil {
+		return processAwsError(fmt.Errorf("failed to list object versions in bucket %s", bucket), err)
+	}
+
+	locked := []string{}
+
+	for start := 0; start < len(objects); start += s3DeleteObjectsMaxKeys {
+		output, err := s.client.DeleteObjectsWithContext(ctx, &s3.DeleteObjectsInput{
+			Bucket: &bucket,
+			Delete: &s3.Delete{
+				Objects: objects[start:min(start+s3DeleteObjectsMaxKeys, len(objects))],
+				Quiet:   aws.Bool(true),
+			},
+		})
+		if err != nil {
+			return processAwsError(fmt.Errorf("failed to delete object versions in bucket %s", bucket), err)
+		}
+
+		for _, deleteErr := range output.Errors {
+			// Deletion of a version under retention or legal hold is denied
+			if aws.StringValue(deleteErr.Code) != "AccessDenied" {
+				return fmt.Errorf("failed to delete object %s version %s in bucket %s: code: %s, message: %s",
+					aws.StringValue(deleteErr.Key), aws.StringValue(deleteErr.VersionId), bucket,
+					aws.StringValue(deleteErr.Code), aws.StringValue(deleteErr.Message))
+			}
+
+			locked = append(locked, aws.StringValue(deleteErr.Key))
+		}
+	}
+
+	if len(locked) != 0 {
+		slices.Sort(locked)
+
+		return &s3ObjectsLockedError{keys: slices.Compact(locked)}
+	}
+
+	return nil
+}
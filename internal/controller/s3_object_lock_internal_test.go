@@ -0,0 +1,180 @@
+// SPDX-FileCopyrightText: The RamenDR authors
+// SPDX-License-Identifier: Apache-2.0
+
+package controllers
+
+import (
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"time"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	ramen "github.com/ramendr/ramen/api/v1alpha1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+var _ = Describe("S3 object lock", func() {
+	objectLock := func(mode ramen.S3ObjectLockMode, period time.Duration) *ramen.S3StoreProfile {
+		return &ramen.S3StoreProfile{
+			S3ProfileName:        "s3-object-lock",
+			S3Bucket:             "bucket",
+			S3CompatibleEndpoint: "http://127.0.0.1:1",
+			ObjectLock:           &ramen.S3ObjectLock{Mode: mode, RetentionPeriod: metav1.Duration{Duration: period}},
+		}
+	}
+
+	DescribeTable("s3StoreProfileFormatCheck",
+		func(s3StoreProfile *ramen.S3StoreProfile, errSubstring string) {
+			err := s3StoreProfileFormatCheck(s3StoreProfile)
+			if errSubstring == "" {
+				Expect(err).ToNot(HaveOccurred())
+
+				return
+			}
+
+			Expect(err).To(MatchError(ContainSubstring(errSubstring)))
+		},
+		Entry("governance", objectLock(ramen.S3ObjectLockModeGovernance, time.Hour), ""),
+		Entry("compliance", objectLock(ramen.S3ObjectLockModeCompliance, time.Hour), ""),
+		Entry("invalid mode", objectLock("LEGAL", time.Hour), "invalid object lock mode LEGAL"),
+		Entry("no retention period", objectLock(ramen.S3ObjectLockModeCompliance, 0), "retention period"),
+		Entry("azure", func() *ramen.S3StoreProfile {
+			s3StoreProfile := objectLock(ramen.S3ObjectLockModeCompliance, time.Hour)
+			s3StoreProfile.Provider = ramen.ObjectStoreProviderAzure
+
+			return s3StoreProfile
+		}(), "not supported by provider azure"),
+	)
+
+	It("reports locked keys", func() {
+		err := fmt.Errorf("delete failed, %w", &s3ObjectsLockedError{keys: []string{"a", "b"}})
+
+		keys, locked := s3ObjectsLocked(err)
+		Expect(locked).To(BeTrue())
+		Expect(keys).To(Equal([]string{"a", "b"}))
+
+		_, locked = s3ObjectsLocked(fmt.Errorf("delete failed"))
+		Expect(locked).To(BeFalse())
+	})
+
+	// s3 serves a bucket with an unlocked and a locked object, and records the
+	// bodies of delete requests, the object lock headers of uploads, and the
+	// numbers of object lock configuration gets and of heads
+	type s3 struct {
+		mutex              sync.Mutex
+		objectLockDisabled bool
+		deleted            []string
+		uploadModes        []string
+		objectLockGets     int
+		heads              int
+	}
+
+	s3Server := func(s *s3) *httptest.Server {
+		return httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			s.mutex.Lock()
+			defer s.mutex.Unlock()
+
+			switch {
+			case r.Method == http.MethodGet && r.URL.Query().Get("list-type") == "2":
+				fmt.Fprint(w, `<ListBucketResult><IsTruncated>false</IsTruncated>`+
+					`<Contents><Key>vrg/unlocked</Key></Contents>`+
+					`<Contents><Key>vrg/locked</Key></Contents></ListBucketResult>`)
+			case r.Method == http.MethodGet && r.URL.Query().Has("object-lock"):
+				s.objectLockGets++
+				if s.objectLockDisabled {
+					w.WriteHeader(http.StatusNotFound)
+					fmt.Fprint(w, `<Error><Code>ObjectLockConfigurationNotFoundError</Code></Error>`)
+
+					return
+				}
+
+				fmt.Fprint(w, `<ObjectLockConfiguration><ObjectLockEnabled>Enabled</ObjectLockEnabled>`+
+					`</ObjectLockConfiguration>`)
+			case r.Method == http.MethodHead && strings.HasSuffix(r.URL.Path, "/locked"):
+				s.heads++
+				w.Header().Set("x-amz-object-lock-mode", "COMPLIANCE")
+				w.Header().Set("x-amz-object-lock-retain-until-date",
+					time.Now().Add(time.Hour).UTC().Format(time.RFC3339))
+			case r.Method == http.MethodHead:
+				s.heads++
+			case r.Method == http.MethodPost && r.URL.Query().Has("delete"):
+				body, _ := io.ReadAll(r.Body)
+				s.deleted = append(s.deleted, string(body))
+				fmt.Fprint(w, `<DeleteResult></DeleteResult>`)
+			case r.Method == http.MethodPut:
+				s.uploadModes = append(s.uploadModes, r.Header.Get("x-amz-object-lock-mode"))
+			default:
+				w.WriteHeader(http.StatusNotImplemented)
+			}
+		}))
+	}
+
+	objectStore := func(url string) *s3ObjectStore {
+		s3StoreProfile := objectLock(ramen.S3ObjectLockModeCompliance, time.Hour)
+		s3StoreProfile.S3CompatibleEndpoint = url
+		s3StoreProfile.S3Region = "us-east-1"
+
+		provider, err := s3CredentialsProviderGet(s3StoreProfile)
+		Expect(err).ToNot(HaveOccurred())
+
+		s, err := newS3ObjectStore(s3StoreProfile.S3ProfileName, *s3StoreProfile, provider, &corev1.Secret{
+			Data: map[string][]byte{"AWS_ACCESS_KEY_ID": []byte("id"), "AWS_SECRET_ACCESS_KEY": []byte("key")},
+		})
+		Expect(err).ToNot(HaveOccurred())
+
+		return s
+	}
+
+	It("hides locked objects and reports their retained versions when deleting with a key prefix", func() {
+		s := &s3{}
+		server := s3Server(s)
+		DeferCleanup(server.Close)
+
+		keys, locked := s3ObjectsLocked(objectStore(server.URL).DeleteObjectsWithKeyPrefix("vrg/"))
+		Expect(locked).To(BeTrue())
+		Expect(keys).To(Equal([]string{"vrg/locked"}))
+		Expect(s.deleted).To(HaveLen(1))
+		Expect(s.deleted[0]).To(ContainSubstring("<Key>vrg/unlocked</Key>"))
+		Expect(s.deleted[0]).To(ContainSubstring("<Key>vrg/locked</Key>"))
+		Expect(s.deleted[0]).ToNot(ContainSubstring("<VersionId>"))
+		Expect(s.heads).To(Equal(2))
+	})
+
+	It("checks objects only if their bucket has object lock enabled, which it gets once", func() {
+		s := &s3{objectLockDisabled: true}
+		server := s3Server(s)
+		DeferCleanup(server.Close)
+
+		objectStore := objectStore(server.URL)
+		Expect(objectStore.DeleteObjectsWithKeyPrefix("vrg/")).To(Succeed())
+		Expect(objectStore.withCallerTag("other").DeleteObjectsWithKeyPrefix("vrg/")).To(Succeed())
+		Expect(s.deleted).To(HaveLen(2))
+		Expect(s.heads).To(BeZero())
+		Expect(s.objectLockGets).To(Equal(1))
+	})
+
+	It("uploads manifests without retention", func() {
+		s := &s3{}
+		server := s3Server(s)
+		DeferCleanup(server.Close)
+
+		Expect(s3ManifestUpdate(objectStore(server.URL), "vrg/", func(*s3Manifest) bool { return true })).To(
+			Succeed())
+		Expect(s.uploadModes).To(Equal([]string{""}))
+	})
+
+	It("uploads objects with the retention mode of the profile", func() {
+		s := &s3{}
+		server := s3Server(s)
+		DeferCleanup(server.Close)
+
+		Expect(objectStore(server.URL).UploadObject("vrg/object", "content")).To(Succeed())
+		Expect(s.uploadModes).To(Equal([]string{"COMPLIANCE"}))
+	})
+})
@@ -36,7 +36,8 @@ func s3StoreAccessorsGet(
 			return nil
 		}
 
-		if s3StoreProfile.VeleroNamespaceSecretKeyRef == nil {
+		// Without a secret, velero uses the credentials of its own service account
+		if s3StoreProfile.VeleroNamespaceSecretKeyRef == nil && s3StoreProfileUsesSecret(&s3StoreProfile) {
 			s3StoreProfile.VeleroNamespaceSecretKeyRef = &v1.SecretKeySelector{
 				Key: util.VeleroSecretKeyNameDefault,
 				LocalObjectReference: v1.LocalObjectReference{
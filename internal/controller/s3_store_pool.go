@@ -0,0 +1,387 @@
+// SPDX-FileCopyrightText: The RamenDR authors
+// SPDX-License-Identifier: Apache-2.0
+
+package controllers
+
+import (
+	"context"
+	"crypto/tls"
+	"crypto/x509"
+	"fmt"
+	"net/http"
+	"reflect"
+	"sync"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/aws/session"
+	"github.com/aws/aws-sdk-go/service/s3"
+	"github.com/aws/aws-sdk-go/service/s3/s3manager"
+	"github.com/go-logr/logr"
+	ramen "github.com/ramendr/ramen/api/v1alpha1"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/types"
+	toolscache "k8s.io/client-go/tools/cache"
+	"sigs.k8s.io/controller-runtime/pkg/cache"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+// s3ObjectStorePool is an ObjectStoreGetter that shares S3 object store
+// connections, keyed by S3 profile name, across the reconciles of all the
+// controllers of this process.  A pooled connection is rebuilt when its S3
+// profile, including its CA certificates, or its secret changes.
+//
+// Until watches are registered by S3ObjectStorePoolWatch(), each ObjectStore()
+// call reads the S3 profile and secret to detect changes; once registered, the
+// pool is invalidated by the watch events instead, and pooled connections are
+// returned without reading either.
+type s3ObjectStorePool struct {
+	mutex   sync.Mutex
+	entries map[string]*s3ObjectStorePoolEntry
+	watched bool
+
+	// generation is incremented on every invalidation, so that a connection
+	// built from a profile or secret read before an invalidation is not pooled
+	generation uint64
+}
+
+type s3ObjectStorePoolEntry struct {
+	objectStore   poolableObjectStorer
+	profile       ramen.S3StoreProfile
+	secretName    types.NamespacedName
+	secretVersion string
+}
+
+// poolableObjectStorer is an ObjectStorer whose connection may be shared by
+// multiple callers.
+type poolableObjectStorer interface {
+	ObjectStorer
+
+	// withCallerTag returns a copy of the object store, sharing its
+	// connection, that reports the given caller in its errors.
+	withCallerTag(callerTag string) ObjectStorer
+}
+
+// closableObjectStorer is implemented by pooled object stores whose connection
+// must be closed once it is no longer pooled.
+type closableObjectStorer interface {
+	close() error
+}
+
+var s3ObjectStorePoolInstance = &s3ObjectStorePool{
+	entries: make(map[string]*s3ObjectStorePoolEntry),
+}
+
+// S3ObjectStorePoolWatch registers, with the given informers, watches of the
+// S3 secrets and the ramen ConfigMap that invalidate the pooled S3 object store
+// connections of the process-wide pool returned by S3ObjectStoreGetter().
+func S3ObjectStorePoolWatch(ctx context.Context, informers cache.Informers) error {
+	return s3ObjectStorePoolInstance.watch(ctx, informers)
+}
+
+func (p *s3ObjectStorePool) watch(ctx context.Context, informers cache.Informers) error {
+	secretInformer, err := informers.GetInformer(ctx, &corev1.Secret{})
+	if err != nil {
+		return fmt.Errorf("failed to get secret informer, %w", err)
+	}
+
+	if _, err := secretInformer.AddEventHandler(p.eventHandler(p.secretChanged)); err != nil {
+		return fmt.Errorf("failed to add secret event handler, %w", err)
+	}
+
+	configMapInformer, err := informers.GetInformer(ctx, &corev1.ConfigMap{})
+	if err != nil {
+		return fmt.Errorf("failed to get configmap informer, %w", err)
+	}
+
+	if _, err := configMapInformer.AddEventHandler(p.eventHandler(p.configMapChanged)); err != nil {
+		return fmt.Errorf("failed to add configmap event handler, %w", err)
+	}
+
+	p.mutex.Lock()
+	defer p.mutex.Unlock()
+
+	p.watched = true
+
+	return nil
+}
+
+func (p *s3ObjectStorePool) eventHandler(changed func(client.Object)) toolscache.ResourceEventHandler {
+	objectChanged := func(object interface{}) {
+		if tombstone, ok := object.(toolscache.DeletedFinalStateUnknown); ok {
+			object = tombstone.Obj
+		}
+
+		if clientObject, ok := object.(client.Object); ok {
+			changed(clientObject)
+		}
+	}
+
+	return toolscache.ResourceEventHandlerFuncs{
+		AddFunc:    objectChanged,
+		UpdateFunc: func(_, object interface{}) { objectChanged(object) },
+		DeleteFunc: objectChanged,
+	}
+}
+
+func (p *s3ObjectStorePool) secretChanged(secret client.Object) {
+	p.invalidate(func(entry *s3ObjectStorePoolEntry) bool {
+		return entry.secretName == client.ObjectKeyFromObject(secret)
+	})
+}
+
+func (p *s3ObjectStorePool) configMapChanged(configMap client.Object) {
+	if configMap.GetNamespace() != RamenOperatorNamespace() ||
+		(configMap.GetName() != HubOperatorConfigMapName && configMap.GetName() != DrClusterOperatorConfigMapName) {
+		return
+	}
+
+	p.invalidate(func(*s3ObjectStorePoolEntry) bool { return true })
+}
+
+func (p *s3ObjectStorePool) invalidate(matches func(*s3ObjectStorePoolEntry) bool) {
+	p.mutex.Lock()
+	defer p.mutex.Unlock()
+
+	p.generation++
+
+	for s3ProfileName, entry := range p.entries {
+		if matches(entry) {
+			delete(p.entries, s3ProfileName)
+			entry.close()
+		}
+	}
+}
+
+// pooled returns the pooled entry of the given S3 profile if it is known to be
+// current without reading the S3 profile, and the pool generation otherwise.
+func (p *s3ObjectStorePool) pooled(s3ProfileName string) (*s3ObjectStorePoolEntry, uint64) {
+	p.mutex.Lock()
+	defer p.mutex.Unlock()
+
+	if entry, ok := p.entries[s3ProfileName]; ok && p.watched {
+		return entry, p.generation
+	}
+
+	return nil, p.generation
+}
+
+// pooledEqual returns the pooled entry of the given S3 profile if it was
+// established with the same profile and secret as the given entry.
+func (p *s3ObjectStorePool) pooledEqual(s3ProfileName string, entry *s3ObjectStorePoolEntry,
+) *s3ObjectStorePoolEntry {
+	p.mutex.Lock()
+	defer p.mutex.Unlock()
+
+	if pooled, ok := p.entries[s3ProfileName]; ok && pooled.equal(entry) {
+		return pooled
+	}
+
+	return nil
+}
+
+// pool pools the given entry, unless the pool was invalidated since the given
+// generation, and returns the entry pooled for its S3 profile, which is a
+// previously pooled equal entry if one was established concurrently.
+func (p *s3ObjectStorePool) pool(s3ProfileName string, entry *s3ObjectStorePoolEntry, generation uint64,
+) *s3ObjectStorePoolEntry {
+	p.mutex.Lock()
+	defer p.mutex.Unlock()
+
+	pooled, ok := p.entries[s3ProfileName]
+	if ok && pooled.equal(entry) {
+		entry.close()
+
+		return pooled
+	}
+
+	if generation == p.generation {
+		if ok {
+			pooled.close()
+		}
+
+		p.entries[s3ProfileName] = entry
+	}
+
+	return entry
+}
+
+// close closes the connection of the entry's object store, if it must be.
+func (e *s3ObjectStorePoolEntry) close() {
+	if closable, ok := e.objectStore.(closableObjectStorer); ok {
+		_ = closable.close()
+	}
+}
+
+func (e *s3ObjectStorePoolEntry) equal(other *s3ObjectStorePoolEntry) bool {
+	return e.secretVersion == other.secretVersion &&
+		e.secretName == other.secretName &&
+		reflect.DeepEqual(e.profile, other.profile)
+}
+
+// ObjectStore returns an S3 object store that satisfies the ObjectStorer
+// interface, reusing the pooled connection of the given s3 profile if neither
+// the profile nor its secret changed since it was established.  Returns an
+// error if s3 profile does not exists, secret is not configured, or if client
+// session creation fails.
+func (p *s3ObjectStorePool) ObjectStore(ctx context.Context,
+	r client.Reader, s3ProfileName string,
+	callerTag string, log logr.Logger,
+) (ObjectStorer, ramen.S3StoreProfile, error) {
+	entry, generation := p.pooled(s3ProfileName)
+	if entry != nil {
+		return entry.objectStore.withCallerTag(callerTag), entry.profile, nil
+	}
+
+	s3StoreProfile, err := GetRamenConfigS3StoreProfile(ctx, r, s3ProfileName)
+	if err != nil {
+		return nil, s3StoreProfile, fmt.Errorf("failed to get profile %s for caller %s, %w",
+			s3ProfileName, callerTag, err)
+	}
+
+	entry = &s3ObjectStorePoolEntry{profile: s3StoreProfile}
+
+	var secret *corev1.Secret
+
+	if s3StoreProfileUsesSecret(&s3StoreProfile) {
+		secret, err = getS3SecretObject(ctx, r, s3StoreProfile.S3SecretRef)
+		if err != nil {
+			return nil, s3StoreProfile, fmt.Errorf("failed to get secret %v for caller %s, %w",
+				s3StoreProfile.S3SecretRef, callerTag, err)
+		}
+
+		entry.secretName = client.ObjectKeyFromObject(secret)
+		entry.secretVersion = secret.ResourceVersion
+	}
+
+	if pooled := p.pooledEqual(s3ProfileName, entry); pooled != nil {
+		return pooled.objectStore.withCallerTag(callerTag), s3StoreProfile, nil
+	}
+
+	entry.objectStore, err = newObjectStore(s3ProfileName, s3StoreProfile, secret)
+	if err != nil {
+		return nil, s3StoreProfile, fmt.Errorf("failed to create new session for %s for caller %s, %w",
+			s3StoreProfile.S3CompatibleEndpoint, callerTag, err)
+	}
+
+	log.Info("S3 object store connection established", "profile", s3ProfileName)
+
+	return p.pool(s3ProfileName, entry, generation).objectStore.withCallerTag(callerTag), s3StoreProfile, nil
+}
+
+// newObjectStore creates an object store of the provider of the given profile.
+func newObjectStore(s3ProfileName string, s3StoreProfile ramen.S3StoreProfile, secret *corev1.Secret,
+) (poolableObjectStorer, error) {
+	switch objectStoreProvider(&s3StoreProfile) {
+	case ramen.ObjectStoreProviderS3:
+		provider, err := s3CredentialsProviderGet(&s3StoreProfile)
+		if err != nil {
+			return nil, err
+		}
+
+		return newS3ObjectStore(s3ProfileName, s3StoreProfile, provider, secret)
+	case ramen.ObjectStoreProviderAzure:
+		return newAzureObjectStore(s3ProfileName, s3StoreProfile, secret)
+	case ramen.ObjectStoreProviderGCS:
+		return newGCSObjectStore(s3ProfileName, s3StoreProfile, secret)
+	default:
+		return nil, fmt.Errorf("unsupported object store provider %s in s3 profile %s",
+			s3StoreProfile.Provider, s3ProfileName)
+	}
+}
+
+func objectStoreProvider(s3StoreProfile *ramen.S3StoreProfile) ramen.ObjectStoreProvider {
+	if s3StoreProfile.Provider == "" {
+		return ramen.ObjectStoreProviderS3
+	}
+
+	return s3StoreProfile.Provider
+}
+
+// newS3ObjectStore creates an S3 client session, with a downloader and an
+// uploader, to the endpoint and bucket of the given S3 profile, authenticated
+// with the credentials of the given provider.
+func newS3ObjectStore(s3ProfileName string, s3StoreProfile ramen.S3StoreProfile,
+	provider s3CredentialsProvider, secret *corev1.Secret,
+) (*s3ObjectStore, error) {
+	s3Endpoint := s3StoreProfile.S3CompatibleEndpoint
+	s3Region := s3StoreProfile.S3Region
+
+	s3Config := &aws.Config{
+		Endpoint:         aws.String(s3Endpoint),
+		Region:           aws.String(s3Region),
+		DisableSSL:       aws.Bool(true),
+		S3ForcePathStyle: aws.Bool(true),
+	}
+
+	if len(s3StoreProfile.CACertificates) != 0 {
+		httpClient, err := s3HTTPClient(s3StoreProfile.CACertificates)
+		if err != nil {
+			return nil, err
+		}
+
+		s3Config.HTTPClient = httpClient
+	}
+
+	s3Credentials, err := provider.credentials(s3StoreProfile, secret, s3Config.HTTPClient)
+	if err != nil {
+		return nil, err
+	}
+
+	s3Config.Credentials = s3Credentials
+
+	// Create an S3 client session
+	s3Session, err := session.NewSession(s3Config)
+	if err != nil {
+		return nil, err
+	}
+
+	// Create a client session
+	s3Client := s3.New(s3Session)
+
+	// Also create S3 uploader and S3 downloader which can be safely used
+	// concurrently across goroutines, whereas, the s3 client session
+	// does not support concurrent writers.
+	return &s3ObjectStore{
+		session:      s3Session,
+		client:       s3Client,
+		uploader:     s3manager.NewUploaderWithClient(s3Client),
+		downloader:   s3manager.NewDownloaderWithClient(s3Client),
+		batchDeleter: s3manager.NewBatchDeleteWithClient(s3Client),
+		s3Endpoint:   s3Endpoint,
+		s3Bucket:     s3StoreProfile.S3Bucket,
+		name:         s3ProfileName,
+		objectLock:   s3StoreProfile.ObjectLock,
+
+		bucketObjectLock: &s3BucketObjectLock{},
+	}, nil
+}
+
+// s3HTTPClient returns an HTTP client that trusts the given CA certificates in
+// addition to the system's.
+func s3HTTPClient(caCertificates []byte) (*http.Client, error) {
+	rootCAs, err := x509.SystemCertPool()
+	if err != nil {
+		rootCAs = x509.NewCertPool()
+	}
+
+	if !rootCAs.AppendCertsFromPEM(caCertificates) {
+		return nil, fmt.Errorf("failed to parse CA certificates")
+	}
+
+	transport, ok := http.DefaultTransport.(*http.Transport)
+	if !ok {
+		return nil, fmt.Errorf("unexpected default HTTP transport type %T", http.DefaultTransport)
+	}
+
+	transport = transport.Clone()
+	transport.TLSClientConfig = &tls.Config{RootCAs: rootCAs, MinVersion: tls.VersionTLS12}
+
+	return &http.Client{Transport: transport}, nil
+}
+
+func (s *s3ObjectStore) withCallerTag(callerTag string) ObjectStorer {
+	s1 := *s
+	s1.callerTag = callerTag
+
+	return &s1
+}
@@ -0,0 +1,129 @@
+// SPDX-FileCopyrightText: The RamenDR authors
+// SPDX-License-Identifier: Apache-2.0
+
+package controllers
+
+import (
+	"context"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	ramen "github.com/ramendr/ramen/api/v1alpha1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+	"sigs.k8s.io/yaml"
+)
+
+var _ = Describe("s3ObjectStorePool", func() {
+	const profileName = "s3-pool-profile"
+
+	var (
+		ctx       context.Context
+		pool      *s3ObjectStorePool
+		secret    *corev1.Secret
+		configMap *corev1.ConfigMap
+		k8sClient client.Client
+	)
+
+	objectStore := func() *s3ObjectStore {
+		s, _, err := pool.ObjectStore(ctx, k8sClient, profileName, "test", GinkgoLogr)
+		Expect(err).ToNot(HaveOccurred())
+
+		objectStore, ok := s.(*s3ObjectStore)
+		Expect(ok).To(BeTrue())
+
+		return objectStore
+	}
+
+	BeforeEach(func() {
+		ctx = context.TODO()
+		pool = &s3ObjectStorePool{entries: make(map[string]*s3ObjectStorePoolEntry)}
+
+		secret = &corev1.Secret{
+			ObjectMeta: metav1.ObjectMeta{Name: "s3-pool-secret", Namespace: RamenOperatorNamespace()},
+			Data: map[string][]byte{
+				"AWS_ACCESS_KEY_ID":     []byte("id"),
+				"AWS_SECRET_ACCESS_KEY": []byte("key"),
+			},
+		}
+
+		ramenConfig := &ramen.RamenConfig{
+			S3StoreProfiles: []ramen.S3StoreProfile{{
+				S3ProfileName:        profileName,
+				S3Bucket:             "bucket",
+				S3CompatibleEndpoint: "http://192.168.39.223:30000",
+				S3Region:             "us-east-1",
+				S3SecretRef:          corev1.SecretReference{Name: secret.Name},
+			}},
+		}
+		ramenConfigYaml, err := yaml.Marshal(ramenConfig)
+		Expect(err).ToNot(HaveOccurred())
+
+		configMap = &corev1.ConfigMap{
+			ObjectMeta: metav1.ObjectMeta{Name: DrClusterOperatorConfigMapName, Namespace: RamenOperatorNamespace()},
+			Data:       map[string]string{ConfigMapRamenConfigKeyName: string(ramenConfigYaml)},
+		}
+		if ControllerType == ramen.DRHubType {
+			configMap.Name = HubOperatorConfigMapName
+		}
+
+		scheme := runtime.NewScheme()
+		Expect(corev1.AddToScheme(scheme)).To(Succeed())
+
+		k8sClient = fake.NewClientBuilder().WithScheme(scheme).WithObjects(secret, configMap).Build()
+	})
+
+	It("shares the connection of a profile until its secret changes", func() {
+		s1 := objectStore()
+		Expect(objectStore().session).To(BeIdenticalTo(s1.session))
+
+		secret.Data["AWS_SECRET_ACCESS_KEY"] = []byte("rotated")
+		Expect(k8sClient.Update(ctx, secret)).To(Succeed())
+
+		Expect(objectStore().session).ToNot(BeIdenticalTo(s1.session))
+	})
+
+	It("rebuilds a watched connection when invalidated by its secret", func() {
+		pool.watched = true
+		s1 := objectStore()
+		Expect(objectStore().session).To(BeIdenticalTo(s1.session))
+
+		pool.secretChanged(secret)
+		Expect(objectStore().session).ToNot(BeIdenticalTo(s1.session))
+	})
+
+	It("rebuilds a watched connection when invalidated by the ramen config", func() {
+		pool.watched = true
+		s1 := objectStore()
+
+		pool.configMapChanged(configMap)
+		Expect(objectStore().session).ToNot(BeIdenticalTo(s1.session))
+	})
+
+	It("closes a GCS connection when invalidated by its secret", func() {
+		gcsProfile := ramen.S3StoreProfile{
+			S3ProfileName:        profileName,
+			S3Bucket:             "bucket",
+			S3CompatibleEndpoint: "http://127.0.0.1:1",
+			Provider:             ramen.ObjectStoreProviderGCS,
+		}
+
+		s, err := newGCSObjectStore(profileName, gcsProfile, secret)
+		Expect(err).ToNot(HaveOccurred())
+
+		pool.entries[profileName] = &s3ObjectStorePoolEntry{
+			objectStore: s,
+			profile:     gcsProfile,
+			secretName:  client.ObjectKeyFromObject(secret),
+		}
+
+		pool.secretChanged(secret)
+		Expect(pool.entries).ToNot(HaveKey(profileName))
+
+		_, err = s.ListKeys("prefix/")
+		Expect(err).To(MatchError(errGCSConnectionClosed))
+	})
+})
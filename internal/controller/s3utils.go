@@ -16,7 +16,6 @@ import (
 
 	"github.com/aws/aws-sdk-go/aws"
 	"github.com/aws/aws-sdk-go/aws/awserr"
-	"github.com/aws/aws-sdk-go/aws/credentials"
 	"github.com/aws/aws-sdk-go/aws/session"
 	"github.com/aws/aws-sdk-go/service/s3"
 	"github.com/aws/aws-sdk-go/service/s3/s3manager"
@@ -98,84 +97,32 @@ type ObjectStorer interface {
 	DeleteObjectsWithKeyPrefix(keyPrefix string) error
 }
 
-// S3ObjectStoreGetter returns a concrete type that implements
-// the ObjectStoreGetter interface, allowing the concrete type
-// to be not exported.
+// S3ObjectStoreGetter returns the process-wide pool of S3 object store
+// connections, a concrete type that implements the ObjectStoreGetter
+// interface, allowing the concrete type to be not exported.
 func S3ObjectStoreGetter() ObjectStoreGetter {
-	return s3ObjectStoreGetter{}
-}
-
-// s3ObjectStoreGetter is a private concrete type that implements
-// the ObjectStoreGetter interface.
-type s3ObjectStoreGetter struct{}
-
-// ObjectStore returns an S3 object store that satisfies the ObjectStorer
-// interface,  with a downloader and an uploader client connections, by either
-// creating a new connection or returning a previously established connection
-// for the given s3 profile.  Returns an error if s3 profile does not exists,
-// secret is not configured, or if client session creation fails.
-func (s3ObjectStoreGetter) ObjectStore(ctx context.Context,
-	r client.Reader, s3ProfileName string,
-	callerTag string, log logr.Logger,
-) (ObjectStorer, ramen.S3StoreProfile, error) {
-	s3StoreProfile, err := GetRamenConfigS3StoreProfile(ctx, r, s3ProfileName)
-	if err != nil {
-		return nil, s3StoreProfile, fmt.Errorf("failed to get profile %s for caller %s, %w",
-			s3ProfileName, callerTag, err)
-	}
-
-	accessID, secretAccessKey, err := GetS3Secret(ctx, r, s3StoreProfile.S3SecretRef)
-	if err != nil {
-		return nil, s3StoreProfile, fmt.Errorf("failed to get secret %v for caller %s, %w",
-			s3StoreProfile.S3SecretRef, callerTag, err)
-	}
-
-	s3Endpoint := s3StoreProfile.S3CompatibleEndpoint
-	s3Region := s3StoreProfile.S3Region
+	return s3ObjectStorePoolInstance
+}
 
-	// Create an S3 client session
-	s3Session, err := session.NewSession(&aws.Config{
-		Credentials: credentials.NewStaticCredentials(string(accessID),
-			string(secretAccessKey), ""),
-		Endpoint:         aws.String(s3Endpoint),
-		Region:           aws.String(s3Region),
-		DisableSSL:       aws.Bool(true),
-		S3ForcePathStyle: aws.Bool(true),
-	})
+func GetS3Secret(ctx context.Context, r client.Reader,
+	secretRef corev1.SecretReference) (
+	s3AccessID, s3SecretAccessKey []byte, err error,
+) {
+	secret, err := getS3SecretObject(ctx, r, secretRef)
 	if err != nil {
-		return nil, s3StoreProfile, fmt.Errorf("failed to create new session for %s for caller %s, %w",
-			s3Endpoint, callerTag, err)
+		return nil, nil, err
 	}
 
-	// Create a client session
-	s3Client := s3.New(s3Session)
-
-	// Also create S3 uploader and S3 downloader which can be safely used
-	// concurrently across goroutines, whereas, the s3 client session
-	// does not support concurrent writers.
-	s3Uploader := s3manager.NewUploaderWithClient(s3Client)
-	s3Downloader := s3manager.NewDownloaderWithClient(s3Client)
-	s3BatchDeleter := s3manager.NewBatchDeleteWithClient(s3Client)
-	s3Conn := &s3ObjectStore{
-		session:      s3Session,
-		client:       s3Client,
-		uploader:     s3Uploader,
-		downloader:   s3Downloader,
-		batchDeleter: s3BatchDeleter,
-		s3Endpoint:   s3Endpoint,
-		s3Bucket:     s3StoreProfile.S3Bucket,
-		callerTag:    callerTag,
-		name:         s3ProfileName,
-	}
+	s3AccessID = secret.Data["AWS_ACCESS_KEY_ID"]
+	s3SecretAccessKey = secret.Data["AWS_SECRET_ACCESS_KEY"]
 
-	return s3Conn, s3StoreProfile, nil
+	return
 }
 
-func GetS3Secret(ctx context.Context, r client.Reader,
-	secretRef corev1.SecretReference) (
-	s3AccessID, s3SecretAccessKey []byte, err error,
-) {
-	secret := corev1.Secret{}
+func getS3SecretObject(ctx context.Context, r client.Reader,
+	secretRef corev1.SecretReference,
+) (*corev1.Secret, error) {
+	secret := &corev1.Secret{}
 	namepacedName := types.NamespacedName{Namespace: "", Name: secretRef.Name}
 
 	if secretRef.Namespace == "" {
@@ -184,15 +131,12 @@ func GetS3Secret(ctx context.Context, r client.Reader,
 		namepacedName.Namespace = secretRef.Namespace
 	}
 
-	if err := r.Get(ctx, namepacedName, &secret); err != nil {
-		return nil, nil, fmt.Errorf("failed to get secret %v, %w",
+	if err := r.Get(ctx, namepacedName, secret); err != nil {
+		return nil, fmt.Errorf("failed to get secret %v, %w",
 			secretRef, err)
 	}
 
-	s3AccessID = secret.Data["AWS_ACCESS_KEY_ID"]
-	s3SecretAccessKey = secret.Data["AWS_SECRET_ACCESS_KEY"]
-
-	return
+	return secret, nil
 }
 
 type s3ObjectStore struct {
@@ -205,10 +149,15 @@ type s3ObjectStore struct {
 	s3Bucket     string
 	callerTag    string
 	name         string
+	objectLock   *ramen.S3ObjectLock
+	// bucketObjectLock is shared by the copies of the object store for other
+	// callers, see withCallerTag
+	bucketObjectLock *s3BucketObjectLock
 }
 
-// CreateBucket creates the given bucket; does not return an error if the bucket
-// exists already.
+// CreateBucket creates the given bucket, with object lock enabled if the
+// profile configures object lock retention; does not return an error if the
+// bucket exists already.
 func (s *s3ObjectStore) CreateBucket(bucket string) (err error) {
 	if bucket == "" {
 		return fmt.Errorf("empty bucket name for "+
@@ -224,6 +173,9 @@ func (s *s3ObjectStore) CreateBucket(bucket string) (err error) {
 	}()
 
 	cbInput := &s3.CreateBucketInput{Bucket: &bucket}
+	if s.objectLock != nil {
+		cbInput.ObjectLockEnabledForBucket = aws.Bool(true)
+	}
 	if err = cbInput.Validate(); err != nil {
 		errMsgPrefix := fmt.Errorf("create bucket input validation failed for %s", bucket)
 
@@ -282,7 +234,10 @@ func (s *s3ObjectStore) DeleteBucket(bucket string) (
 	return nil
 }
 
-// PurgeBucket empties the content of the given bucket.
+// PurgeBucket empties the content of the given bucket, including all object
+// versions, and deletes it.  Object versions under object lock retention or
+// legal hold are retained, along with the bucket, and reported with an
+// s3ObjectsLockedError.
 func (s *s3ObjectStore) PurgeBucket(bucket string) (
 	err error,
 ) {
@@ -299,25 +254,15 @@ func (s *s3ObjectStore) PurgeBucket(bucket string) (
 		}
 	}()
 
-	keys, err := s.ListKeys("")
+	err = s.deleteObjectVersions(bucket)
 	if err != nil {
 		if isAwsErrCodeNoSuchBucket(err) {
 			return nil // Not an error
 		}
 
-		errMsgPrefix := fmt.Errorf("unable to ListKeys "+
-			"from endpoint %s bucket %s",
-			s.s3Endpoint, bucket)
-
-		return processAwsError(errMsgPrefix, err)
-	}
-
-	for _, key := range keys {
-		err = s.DeleteObjects(key)
-		if err != nil {
-			return fmt.Errorf("failed to delete object %s in bucket %s, %w",
-				key, bucket, err)
-		}
+		return fmt.Errorf("unable to purge objects "+
+			"from endpoint %s bucket %s, %w",
+			s.s3Endpoint, bucket, err)
 	}
 
 	err = s.DeleteBucket(bucket)
@@ -387,25 +332,44 @@ func UploadPVC(s ObjectStorer, pvcKeyPrefix, pvcKeySuffix string,
 
 // uploadTypedObject uploads to the bucket the given uploadContent with a
 // key of <keyPrefix><objectType/>keySuffix>, where objectType is the type of the
-// uploadContent parameter. OK to call uploadTypedObject() concurrently from
-// multiple goroutines safely.
+// uploadContent parameter, and records it in the manifest of keyPrefix. OK to
+// call uploadTypedObject() concurrently from multiple goroutines safely.
 // - keyPrefix should have any required delimiters like '/'
 func uploadTypedObject(s ObjectStorer, keyPrefix, keySuffix string,
 	uploadContent interface{},
 ) error {
 	key := typedKey(keyPrefix, keySuffix, reflect.TypeOf(uploadContent))
 
-	return s.UploadObject(key, uploadContent)
+	if err := s.UploadObject(key, uploadContent); err != nil {
+		return err
+	}
+
+	return s3ManifestObjectRecord(s, keyPrefix, key, uploadContent)
 }
 
+// DownloadTypedObject downloads the object uploaded by uploadTypedObject() with
+// the given key prefix and suffix, migrating it from an older format version if
+// needed.
 func DownloadTypedObject(s ObjectStorer, keyPrefix, keySuffix string, objectPointer interface{},
 ) error {
-	return s.DownloadObject(typedKey(keyPrefix, keySuffix, reflect.TypeOf(objectPointer).Elem()), objectPointer)
+	manifest, err := s3ManifestGet(s, keyPrefix)
+	if err != nil {
+		return err
+	}
+
+	return downloadManifestedObject(s, manifest,
+		typedKey(keyPrefix, keySuffix, reflect.TypeOf(objectPointer).Elem()), objectPointer)
 }
 
 func DeleteTypedObject(s ObjectStorer, keyPrefix, keySuffix string, object interface{},
 ) error {
-	return s.DeleteObject(typedKey(keyPrefix, keySuffix, reflect.TypeOf(object)))
+	key := typedKey(keyPrefix, keySuffix, reflect.TypeOf(object))
+
+	if err := s.DeleteObject(key); err != nil {
+		return err
+	}
+
+	return s3ManifestObjectForget(s, keyPrefix, key)
 }
 
 func processAwsError(errMsgPrefix, err error) error {
@@ -428,33 +392,49 @@ func processAwsError(errMsgPrefix, err error) error {
 func (s *s3ObjectStore) UploadObject(key string,
 	uploadContent interface{},
 ) error {
-	encodedUploadContent := &bytes.Buffer{}
-	bucket := s.s3Bucket
+	return s.uploadObject(key, uploadContent, true)
+}
 
-	gzWriter := gzip.NewWriter(encodedUploadContent)
-	if err := json.NewEncoder(gzWriter).Encode(uploadContent); err != nil {
-		return fmt.Errorf("failed to json encode %s:%s, %w",
-			bucket, key, err)
-	}
+// uploadObjectUnlocked uploads the given object without the object lock
+// retention of the profile, if any.
+func (s *s3ObjectStore) uploadObjectUnlocked(key string, uploadContent interface{}) error {
+	return s.uploadObject(key, uploadContent, false)
+}
 
-	if err := gzWriter.Close(); err != nil {
-		return fmt.Errorf("failed to close gzip writer of %s:%s, %w",
-			bucket, key, err)
+func (s *s3ObjectStore) uploadObject(key string, uploadContent interface{}, objectLock bool) error {
+	bucket := s.s3Bucket
+
+	encodedUploadContent, err := encodeObject(bucket, key, uploadContent)
+	if err != nil {
+		return err
 	}
 
 	ctx, cancel := context.WithDeadline(context.TODO(), time.Now().Add(s3Timeout))
 	defer cancel()
 
-	if _, err := s.uploader.UploadWithContext(ctx, &s3manager.UploadInput{
+	start := time.Now()
+	uploadSize := int64(encodedUploadContent.Len())
+
+	uploadInput := &s3manager.UploadInput{
 		Bucket: &bucket,
 		Key:    &key,
 		Body:   encodedUploadContent,
-	}); err != nil {
+	}
+
+	if objectLock {
+		s.objectLockApply(uploadInput)
+	}
+
+	if _, err := s.uploader.UploadWithContext(ctx, uploadInput); err != nil {
+		s3OperationFailed(s.name, s3OperationUpload, start, err)
+
 		errMsgPrefix := fmt.Errorf("failed to upload data of %s:%s", bucket, key)
 
 		return processAwsError(errMsgPrefix, err)
 	}
 
+	s3OperationSucceeded(s.name, s3OperationUpload, start, uploadSize)
+
 	return nil
 }
 
@@ -517,12 +497,19 @@ func DownloadVRGs(s ObjectStorer, pvKeyPrefix string) (
 //     Example new key prefix: namespace/vrgName/v1.PersistentVolumeClaim/
 //   - Objects being downloaded should meet the decoding expectations of
 //     the DownloadObject() method.
+//   - Objects are verified against, and migrated from the format version
+//     recorded in, the manifest of the given key prefix.
 func DownloadTypedObjects(s ObjectStorer, keyPrefix string, objectsPointer interface{},
 ) error {
 	objectsValue := reflect.ValueOf(objectsPointer).Elem()
 	objectType := objectsValue.Type().Elem()
 	newKeyPrefix := typedKey(keyPrefix, "", objectType)
 
+	manifest, err := s3ManifestGet(s, keyPrefix)
+	if err != nil {
+		return err
+	}
+
 	keys, err := s.ListKeys(newKeyPrefix)
 	if err != nil {
 		return fmt.Errorf("unable to ListKeys of type %v keyPrefix %s, %w",
@@ -534,7 +521,7 @@ func DownloadTypedObjects(s ObjectStorer, keyPrefix string, objectsPointer inter
 
 	for i := range keys {
 		objectReceiver := objects.Index(i).Addr().Interface()
-		if err := s.DownloadObject(keys[i], objectReceiver); err != nil {
+		if err := downloadManifestedObject(s, manifest, keys[i], objectReceiver); err != nil {
 			return fmt.Errorf("unable to DownloadObject of key %s, %w",
 				keys[i], err)
 		}
@@ -558,6 +545,8 @@ func (s *s3ObjectStore) ListKeys(keyPrefix string) (
 	ctx, cancel := context.WithDeadline(context.TODO(), time.Now().Add(s3Timeout))
 	defer cancel()
 
+	start := time.Now()
+
 	for gotAllObjects := false; !gotAllObjects; {
 		result, err := s.client.ListObjectsV2WithContext(ctx, &s3.ListObjectsV2Input{
 			Bucket:            &bucket,
@@ -565,6 +554,8 @@ func (s *s3ObjectStore) ListKeys(keyPrefix string) (
 			ContinuationToken: nextContinuationToken,
 		})
 		if err != nil {
+			s3OperationFailed(s.name, s3OperationList, start, err)
+
 			errMsgPrefix := fmt.Errorf("failed to list objects in bucket")
 
 			return nil, processAwsError(errMsgPrefix, err)
@@ -581,6 +572,8 @@ func (s *s3ObjectStore) ListKeys(keyPrefix string) (
 		}
 	}
 
+	s3OperationSucceeded(s.name, s3OperationList, start, 0)
+
 	return keys, nil
 }
 
@@ -607,22 +600,54 @@ func (s *s3ObjectStore) DownloadObject(key string,
 	ctx, cancel := context.WithDeadline(context.TODO(), time.Now().Add(s3Timeout))
 	defer cancel()
 
-	if _, err := s.downloader.DownloadWithContext(ctx, writerAt, &s3.GetObjectInput{
+	start := time.Now()
+
+	downloadSize, err := s.downloader.DownloadWithContext(ctx, writerAt, &s3.GetObjectInput{
 		Bucket: &bucket,
 		Key:    &key,
-	}); err != nil {
+	})
+	if err != nil {
+		s3OperationFailed(s.name, s3OperationDownload, start, err)
+
 		errMsgPrefix := fmt.Errorf("failed to download data of %s:%s", bucket, key)
 
 		return processAwsError(errMsgPrefix, err)
 	}
 
-	gzReader, err := gzip.NewReader(bytes.NewReader(writerAt.Bytes()))
+	s3OperationSucceeded(s.name, s3OperationDownload, start, downloadSize)
+
+	return decodeObject(bucket, key, writerAt.Bytes(), downloadContent)
+}
+
+// encodeObject json encodes and gzips the given object, to be uploaded to the
+// given bucket with the given key, in the format expected by decodeObject().
+func encodeObject(bucket, key string, object interface{}) (*bytes.Buffer, error) {
+	encodedObject := &bytes.Buffer{}
+
+	gzWriter := gzip.NewWriter(encodedObject)
+	if err := json.NewEncoder(gzWriter).Encode(object); err != nil {
+		return nil, fmt.Errorf("failed to json encode %s:%s, %w",
+			bucket, key, err)
+	}
+
+	if err := gzWriter.Close(); err != nil {
+		return nil, fmt.Errorf("failed to close gzip writer of %s:%s, %w",
+			bucket, key, err)
+	}
+
+	return encodedObject, nil
+}
+
+// decodeObject unzips and json decodes the given data, downloaded from the
+// given bucket with the given key, into objectPointer.
+func decodeObject(bucket, key string, data []byte, objectPointer interface{}) error {
+	gzReader, err := gzip.NewReader(bytes.NewReader(data))
 	if err != nil && !errors.Is(err, io.EOF) {
 		return fmt.Errorf("failed to unzip data of %s:%s, %w",
 			bucket, key, err)
 	}
 
-	if err := json.NewDecoder(gzReader).Decode(downloadContent); err != nil {
+	if err := json.NewDecoder(gzReader).Decode(objectPointer); err != nil {
 		return fmt.Errorf("failed to decode json decoder of %s:%s, %w",
 			bucket, key, err)
 	}
@@ -651,7 +676,10 @@ func (s *s3ObjectStore) DeleteObject(key string) error {
 
 // DeleteObjectsWithKeyPrefix deletes from the bucket any objects that
 // have the given keyPrefix.  If the bucket doesn't exist, it returns
-// ErrCodeNoSuchBucket "NoSuchBucket".
+// ErrCodeNoSuchBucket "NoSuchBucket".  If the bucket has object lock enabled,
+// objects under retention or legal hold are hidden by a delete marker, as a
+// delete without a version id is allowed under object lock, and their retained
+// versions are reported with an s3ObjectsLockedError.
 func (s *s3ObjectStore) DeleteObjectsWithKeyPrefix(keyPrefix string) (
 	err error,
 ) {
@@ -666,13 +694,20 @@ func (s *s3ObjectStore) DeleteObjectsWithKeyPrefix(keyPrefix string) (
 		return processAwsError(errMsgPrefix, err)
 	}
 
+	lockedErr, err := s.objectsLocked(keys)
+	if err != nil {
+		return fmt.Errorf("unable to get object lock status "+
+			"from endpoint %s bucket %s keyPrefix %s, %w",
+			s.s3Endpoint, bucket, keyPrefix, err)
+	}
+
 	if err = s.DeleteObjects(keys...); err != nil {
 		return fmt.Errorf("unable to DeleteObjects "+
 			"from endpoint %s bucket %s keyPrefix %s, %w",
 			s.s3Endpoint, bucket, keyPrefix, err)
 	}
 
-	return nil
+	return lockedErr
 }
 
 func (s *s3ObjectStore) DeleteObjects(keys ...string) error {
@@ -691,15 +726,21 @@ func (s *s3ObjectStore) DeleteObjects(keys ...string) error {
 	ctx, cancel := context.WithDeadline(context.TODO(), time.Now().Add(s3Timeout))
 	defer cancel()
 
+	start := time.Now()
+
 	err := s.batchDeleter.Delete(ctx, &s3manager.DeleteObjectsIterator{
 		Objects: delObjects,
 	})
 	if err != nil {
+		s3OperationFailed(s.name, s3OperationDelete, start, err)
+
 		errMsgPrefix := fmt.Errorf("unable to process batch delete")
 
 		return processAwsError(errMsgPrefix, err)
 	}
 
+	s3OperationSucceeded(s.name, s3OperationDelete, start, 0)
+
 	return nil
 }
 
@@ -5,6 +5,7 @@ package controllers_test
 
 import (
 	"context"
+	"encoding/json"
 	"fmt"
 	"io/fs"
 	"reflect"
@@ -115,9 +116,18 @@ func (f *fakeObjectStorer) DownloadObject(key string, objectPointer interface{})
 
 	objectSource := reflect.ValueOf(object)
 	Expect(objectSource.IsValid()).To(BeTrue())
-	objectDestination.Set(objectSource)
 
-	return nil
+	if objectSource.Type().AssignableTo(objectDestination.Type()) {
+		objectDestination.Set(objectSource)
+
+		return nil
+	}
+
+	// Decode into a different type, e.g. json.RawMessage, as the S3 store does
+	data, err := json.Marshal(object)
+	Expect(err).ToNot(HaveOccurred())
+
+	return json.Unmarshal(data, objectPointer)
 }
 
 func (f *fakeObjectStorer) ListKeys(keyPrefix string) ([]string, error) {
@@ -42,6 +42,16 @@ const (
 
 	VRGConditionTypeNoClusterDataConflict = "NoClusterDataConflict"
 
+	// Kube objects are recoverable.  This condition indicates whether the kube
+	// objects of the capture-to-recover-from would be recovered on the
+	// secondary cluster, as verified there with a server-side dry run.
+	VRGConditionTypeKubeObjectsRecoverable = "KubeObjectsRecoverable"
+
+	// Recipe is valid.  This condition indicates whether the recipe the VRG
+	// refers to, with the VRG's recipe parameters, is valid, as validated when
+	// the recipe is created or updated.
+	VRGConditionTypeRecipeValidated = "RecipeValidated"
+
 	// VolSync related conditions. These conditions are only applicable
 	// at individual PVCs and not generic VRG conditions.
 	VRGConditionTypeVolSyncRepSourceSetup      = "ReplicationSourceSetup"
@@ -52,33 +62,40 @@ const (
 
 // VRG condition reasons
 const (
-	VRGConditionReasonUnused                      = "Unused"
-	VRGConditionReasonInitializing                = "Initializing"
-	VRGConditionReasonReplicating                 = "Replicating"
-	VRGConditionReasonReplicated                  = "Replicated"
-	VRGConditionReasonReady                       = "Ready"
-	VRGConditionReasonDataProtected               = "DataProtected"
-	VRGConditionReasonProgressing                 = "Progressing"
-	VRGConditionReasonClusterDataRestored         = "Restored"
-	VRGConditionReasonClusterDataUnused           = "Unused"
-	VRGConditionReasonKubeObjectsRestored         = "KubeObjectsRestored"
-	VRGConditionReasonKubeObjectsUnused           = "Unused"
-	VRGConditionReasonError                       = "Error"
-	VRGConditionReasonErrorUnknown                = "UnknownError"
-	VRGConditionReasonUploading                   = "Uploading"
-	VRGConditionReasonUploaded                    = "Uploaded"
-	VRGConditionReasonUploadError                 = "UploadError"
-	VRGConditionReasonVolSyncRepSourceInited      = "SourceInitialized"
-	VRGConditionReasonVolSyncRepDestInited        = "DestinationInitialized"
-	VRGConditionReasonVolSyncPVsRestored          = "Restored"
-	VRGConditionReasonVolSyncFinalSyncInProgress  = "Syncing"
-	VRGConditionReasonVolSyncFinalSyncComplete    = "Synced"
-	VRGConditionReasonClusterDataAnnotationFailed = "AnnotationFailed"
-	VRGConditionReasonPeerClassNotFound           = "PeerClassNotFound"
-	VRGConditionReasonStorageIDNotFound           = "StorageIDNotFound"
-	VRGConditionReasonDataConflictPrimary         = "ClusterDataConflictPrimary"
-	VRGConditionReasonDataConflictSecondary       = "ClusterDataConflictSecondary"
-	VRGConditionReasonConflictResolved            = "ConflictResolved"
+	VRGConditionReasonUnused                             = "Unused"
+	VRGConditionReasonInitializing                       = "Initializing"
+	VRGConditionReasonReplicating                        = "Replicating"
+	VRGConditionReasonReplicated                         = "Replicated"
+	VRGConditionReasonReady                              = "Ready"
+	VRGConditionReasonDataProtected                      = "DataProtected"
+	VRGConditionReasonProgressing                        = "Progressing"
+	VRGConditionReasonClusterDataRestored                = "Restored"
+	VRGConditionReasonClusterDataUnused                  = "Unused"
+	VRGConditionReasonKubeObjectsRestored                = "KubeObjectsRestored"
+	VRGConditionReasonKubeObjectsUnused                  = "Unused"
+	VRGConditionReasonError                              = "Error"
+	VRGConditionReasonErrorUnknown                       = "UnknownError"
+	VRGConditionReasonUploading                          = "Uploading"
+	VRGConditionReasonUploaded                           = "Uploaded"
+	VRGConditionReasonUploadError                        = "UploadError"
+	VRGConditionReasonVolSyncRepSourceInited             = "SourceInitialized"
+	VRGConditionReasonVolSyncRepDestInited               = "DestinationInitialized"
+	VRGConditionReasonVolSyncPVsRestored                 = "Restored"
+	VRGConditionReasonVolSyncFinalSyncInProgress         = "Syncing"
+	VRGConditionReasonVolSyncFinalSyncComplete           = "Synced"
+	VRGConditionReasonClusterDataAnnotationFailed        = "AnnotationFailed"
+	VRGConditionReasonPeerClassNotFound                  = "PeerClassNotFound"
+	VRGConditionReasonStorageIDNotFound                  = "StorageIDNotFound"
+	VRGConditionReasonDataConflictPrimary                = "ClusterDataConflictPrimary"
+	VRGConditionReasonDataConflictSecondary              = "ClusterDataConflictSecondary"
+	VRGConditionReasonKubeObjectsRecoverable             = "Recoverable"
+	VRGConditionReasonKubeObjectsUnrecoverable           = "Unrecoverable"
+	VRGConditionReasonKubeObjectsUnverifiable            = "Unverifiable"
+	VRGConditionReasonKubeObjectsVerificationUnsupported = "VerificationUnsupported"
+	VRGConditionReasonConflictResolved                   = "ConflictResolved"
+	VRGConditionReasonRecipeValid                        = "Valid"
+	VRGConditionReasonRecipeInvalid                      = "Invalid"
+	VRGConditionReasonRecipeNotFound                     = "NotFound"
 )
 
 const (
@@ -503,3 +520,36 @@ func updateVRGNoClusterDataConflictCondition(observedGeneration int64,
 		Message:            message,
 	}
 }
+
+// sets conditions when the recovery of kube objects is verified, or cannot be
+func setVRGKubeObjectsRecoverableCondition(conditions *[]metav1.Condition, observedGeneration int64,
+	status metav1.ConditionStatus, reason, message string,
+) {
+	util.SetStatusCondition(conditions, metav1.Condition{
+		Type:               VRGConditionTypeKubeObjectsRecoverable,
+		Reason:             reason,
+		ObservedGeneration: observedGeneration,
+		Status:             status,
+		Message:            message,
+	})
+}
+
+// sets conditions when the recipe the VRG refers to is validated
+func setVRGRecipeValidatedCondition(conditions *[]metav1.Condition, observedGeneration int64,
+	reason string, err error,
+) {
+	status, message := metav1.ConditionTrue, "Recipe hooks and workflows are valid"
+	if err != nil {
+		status, message = metav1.ConditionFalse, err.Error()
+	} else {
+		reason = VRGConditionReasonRecipeValid
+	}
+
+	util.SetStatusCondition(conditions, metav1.Condition{
+		Type:               VRGConditionTypeRecipeValidated,
+		Reason:             reason,
+		ObservedGeneration: observedGeneration,
+		Status:             status,
+		Message:            message,
+	})
+}
@@ -55,6 +55,30 @@ const (
 	// EventReasonSecondarySuccess is an event generated when VRG is successfully
 	// processed as Primary.
 	EventReasonDeleteSuccess = "VRGDeleteSuccess"
+
+	// EventReasonS3ObjectsLocked is used when VRG cluster data objects in an S3
+	// store are retained, rather than deleted, as they are under object lock
+	EventReasonS3ObjectsLocked = "S3ObjectsLocked"
+
+	// EventReasonKubeObjectsChanged is used when VRG kube objects captured
+	// differ from those of the previous capture
+	EventReasonKubeObjectsChanged = "KubeObjectsChanged"
+
+	// EventReasonKubeObjectsUnrecoverable is used when VRG kube objects captured
+	// would not all be recovered, as verified on the secondary cluster
+	EventReasonKubeObjectsUnrecoverable = "KubeObjectsUnrecoverable"
+
+	// EventReasonKubeObjectsSecretsRedacted is used when VRG kube objects
+	// captured exclude or redact secrets, as directed by secret policies
+	EventReasonKubeObjectsSecretsRedacted = "KubeObjectsSecretsRedacted"
+
+	// EventReasonRecipeValidated is used when a Recipe's hooks and workflows
+	// are validated as it is created or updated
+	EventReasonRecipeValidated = "RecipeValidated"
+
+	// EventReasonRecipeValidationFailed is used when a Recipe's hooks or
+	// workflows are not valid as it is created or updated
+	EventReasonRecipeValidationFailed = "RecipeValidationFailed"
 	// TODO: Add any additional events (or remove one of existing ones above) if necessary.
 
 	// Events for DRPC Reconciler
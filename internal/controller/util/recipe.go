@@ -4,6 +4,10 @@
 package util
 
 import (
+	"os"
+	"slices"
+	"strings"
+
 	"github.com/ramendr/ramen/internal/controller/kubeobjects"
 	recipev1 "github.com/ramendr/recipe/api/v1alpha1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
@@ -18,6 +22,7 @@ type RecipeElements struct {
 	CaptureFailOn       string
 	RestoreFailOn       string
 	RecipeWithParams    *recipev1.Recipe
+	Parameters          map[string][]string
 	StopRecipeReconcile bool
 }
 
@@ -25,3 +30,17 @@ type PvcSelector struct {
 	LabelSelector  metav1.LabelSelector
 	NamespaceNames []string
 }
+
+// ParametersExpand expands the given parameters in the given string, except
+// for those named output parameters that are not given, which the output of an
+// exec hook expands to later, in its workflow.
+func ParametersExpand(s string, parameters map[string][]string, outputParameterNames []string) string {
+	return os.Expand(s, func(key string) string {
+		values, ok := parameters[key]
+		if !ok && slices.Contains(outputParameterNames, key) {
+			return "${" + key + "}"
+		}
+
+		return strings.Join(values, `","`)
+	})
+}
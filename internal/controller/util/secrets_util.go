@@ -11,8 +11,10 @@ import (
 	"crypto/md5"
 	"encoding/hex"
 	"fmt"
+	"strings"
 
 	"github.com/go-logr/logr"
+	rmn "github.com/ramendr/ramen/api/v1alpha1"
 	plrv1 "github.com/stolostron/multicloud-operators-placementrule/pkg/apis/apps/v1"
 	corev1 "k8s.io/api/core/v1"
 	k8serrors "k8s.io/apimachinery/pkg/api/errors"
@@ -40,6 +42,10 @@ const (
 	// See: https://github.com/stolostron/rhacm-docs/blob/2.4_stage/governance/custom_template.adoc#special-annotation-for-reprocessing
 	PolicyTriggerAnnotation = "policy.open-cluster-management.io/trigger-update"
 
+	// PolicySecretKeysAnnotation lists the keys of the secret a ramen format
+	// policy delivers, so that its template is updated when they change
+	PolicySecretKeysAnnotation = "drpolicies.ramendr.openshift.io/secret-keys"
+
 	// Finalizer on the secret
 	SecretPolicyFinalizer string = "drpolicies.ramendr.openshift.io/policy-protection"
 
@@ -273,7 +279,30 @@ func (in *localSecret) DeepCopyInto(out *localSecret) {
 	}
 }
 
-func newS3ConfigurationSecret(s3SecretRef corev1.SecretReference, targetns string) *localSecret {
+// Keys of the ramen format secrets of S3 profiles, by object store provider
+const (
+	SecretKeyAWSAccessKeyID          = "AWS_ACCESS_KEY_ID"
+	SecretKeyAWSSecretAccessKey      = "AWS_SECRET_ACCESS_KEY"
+	SecretKeyAzureStorageAccountName = "AZURE_STORAGE_ACCOUNT_NAME"
+	SecretKeyAzureStorageAccountKey  = "AZURE_STORAGE_ACCOUNT_KEY"
+	SecretKeyGoogleServiceAccountKey = "GOOGLE_SERVICE_ACCOUNT_KEY"
+)
+
+// ObjectStoreSecretKeys returns the keys of the ramen format secret of an S3
+// profile of the given object store provider.
+func ObjectStoreSecretKeys(provider rmn.ObjectStoreProvider) []string {
+	switch provider {
+	case rmn.ObjectStoreProviderAzure:
+		return []string{SecretKeyAzureStorageAccountName, SecretKeyAzureStorageAccountKey}
+	case rmn.ObjectStoreProviderGCS:
+		return []string{SecretKeyGoogleServiceAccountKey}
+	default:
+		return []string{SecretKeyAWSAccessKeyID, SecretKeyAWSSecretAccessKey}
+	}
+}
+
+func newS3ConfigurationSecret(s3SecretRef corev1.SecretReference, targetns string, secretKeys []string,
+) *localSecret {
 	localsecret := &localSecret{
 		TypeMeta: metav1.TypeMeta{
 			Kind:       "Secret",
@@ -283,16 +312,14 @@ func newS3ConfigurationSecret(s3SecretRef corev1.SecretReference, targetns strin
 			Name:      s3SecretRef.Name,
 			Namespace: targetns,
 		},
-		Data: map[string]string{
-			"AWS_ACCESS_KEY_ID": "{{hub fromSecret " +
-				"\"" + s3SecretRef.Namespace + "\"" + " " +
-				"\"" + s3SecretRef.Name + "\"" + " " +
-				"\"AWS_ACCESS_KEY_ID\" hub}}",
-			"AWS_SECRET_ACCESS_KEY": "{{hub fromSecret " +
-				"\"" + s3SecretRef.Namespace + "\"" + " " +
-				"\"" + s3SecretRef.Name + "\"" + " " +
-				"\"AWS_SECRET_ACCESS_KEY\" hub}}",
-		},
+		Data: make(map[string]string, len(secretKeys)),
+	}
+
+	for _, key := range secretKeys {
+		localsecret.Data[key] = "{{hub fromSecret " +
+			"\"" + s3SecretRef.Namespace + "\"" + " " +
+			"\"" + s3SecretRef.Name + "\"" + " " +
+			"\"" + key + "\" hub}}"
 	}
 
 	AddLabel(localsecret, CreatedByRamenLabel, "true")
@@ -387,6 +414,7 @@ func (sutil *SecretsUtil) createPolicyResources(
 	cluster, namespace, targetNS string,
 	format TargetSecretFormat,
 	veleroNS string,
+	secretKeys []string,
 ) error {
 	policyName, plBindingName, plRuleName, configPolicyName := GeneratePolicyResourceNames(secret.Name, format)
 
@@ -420,7 +448,7 @@ func (sutil *SecretsUtil) createPolicyResources(
 
 	// Create a Policy object for the secret
 	configObject := newConfigurationPolicy(configPolicyName,
-		sutil.policyObject(secret.Name, namespace, targetNS, format, veleroNS))
+		sutil.policyObject(secret.Name, namespace, targetNS, format, veleroNS, secretKeys))
 	AddLabel(configObject, CreatedByRamenLabel, "true")
 
 	sutil.Log.Info("Initializing secret policy trigger", "secret", secret.Name, "trigger", secret.ResourceVersion)
@@ -429,6 +457,10 @@ func (sutil *SecretsUtil) createPolicyResources(
 		secret.ResourceVersion, runtime.RawExtension{Object: configObject})
 	AddLabel(policyObject, CreatedByRamenLabel, "true")
 
+	if format == SecretFormatRamen {
+		AddAnnotation(policyObject, PolicySecretKeysAnnotation, strings.Join(secretKeys, ","))
+	}
+
 	if err := sutil.Client.Create(sutil.Ctx, policyObject); err != nil && !k8serrors.IsAlreadyExists(err) {
 		sutil.Log.Error(err, "unable to create policy", "secret", secret.Name, "cluster", cluster)
 
@@ -452,6 +484,7 @@ func (sutil *SecretsUtil) policyObject(
 	secretName, secretNS, targetNS string,
 	format TargetSecretFormat,
 	veleroNS string,
+	secretKeys []string,
 ) *runtime.RawExtension {
 	var object *runtime.RawExtension
 
@@ -459,7 +492,7 @@ func (sutil *SecretsUtil) policyObject(
 
 	switch format {
 	case SecretFormatRamen:
-		object = &runtime.RawExtension{Object: newS3ConfigurationSecret(s3SecretRef, targetNS)}
+		object = &runtime.RawExtension{Object: newS3ConfigurationSecret(s3SecretRef, targetNS, secretKeys)}
 	case SecretFormatVelero:
 		object = &runtime.RawExtension{
 			Object: newVeleroSecret(s3SecretRef, targetNS, veleroNS, VeleroSecretKeyNameDefault),
@@ -636,6 +669,45 @@ func (sutil *SecretsUtil) ticklePolicy(secret *corev1.Secret, namespace string)
 	return nil
 }
 
+// policySecretKeysUpdate updates the template of the ramen format policy of the
+// given secret to deliver the given keys, if it delivers others, as policies
+// created before the keys depended on the object store provider do.
+func (sutil *SecretsUtil) policySecretKeysUpdate(
+	secret *corev1.Secret,
+	namespace, targetNS string,
+	secretKeys []string,
+) error {
+	policyName, _, _, configPolicyName := GeneratePolicyResourceNames(secret.Name, SecretFormatRamen)
+	keys := strings.Join(secretKeys, ",")
+	policyObject := gppv1.Policy{}
+
+	if err := sutil.Client.Get(sutil.Ctx,
+		types.NamespacedName{Namespace: namespace, Name: policyName},
+		&policyObject); err != nil {
+		return fmt.Errorf("unable to get policy (secret: %s): %w", secret.Name, err)
+	}
+
+	if policyObject.GetAnnotations()[PolicySecretKeysAnnotation] == keys {
+		return nil
+	}
+
+	sutil.Log.Info("Updating secret policy keys", "secret", secret.Name, "keys", keys)
+
+	configObject := newConfigurationPolicy(configPolicyName,
+		sutil.policyObject(secret.Name, namespace, targetNS, SecretFormatRamen, "", secretKeys))
+	AddLabel(configObject, CreatedByRamenLabel, "true")
+
+	policyObject.Spec.PolicyTemplates = newPolicy(policyName, namespace, secret.ResourceVersion,
+		runtime.RawExtension{Object: configObject}).Spec.PolicyTemplates
+	AddAnnotation(&policyObject, PolicySecretKeysAnnotation, keys)
+
+	if err := sutil.Client.Update(sutil.Ctx, &policyObject); err != nil {
+		return fmt.Errorf("unable to update policy keys (secret: %s): %w", secret.Name, err)
+	}
+
+	return nil
+}
+
 func (sutil *SecretsUtil) updatePolicyResources(
 	plRule *plrv1.PlacementRule,
 	secret *corev1.Secret,
@@ -690,11 +762,13 @@ func (sutil *SecretsUtil) ensureS3SecretResources(
 // can help convert the secret in the hub cluster to a desired format on the target cluster.
 // The format SecretFormatVelero needs an additional argument veleroNS which is the namespace for the velero
 // formatted secret, to be delivered from the targetNS (which requires that the secret first be delivered to
-// the targetNS)
+// the targetNS). The format SecretFormatRamen delivers the given keys of the secret, which depend on the object
+// store providers of the S3 profiles that refer to it (see ObjectStoreSecretKeys).
 func (sutil *SecretsUtil) AddSecretToCluster(
 	secretName, clusterName, namespace, targetNS string,
 	format TargetSecretFormat,
 	veleroNS string,
+	secretKeys []string,
 ) error {
 	sutil.Log.Info("Add Secret", "cluster", clusterName, "secret", secretName, "format", format)
 
@@ -729,7 +803,13 @@ func (sutil *SecretsUtil) AddSecretToCluster(
 			return fmt.Errorf("failed to get placementRule object: %w", err)
 		}
 
-		return sutil.createPolicyResources(secret, clusterName, namespace, targetNS, format, veleroNS)
+		return sutil.createPolicyResources(secret, clusterName, namespace, targetNS, format, veleroNS, secretKeys)
+	}
+
+	if format == SecretFormatRamen {
+		if err := sutil.policySecretKeysUpdate(secret, namespace, targetNS, secretKeys); err != nil {
+			return err
+		}
 	}
 
 	return sutil.updatePolicyResources(plRule, secret, clusterName, namespace, format, true)
@@ -5,15 +5,18 @@ package util_test
 
 import (
 	"context"
+	"encoding/json"
 
 	. "github.com/onsi/ginkgo/v2"
 	. "github.com/onsi/gomega"
+	rmn "github.com/ramendr/ramen/api/v1alpha1"
 	"github.com/ramendr/ramen/internal/controller/util"
 	plrv1 "github.com/stolostron/multicloud-operators-placementrule/pkg/apis/apps/v1"
 	corev1 "k8s.io/api/core/v1"
 	k8serrors "k8s.io/apimachinery/pkg/api/errors"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/types"
+	cpcv1 "open-cluster-management.io/config-policy-controller/api/v1"
 	gppv1 "open-cluster-management.io/governance-policy-propagator/api/v1"
 )
 
@@ -35,6 +38,7 @@ var _ = Describe("Secrets_Util", func() {
 		secrets                                  [secretsCount]*corev1.Secret
 		tstNamespace                             = "default" // 7 chars
 		veleroNS                                 = "default" // 7 chars
+		s3SecretKeys                             = util.ObjectStoreSecretKeys(rmn.ObjectStoreProviderS3)
 	)
 
 	BeforeEach(func() {
@@ -194,7 +198,7 @@ var _ = Describe("Secrets_Util", func() {
 					clusterNames[0],
 					tstNamespace, // "default" 7 chars
 					tstNamespace,
-					util.SecretFormatRamen, "")).Should(HaveOccurred())
+					util.SecretFormatRamen, "", s3SecretKeys)).Should(HaveOccurred())
 			})
 			It("Does not create an associated secret policy", func() {
 				Expect(plRuleAbsent(plRuleName[2], tstNamespace)).Should(BeTrue())
@@ -207,7 +211,7 @@ var _ = Describe("Secrets_Util", func() {
 					clusterNames[0],
 					tstNamespace, // "default" 7 chars
 					tstNamespace,
-					util.SecretFormatRamen, "")).Should(HaveOccurred())
+					util.SecretFormatRamen, "", s3SecretKeys)).Should(HaveOccurred())
 			})
 			It("Does not create an associated secret policy", func() {
 				Expect(plRuleAbsent(plRuleName[2], tstNamespace)).Should(BeTrue())
@@ -223,7 +227,7 @@ var _ = Describe("Secrets_Util", func() {
 					clusterNames[0],
 					tstNamespace,
 					tstNamespace,
-					util.SecretFormatRamen, "")).To(Succeed())
+					util.SecretFormatRamen, "", s3SecretKeys)).To(Succeed())
 			})
 			It("Protects the secret with a finalizer", func() {
 				Expect(finalizerPresent(secretNames[2], util.SecretFormatRamen)).Should(BeTrue())
@@ -240,7 +244,7 @@ var _ = Describe("Secrets_Util", func() {
 					clusterNames[0],
 					tstNamespace,
 					tstNamespace,
-					util.SecretFormatRamen, "")).Should(HaveOccurred())
+					util.SecretFormatRamen, "", s3SecretKeys)).Should(HaveOccurred())
 			})
 			It("Does not create an associated secret policy", func() {
 				Expect(plRuleAbsent(plRuleName[0], tstNamespace)).Should(BeTrue())
@@ -256,7 +260,7 @@ var _ = Describe("Secrets_Util", func() {
 					clusterNames[0],
 					tstNamespace,
 					tstNamespace,
-					util.SecretFormatRamen, "")).To(Succeed())
+					util.SecretFormatRamen, "", s3SecretKeys)).To(Succeed())
 			})
 			It("Protects the secret with a finalizer", func() {
 				Expect(finalizerPresent(secretNames[0], util.SecretFormatRamen)).Should(BeTrue())
@@ -276,7 +280,7 @@ var _ = Describe("Secrets_Util", func() {
 					clusterNames[0],
 					tstNamespace,
 					tstNamespace,
-					util.SecretFormatRamen, "")).Should(HaveOccurred())
+					util.SecretFormatRamen, "", s3SecretKeys)).Should(HaveOccurred())
 			})
 			It("No longer protects the secret with a finalizer", func() {
 				By("Ensuring secret is deleted")
@@ -296,7 +300,7 @@ var _ = Describe("Secrets_Util", func() {
 					clusterNames[0],
 					tstNamespace,
 					tstNamespace,
-					util.SecretFormatRamen, "")).To(Succeed())
+					util.SecretFormatRamen, "", s3SecretKeys)).To(Succeed())
 			})
 			It("Protects the secret with a finalizer", func() {
 				Expect(finalizerPresent(secretNames[0], util.SecretFormatRamen)).Should(BeTrue())
@@ -316,7 +320,7 @@ var _ = Describe("Secrets_Util", func() {
 					clusterNames[0],
 					tstNamespace,
 					tstNamespace,
-					util.SecretFormatRamen, "")).To(Succeed())
+					util.SecretFormatRamen, "", s3SecretKeys)).To(Succeed())
 			})
 			It("Protects the secret with a finalizer", func() {
 				Expect(finalizerPresent(secretNames[0], util.SecretFormatRamen)).Should(BeTrue())
@@ -333,7 +337,7 @@ var _ = Describe("Secrets_Util", func() {
 					clusterNames[1],
 					tstNamespace,
 					tstNamespace,
-					util.SecretFormatRamen, "")).To(Succeed())
+					util.SecretFormatRamen, "", s3SecretKeys)).To(Succeed())
 			})
 			It("Protects the secret with a finalizer", func() {
 				Expect(finalizerPresent(secretNames[0], util.SecretFormatRamen)).Should(BeTrue())
@@ -354,7 +358,7 @@ var _ = Describe("Secrets_Util", func() {
 					clusterNames[0],
 					tstNamespace,
 					tstNamespace,
-					util.SecretFormatRamen, "")).To(Succeed())
+					util.SecretFormatRamen, "", s3SecretKeys)).To(Succeed())
 			})
 			It("Protects the secret with a finalizer", func() {
 				Expect(finalizerPresent(secretNames[1], util.SecretFormatRamen)).Should(BeTrue())
@@ -507,7 +511,8 @@ var _ = Describe("Secrets_Util", func() {
 					tstNamespace,
 					tstNamespace,
 					util.SecretFormatRamen,
-					"")).To(Succeed())
+					"",
+					s3SecretKeys)).To(Succeed())
 			})
 			It("Protects the secret with a finalizer", func() {
 				Expect(finalizerPresent(secretNames[0], util.SecretFormatRamen)).Should(BeTrue())
@@ -525,7 +530,8 @@ var _ = Describe("Secrets_Util", func() {
 					tstNamespace,
 					tstNamespace,
 					util.SecretFormatVelero,
-					veleroNS)).To(Succeed())
+					veleroNS,
+					nil)).To(Succeed())
 			})
 			It("Protects the secret with an additional finalizer", func() {
 				Expect(finalizerPresent(secretNames[0], util.SecretFormatVelero)).Should(BeTrue())
@@ -546,7 +552,8 @@ var _ = Describe("Secrets_Util", func() {
 					tstNamespace,
 					tstNamespace,
 					util.SecretFormatVelero,
-					veleroNS)).Should(HaveOccurred())
+					veleroNS,
+					nil)).Should(HaveOccurred())
 			})
 			It("Cleans up the associated velero policy for the secret", func() {
 				Expect(plRuleAbsent(plRuleNameV[0], tstNamespace)).Should(BeTrue())
@@ -562,7 +569,8 @@ var _ = Describe("Secrets_Util", func() {
 					tstNamespace,
 					tstNamespace,
 					util.SecretFormatRamen,
-					veleroNS)).Should(HaveOccurred())
+					veleroNS,
+					s3SecretKeys)).Should(HaveOccurred())
 			})
 			It("No longer protects the secret with a finalizer", func() {
 				By("Ensuring secret is deleted")
@@ -575,3 +583,78 @@ var _ = Describe("Secrets_Util", func() {
 		})
 	})
 })
+
+var _ = Describe("Secrets_Util object store providers", func() {
+	const (
+		tstNamespace = "default"
+		clusterName  = "clusterEast"
+	)
+
+	// policySecretData returns the data of the secret the ramen format policy
+	// of the named secret delivers.
+	policySecretData := func(secretName string) map[string]string {
+		policyName, _, _, _ := util.GeneratePolicyResourceNames(secretName, util.SecretFormatRamen)
+		policy := &gppv1.Policy{}
+		Expect(k8sClient.Get(context.TODO(),
+			types.NamespacedName{Name: policyName, Namespace: tstNamespace}, policy)).To(Succeed())
+		Expect(policy.Spec.PolicyTemplates).To(HaveLen(1))
+
+		configPolicy := &cpcv1.ConfigurationPolicy{}
+		Expect(json.Unmarshal(policy.Spec.PolicyTemplates[0].ObjectDefinition.Raw, configPolicy)).To(Succeed())
+		Expect(configPolicy.Spec.ObjectTemplates).To(HaveLen(1))
+
+		secret := struct {
+			Data map[string]string `json:"data"`
+		}{}
+		Expect(json.Unmarshal(configPolicy.Spec.ObjectTemplates[0].ObjectDefinition.Raw, &secret)).To(Succeed())
+
+		return secret.Data
+	}
+
+	secretAdd := func(secretName string, provider rmn.ObjectStoreProvider) {
+		Expect(secretsUtil.AddSecretToCluster(secretName, clusterName, tstNamespace, tstNamespace,
+			util.SecretFormatRamen, "", util.ObjectStoreSecretKeys(provider))).To(Succeed())
+	}
+
+	secretCreate := func(secretName string) {
+		Expect(k8sClient.Create(context.TODO(), &corev1.Secret{
+			ObjectMeta: metav1.ObjectMeta{Name: secretName, Namespace: tstNamespace},
+		})).To(Succeed())
+		DeferCleanup(func() {
+			Expect(secretsUtil.RemoveSecretFromCluster(secretName, clusterName, tstNamespace,
+				util.SecretFormatRamen)).To(Succeed())
+		})
+	}
+
+	DescribeTable("delivers the keys of the secret of the object store provider",
+		func(provider rmn.ObjectStoreProvider, keys []string) {
+			secretName := "secret-" + string(provider)
+			secretCreate(secretName)
+			secretAdd(secretName, provider)
+
+			data := policySecretData(secretName)
+			Expect(data).To(HaveLen(len(keys)))
+
+			for _, key := range keys {
+				Expect(data).To(HaveKeyWithValue(key,
+					`{{hub fromSecret "`+tstNamespace+`" "`+secretName+`" "`+key+`" hub}}`))
+			}
+		},
+		Entry("s3", rmn.ObjectStoreProviderS3, []string{"AWS_ACCESS_KEY_ID", "AWS_SECRET_ACCESS_KEY"}),
+		Entry("azure", rmn.ObjectStoreProviderAzure,
+			[]string{"AZURE_STORAGE_ACCOUNT_NAME", "AZURE_STORAGE_ACCOUNT_KEY"}),
+		Entry("gcs", rmn.ObjectStoreProviderGCS, []string{"GOOGLE_SERVICE_ACCOUNT_KEY"}),
+	)
+
+	It("updates the keys a policy delivers when they change", func() {
+		const secretName = "secret-provider-changed"
+
+		secretCreate(secretName)
+		secretAdd(secretName, rmn.ObjectStoreProviderS3)
+		Expect(policySecretData(secretName)).To(HaveKey("AWS_ACCESS_KEY_ID"))
+
+		secretAdd(secretName, rmn.ObjectStoreProviderGCS)
+		Expect(policySecretData(secretName)).To(SatisfyAll(
+			HaveLen(1), HaveKey("GOOGLE_SERVICE_ACCOUNT_KEY")))
+	})
+})
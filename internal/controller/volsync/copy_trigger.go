@@ -0,0 +1,139 @@
+// SPDX-FileCopyrightText: The RamenDR authors
+// SPDX-License-Identifier: Apache-2.0
+
+package volsync
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/apimachinery/pkg/util/wait"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	volsyncv1alpha1 "github.com/backube/volsync/api/v1alpha1"
+	"github.com/ramendr/ramen/internal/controller/util"
+)
+
+// copyTriggerTimeout bounds how long the applications are frozen for VolSync to
+// copy their volumes.
+const copyTriggerTimeout = 30 * time.Second
+
+// Freezer freezes, and unfreezes, the applications whose volumes are copied for
+// a sync, e.g. the guests of VMs, for their copies to be application consistent.
+type Freezer interface {
+	Freeze(ctx context.Context) error
+	Unfreeze(ctx context.Context) error
+}
+
+// PVCCopyTriggerUse has VolSync, if use is true, wait for the copy trigger of
+// the given PVC to be updated before it copies the PVC for a sync, so that its
+// application is frozen while it does, or else not wait.
+func (v *VSHandler) PVCCopyTriggerUse(pvcNamespacedName types.NamespacedName, use bool) error {
+	pvc, err := v.getPVC(pvcNamespacedName)
+	if err != nil {
+		return err
+	}
+
+	if _, used := pvc.GetAnnotations()[volsyncv1alpha1.UseCopyTriggerAnnotation]; used == use {
+		return nil
+	}
+
+	if use {
+		util.AddAnnotation(pvc, volsyncv1alpha1.UseCopyTriggerAnnotation, "")
+	} else {
+		delete(pvc.Annotations, volsyncv1alpha1.UseCopyTriggerAnnotation)
+	}
+
+	if err := v.client.Update(v.ctx, pvc); err != nil {
+		return fmt.Errorf("pvc %s copy trigger use %t update: %w", pvcNamespacedName, use, err)
+	}
+
+	v.log.Info("PVC copy trigger use updated", "pvc", pvcNamespacedName, "use", use)
+
+	return nil
+}
+
+// CopiesFrozenTrigger triggers VolSync to copy those of the given PVCs whose
+// copy for a sync waits for their copy trigger, with their applications frozen
+// until it has copied them all, or copyTriggerTimeout elapses.
+func (v *VSHandler) CopiesFrozenTrigger(pvcNamespacedNames []types.NamespacedName, freezer Freezer) error {
+	pvcs := make([]*corev1.PersistentVolumeClaim, 0, len(pvcNamespacedNames))
+
+	for _, pvcNamespacedName := range pvcNamespacedNames {
+		pvc, err := v.getPVC(pvcNamespacedName)
+		if err != nil {
+			return err
+		}
+
+		if pvcCopyTriggerWaiting(pvc) {
+			pvcs = append(pvcs, pvc)
+		}
+	}
+
+	if len(pvcs) == 0 {
+		return nil
+	}
+
+	if err := freezer.Freeze(v.ctx); err != nil {
+		return fmt.Errorf("freeze for copies: %w", err)
+	}
+
+	defer func() {
+		if err := freezer.Unfreeze(v.ctx); err != nil {
+			v.log.Error(err, "Failed to unfreeze after copies")
+		}
+	}()
+
+	trigger := time.Now().UTC().Format(time.RFC3339Nano)
+
+	for _, pvc := range pvcs {
+		pvc.Annotations[volsyncv1alpha1.CopyTriggerAnnotation] = trigger
+
+		if err := v.client.Update(v.ctx, pvc); err != nil {
+			return fmt.Errorf("pvc %s/%s copy trigger update: %w", pvc.GetNamespace(), pvc.GetName(), err)
+		}
+	}
+
+	v.log.Info("PVC copies triggered", "trigger", trigger, "count", len(pvcs))
+
+	return v.copiesWait(pvcs, trigger)
+}
+
+// pvcCopyTriggerWaiting returns whether VolSync waits for the given PVC's copy
+// trigger to be updated to copy it.
+func pvcCopyTriggerWaiting(pvc *corev1.PersistentVolumeClaim) bool {
+	annotations := pvc.GetAnnotations()
+	if _, ok := annotations[volsyncv1alpha1.UseCopyTriggerAnnotation]; !ok {
+		return false
+	}
+
+	return annotations[volsyncv1alpha1.LatestCopyStatusAnnotation] ==
+		volsyncv1alpha1.LatestCopyStatusValueWaitingForTrigger &&
+		annotations[volsyncv1alpha1.CopyTriggerAnnotation] == annotations[volsyncv1alpha1.LatestCopyTriggerAnnotation]
+}
+
+// copiesWait waits for VolSync to have copied each of the given PVCs for the
+// given copy trigger.
+func (v *VSHandler) copiesWait(pvcs []*corev1.PersistentVolumeClaim, trigger string) error {
+	return wait.PollUntilContextTimeout(v.ctx, time.Second, copyTriggerTimeout, true,
+		func(ctx context.Context) (bool, error) {
+			for _, pvc := range pvcs {
+				if pvc.GetAnnotations()[volsyncv1alpha1.LatestCopyTriggerAnnotation] == trigger {
+					continue
+				}
+
+				if err := v.client.Get(ctx, client.ObjectKeyFromObject(pvc), pvc); err != nil {
+					return false, err
+				}
+
+				if pvc.GetAnnotations()[volsyncv1alpha1.LatestCopyTriggerAnnotation] != trigger {
+					return false, nil
+				}
+			}
+
+			return true, nil
+		})
+}
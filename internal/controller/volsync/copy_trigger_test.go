@@ -0,0 +1,119 @@
+// SPDX-FileCopyrightText: The RamenDR authors
+// SPDX-License-Identifier: Apache-2.0
+
+package volsync_test
+
+import (
+	"context"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+	"sigs.k8s.io/controller-runtime/pkg/client/interceptor"
+
+	volsyncv1alpha1 "github.com/backube/volsync/api/v1alpha1"
+	ramendrv1alpha1 "github.com/ramendr/ramen/api/v1alpha1"
+	"github.com/ramendr/ramen/internal/controller/volsync"
+)
+
+// copyTriggerFreezer records whether the applications are frozen when each PVC
+// copy is triggered.
+type copyTriggerFreezer struct {
+	frozen, unfrozen int
+	frozenTriggers   []string
+}
+
+func (f *copyTriggerFreezer) Freeze(context.Context) error {
+	f.frozen++
+
+	return nil
+}
+
+func (f *copyTriggerFreezer) Unfreeze(context.Context) error {
+	f.unfrozen++
+
+	return nil
+}
+
+var _ = Describe("VolSync Handler - copy trigger", func() {
+	var (
+		c         client.Client
+		vsHandler *volsync.VSHandler
+		freezer   *copyTriggerFreezer
+		waiting   = types.NamespacedName{Namespace: "app", Name: "waiting"}
+		copying   = types.NamespacedName{Namespace: "app", Name: "copying"}
+	)
+
+	pvcGet := func(pvcNamespacedName types.NamespacedName) *corev1.PersistentVolumeClaim {
+		pvc := &corev1.PersistentVolumeClaim{}
+		Expect(c.Get(context.TODO(), pvcNamespacedName, pvc)).To(Succeed())
+
+		return pvc
+	}
+
+	BeforeEach(func() {
+		freezer = &copyTriggerFreezer{}
+		pvc := func(pvcNamespacedName types.NamespacedName, status string) *corev1.PersistentVolumeClaim {
+			return &corev1.PersistentVolumeClaim{ObjectMeta: metav1.ObjectMeta{
+				Namespace: pvcNamespacedName.Namespace, Name: pvcNamespacedName.Name,
+				Annotations: map[string]string{
+					volsyncv1alpha1.UseCopyTriggerAnnotation:   "",
+					volsyncv1alpha1.LatestCopyStatusAnnotation: status,
+				},
+			}}
+		}
+
+		// VolSync copies a PVC, once its copy trigger is updated, while the
+		// applications are frozen, and records the trigger it copied it for
+		c = fake.NewClientBuilder().
+			WithObjects(
+				pvc(waiting, volsyncv1alpha1.LatestCopyStatusValueWaitingForTrigger),
+				pvc(copying, volsyncv1alpha1.LatestCopyStatusValueInProgress),
+			).
+			WithInterceptorFuncs(interceptor.Funcs{
+				Update: func(ctx context.Context, c client.WithWatch, obj client.Object, opts ...client.UpdateOption) error {
+					annotations := obj.GetAnnotations()
+					if trigger, ok := annotations[volsyncv1alpha1.CopyTriggerAnnotation]; ok &&
+						freezer.frozen > freezer.unfrozen {
+						freezer.frozenTriggers = append(freezer.frozenTriggers, obj.GetName())
+						annotations[volsyncv1alpha1.LatestCopyTriggerAnnotation] = trigger
+						annotations[volsyncv1alpha1.LatestCopyStatusAnnotation] =
+							volsyncv1alpha1.LatestCopyStatusValueCompleted
+					}
+
+					return c.Update(ctx, obj, opts...)
+				},
+			}).
+			Build()
+		owner := &ramendrv1alpha1.VolumeReplicationGroup{ObjectMeta: metav1.ObjectMeta{Namespace: "app", Name: "vrg"}}
+		vsHandler = volsync.NewVSHandler(context.TODO(), c, GinkgoLogr, owner, nil, "", "", false)
+	})
+
+	It("has VolSync wait for a PVC's copy trigger only while it is used", func() {
+		Expect(vsHandler.PVCCopyTriggerUse(waiting, false)).To(Succeed())
+		Expect(pvcGet(waiting).Annotations).NotTo(HaveKey(volsyncv1alpha1.UseCopyTriggerAnnotation))
+
+		Expect(vsHandler.PVCCopyTriggerUse(waiting, true)).To(Succeed())
+		Expect(pvcGet(waiting).Annotations).To(HaveKey(volsyncv1alpha1.UseCopyTriggerAnnotation))
+	})
+
+	It("triggers the copies of the PVCs waiting for it with the applications frozen", func() {
+		Expect(vsHandler.CopiesFrozenTrigger([]types.NamespacedName{waiting, copying}, freezer)).To(Succeed())
+		Expect(freezer.frozen).To(Equal(1))
+		Expect(freezer.unfrozen).To(Equal(1))
+		Expect(freezer.frozenTriggers).To(Equal([]string{waiting.Name}))
+		Expect(pvcGet(copying).Annotations).NotTo(HaveKey(volsyncv1alpha1.CopyTriggerAnnotation))
+	})
+
+	It("does not freeze the applications if no PVC copy waits for its trigger", func() {
+		Expect(vsHandler.CopiesFrozenTrigger([]types.NamespacedName{waiting}, freezer)).To(Succeed())
+		Expect(vsHandler.CopiesFrozenTrigger([]types.NamespacedName{waiting, copying}, freezer)).To(Succeed())
+		Expect(freezer.frozen).To(Equal(1))
+		Expect(freezer.unfrozen).To(Equal(1))
+	})
+})
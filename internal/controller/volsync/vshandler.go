@@ -85,6 +85,7 @@ type VSHandler struct {
 	volumeSnapshotClassList     *snapv1.VolumeSnapshotClassList
 	vrgInAdminNamespace         bool
 	workloadStatus              string
+	remoteNamespaces            map[string]string // remote namespace names of those named differently locally
 }
 
 func NewVSHandler(ctx context.Context, client client.Client, log logr.Logger, owner metav1.Object,
@@ -118,6 +119,20 @@ func (v *VSHandler) SetWorkloadStatus(status string) {
 	v.workloadStatus = status
 }
 
+// SetRemoteNamespaceMapping sets the names of the namespaces on the remote
+// cluster, of the local namespaces named differently there.
+func (v *VSHandler) SetRemoteNamespaceMapping(remoteNamespaces map[string]string) {
+	v.remoteNamespaces = remoteNamespaces
+}
+
+func (v *VSHandler) remoteNamespaceName(namespaceName string) string {
+	if remoteNamespaceName, ok := v.remoteNamespaces[namespaceName]; ok {
+		return remoteNamespaceName
+	}
+
+	return namespaceName
+}
+
 // returns replication destination only if create/update is successful and the RD is considered available.
 // Callers should assume getting a nil replication destination back means they should retry/requeue.
 //
@@ -444,8 +459,9 @@ func (v *VSHandler) createOrUpdateRS(rsSpec ramendrv1alpha1.VolSyncReplicationSo
 	}
 
 	// Remote service address created for the ReplicationDestination on the secondary
-	// The secondary namespace will be the same as primary namespace so use the vrg.Namespace
-	remoteAddress := getRemoteServiceNameForRDFromPVCName(rsSpec.ProtectedPVC.Name, rsSpec.ProtectedPVC.Namespace)
+	// The secondary namespace is the same as the PVC's namespace, unless it is mapped to another
+	remoteAddress := getRemoteServiceNameForRDFromPVCName(rsSpec.ProtectedPVC.Name,
+		v.remoteNamespaceName(rsSpec.ProtectedPVC.Namespace))
 
 	rs := &volsyncv1alpha1.ReplicationSource{
 		ObjectMeta: metav1.ObjectMeta{
@@ -18,7 +18,6 @@ import (
 	volrep "github.com/csi-addons/kubernetes-csi-addons/api/replication.storage/v1alpha1"
 	snapv1 "github.com/kubernetes-csi/external-snapshotter/client/v8/apis/volumesnapshot/v1"
 	"github.com/ramendr/ramen/internal/controller/kubeobjects"
-	"github.com/ramendr/ramen/internal/controller/kubeobjects/velero"
 	"github.com/ramendr/ramen/internal/controller/util"
 	"golang.org/x/exp/maps" // TODO replace with "maps" in go1.21+
 	corev1 "k8s.io/api/core/v1"
@@ -50,15 +49,20 @@ import (
 // VolumeReplicationGroupReconciler reconciles a VolumeReplicationGroup object
 type VolumeReplicationGroupReconciler struct {
 	client.Client
-	APIReader           client.Reader
-	Log                 logr.Logger
-	ObjStoreGetter      ObjectStoreGetter
-	Scheme              *runtime.Scheme
-	eventRecorder       *util.EventReporter
-	kubeObjects         kubeobjects.RequestsManager
-	RateLimiter         *workqueue.TypedRateLimiter[reconcile.Request]
-	veleroCRsAreWatched bool
-	recipeRetries       sync.Map
+	APIReader      client.Reader
+	Log            logr.Logger
+	ObjStoreGetter ObjectStoreGetter
+	Scheme         *runtime.Scheme
+	eventRecorder  *util.EventReporter
+	kubeObjects    kubeobjects.RequestsManager
+	// kubeObjectsChangeWatcher is nil if kube object protection is disabled
+	kubeObjectsChangeWatcher *kubeObjectsChangeWatcher
+	RateLimiter              *workqueue.TypedRateLimiter[reconcile.Request]
+	kubeObjectsProtectable   bool
+	recipeRetries            sync.Map
+	// s3ManifestsPending holds the manifest entries that reconciles failed to
+	// record, by s3ManifestsPendingKey
+	s3ManifestsPending sync.Map
 }
 
 // SetupWithManager sets up the controller with the Manager.
@@ -118,10 +122,19 @@ func (r *VolumeReplicationGroupReconciler) SetupWithManager(
 		r.Log.Info("VolSync disabled; don't own volsync resources")
 	}
 
-	r.kubeObjects = velero.RequestsManager{}
+	kubeObjects, nativeKubeObjects, err := r.kubeObjectsRequestsManager(mgr, ramenConfig)
+	if err != nil {
+		return err
+	}
+
+	r.kubeObjects = kubeObjects
 
 	if !ramenConfig.KubeObjectProtection.Disabled {
-		ctrlBuilder = r.addKubeObjectsOwnsAndWatches(ctrlBuilder)
+		ctrlBuilder = r.addKubeObjectsOwnsAndWatches(ctrlBuilder, ramenConfig)
+
+		if ctrlBuilder, err = r.addKubeObjectsChangeWatches(mgr, ctrlBuilder, nativeKubeObjects); err != nil {
+			return err
+		}
 	} else {
 		r.Log.Info("Kube object protection disabled; don't watch kube objects requests")
 	}
@@ -247,6 +260,12 @@ func updateEventDecision(oldPVC, newPVC *corev1.PersistentVolumeClaim, log logr.
 		return requeue
 	}
 
+	if pvcCopyTriggerWaitingStarted(oldPVC, newPVC) {
+		predicateLog.Info("Reconciling due to VolSync waiting for copy trigger")
+
+		return requeue
+	}
+
 	// If finalizers change then deep equal of spec fails to catch it, we may want more
 	// conditions here, compare finalizers and also status.phase to catch bound PVCs
 	if !reflect.DeepEqual(oldPVC.Spec, newPVC.Spec) {
@@ -294,6 +313,15 @@ func pvcFinalizerAddedOrRemoved(oldPVC, newPVC *corev1.PersistentVolumeClaim) (b
 	return added || removed, added, removed
 }
 
+// pvcCopyTriggerWaitingStarted returns whether VolSync started to wait for the
+// copy trigger of the PVC to be updated, for its application to be frozen.
+func pvcCopyTriggerWaitingStarted(oldPVC, newPVC *corev1.PersistentVolumeClaim) bool {
+	const waiting = volsyncv1alpha1.LatestCopyStatusValueWaitingForTrigger
+
+	return oldPVC.GetAnnotations()[volsyncv1alpha1.LatestCopyStatusAnnotation] != waiting &&
+		newPVC.GetAnnotations()[volsyncv1alpha1.LatestCopyStatusAnnotation] == waiting
+}
+
 func pvcAnnotationAdded(oldPVC, newPVC *corev1.PersistentVolumeClaim) (bool, bool, bool) {
 	before := oldPVC.GetAnnotations()
 	after := newPVC.GetAnnotations()
@@ -381,10 +409,11 @@ func filterPVC(reader client.Reader, pvc *corev1.PersistentVolumeClaim, log logr
 // +kubebuilder:rbac:groups=storage.k8s.io,resources=storageclasses,verbs=get;list;watch;create;update
 // +kubebuilder:rbac:groups=storage.k8s.io,resources=volumeattachments,verbs=get;list;watch
 // +kubebuilder:rbac:groups=core,resources=pods,verbs=get;list;watch
-// +kubebuilder:rbac:groups=batch,resources=jobs,verbs=get;list;watch
-// +kubebuilder:rbac:groups=apps,resources=deployments,verbs=get;list;watch
+// +kubebuilder:rbac:groups=batch,resources=jobs,verbs=get;list;watch;create;delete
+// +kubebuilder:rbac:groups=apps,resources=deployments,verbs=get;list;watch;patch
 // +kubebuilder:rbac:groups=apps,resources=replicasets,verbs=get;list;watch
-// +kubebuilder:rbac:groups=apps,resources=statefulsets,verbs=get;list;watch
+// +kubebuilder:rbac:groups=apps,resources=statefulsets,verbs=get;list;watch;patch
+// +kubebuilder:rbac:groups=apps.openshift.io,resources=deploymentconfigs,verbs=get;list;watch;patch
 // +kubebuilder:rbac:groups=core,resources=persistentvolumeclaims,verbs=get;list;watch;create;update;patch;delete
 // +kubebuilder:rbac:groups=core,resources=persistentvolumes,verbs=get;list;watch;update;patch;create
 // +kubebuilder:rbac:groups=volsync.backube,resources=replicationdestinations,verbs=get;list;watch;create;update;patch;delete
@@ -395,10 +424,13 @@ func filterPVC(reader client.Reader, pvc *corev1.PersistentVolumeClaim, log logr
 // +kubebuilder:rbac:groups=core,resources=events,verbs=get;create;patch;update
 // +kubebuilder:rbac:groups=core,resources=secrets,verbs=get;list;watch;create;update;patch;delete
 // +kubebuilder:rbac:groups=ramendr.openshift.io,resources=recipes,verbs=get;list;watch
-// +kubebuilder:rbac:groups="",resources=configmaps,verbs=list;watch
+// +kubebuilder:rbac:groups="",resources=configmaps,verbs=get;list;watch
 // +kubebuilder:rbac:groups="apiextensions.k8s.io",resources=customresourcedefinitions,verbs=get;list;watch
 // +kubebuilder:rbac:groups=core,resources=pods/exec,verbs=create
+// +kubebuilder:rbac:groups=core,resources=pods/log,verbs=get
 // +kubebuilder:rbac:groups="kubevirt.io",resources=virtualmachines,verbs=get;list
+// +kubebuilder:rbac:groups="kubevirt.io",resources=virtualmachineinstances,verbs=get;list
+// +kubebuilder:rbac:groups="subresources.kubevirt.io",resources=virtualmachineinstances/freeze;virtualmachineinstances/unfreeze,verbs=update
 
 // Reconcile is part of the main kubernetes reconciliation loop which aims to
 // move the current state of the cluster closer to the desired state.
@@ -437,6 +469,12 @@ func (r *VolumeReplicationGroupReconciler) Reconcile(ctx context.Context, req ct
 		if k8serrors.IsNotFound(err) {
 			log.Info("Resource not found")
 
+			if r.kubeObjectsChangeWatcher != nil {
+				r.kubeObjectsChangeWatcher.unwatch(req.NamespacedName)
+			}
+
+			s3ManifestsPendingForget(&r.s3ManifestsPending, req.NamespacedName.String())
+
 			return ctrl.Result{}, nil
 		}
 
@@ -454,7 +492,7 @@ func (r *VolumeReplicationGroupReconciler) Reconcile(ctx context.Context, req ct
 	v.ramenConfig = ramenConfig
 	adminNamespaceVRG := vrgInAdminNamespace(v.instance, v.ramenConfig)
 
-	if adminNamespaceVRG && !r.veleroCRsAreWatched {
+	if adminNamespaceVRG && !r.kubeObjectsProtectable {
 		return ctrl.Result{},
 			fmt.Errorf("VRG {%s/%s} with kube object protection doesn't work if velero/oadp is not installed. "+
 				"Please install velero/oadp and restart the operator", v.instance.Namespace, v.instance.Name)
@@ -463,6 +501,7 @@ func (r *VolumeReplicationGroupReconciler) Reconcile(ctx context.Context, req ct
 	v.volSyncHandler = volsync.NewVSHandler(ctx, r.Client, log, v.instance,
 		v.instance.Spec.Async, cephFSCSIDriverNameOrDefault(v.ramenConfig),
 		volSyncDestinationCopyMethodOrDefault(v.ramenConfig), adminNamespaceVRG)
+	v.volSyncHandler.SetRemoteNamespaceMapping(vrgNamespaceMapping(*v.instance).remoteMapping())
 
 	if v.instance.Status.ProtectedPVCs == nil {
 		v.instance.Status.ProtectedPVCs = []ramendrv1alpha1.ProtectedPVC{}
@@ -474,6 +513,15 @@ func (r *VolumeReplicationGroupReconciler) Reconcile(ctx context.Context, req ct
 		"Initializing VolumeReplicationGroup")
 
 	res := v.processVRG()
+
+	if err := v.s3ManifestsFlush(); err != nil {
+		log.Error(err, "Failed to update S3 manifests")
+		util.ReportIfNotPresent(r.eventRecorder, v.instance, corev1.EventTypeWarning,
+			util.EventReasonUploadFailed, err.Error())
+
+		res.Requeue = true
+	}
+
 	delayResetIfRequeueTrue(&res, v.log)
 	log.Info("Reconcile return", "result", res,
 		"VolRep count", len(v.volRepPVCs), "VolSync count", len(v.volSyncPVCs))
@@ -494,6 +542,7 @@ type VRGInstance struct {
 	savedInstanceStatus  ramendrv1alpha1.VolumeReplicationGroupStatus
 	ramenConfig          *ramendrv1alpha1.RamenConfig
 	recipeElements       util.RecipeElements
+	hookOutputParameters map[string][]string
 	volRepPVCs           []corev1.PersistentVolumeClaim
 	volSyncPVCs          []corev1.PersistentVolumeClaim
 	replClassList        *volrep.VolumeReplicationClassList
@@ -505,6 +554,8 @@ type VRGInstance struct {
 	namespacedName       string
 	volSyncHandler       *volsync.VSHandler
 	objectStorers        map[string]cachedObjectStorer
+	objectStorersMutex   sync.Mutex
+	s3ManifestBatches    map[string]*s3ManifestBatch
 	s3StoreAccessors     []s3StoreAccessor
 	result               ctrl.Result
 }
@@ -568,15 +619,24 @@ func (v *VRGInstance) processVRG() ctrl.Result {
 		}
 	}
 
+	if err := namespaceMappingsValidate(v.instance, vrgInAdminNamespace(v.instance, v.ramenConfig)); err != nil {
+		return v.invalid(err, "VolumeReplicationGroup namespace mappings are invalid", false)
+	}
+
 	var err error
 
 	v.recipeElements, err = RecipeElementsGet(v.ctx, v.reconciler.Client, *v.instance, *v.ramenConfig, v.log)
+	v.recipeValidatedConditionSet(err)
+
 	if err != nil {
 		return v.invalid(err, "Failed to get recipe", false)
 	}
 
 	v.log.Info("Recipe", "elements", v.recipeElements)
 
+	hookResultsPrune(&v.instance.Status.KubeObjectProtection.HookResults, v.recipeElements.CaptureWorkflow,
+		v.recipeElements.RecoverWorkflow)
+
 	if err := v.updatePVCList(); err != nil {
 		return v.invalid(err, "Failed to process list of PVCs to protect", true)
 	}
@@ -1532,6 +1592,7 @@ func (v *VRGInstance) processAsSecondary() ctrl.Result {
 	defer v.log.Info("Exiting processing VolumeReplicationGroup")
 
 	v.instance.Status.LastGroupSyncTime = nil
+	v.kubeObjectsChangeWatchUpdate()
 
 	if v.resetInitialStatusAsSecondary() {
 		v.result.Requeue = true
@@ -1540,6 +1601,7 @@ func (v *VRGInstance) processAsSecondary() ctrl.Result {
 	}
 
 	result := v.reconcileAsSecondary()
+	v.kubeObjectsRecoverVerify(&result)
 
 	// If requeue is false, then VRG was successfully processed as Secondary.
 	// Hence the event to be generated is Success of type normal.
@@ -1569,8 +1631,16 @@ func (v *VRGInstance) reconcileAsSecondary() ctrl.Result {
 	// Clear the conditions only if there are no more work as secondary and the RDSpec is not empty.
 	// Note: When using VolSync, we preserve the secondary and we need the status of the VRG to be
 	// clean. In all other cases, the VRG will be deleted and we don't care about the its conditions.
+	// The RecipeValidated condition is kept, since it is not about the work as secondary.
 	if !result.Requeue && len(v.instance.Spec.VolSync.RDSpec) > 0 {
-		v.instance.Status.Conditions = []metav1.Condition{}
+		conditions := []metav1.Condition{}
+
+		if recipeValidated := util.FindCondition(v.instance.Status.Conditions,
+			VRGConditionTypeRecipeValidated); recipeValidated != nil {
+			conditions = append(conditions, *recipeValidated)
+		}
+
+		v.instance.Status.Conditions = conditions
 	}
 
 	if !result.Requeue && v.isVMRecipeProtection() {
@@ -2127,7 +2197,21 @@ func (r *VolumeReplicationGroupReconciler) addVolsyncOwnsAndWatches(ctrlBuilder
 	return ctrlBuilder
 }
 
-func (r *VolumeReplicationGroupReconciler) addKubeObjectsOwnsAndWatches(ctrlBuilder *builder.Builder) *builder.Builder {
+func (r *VolumeReplicationGroupReconciler) addKubeObjectsOwnsAndWatches(
+	ctrlBuilder *builder.Builder, ramenConfig *ramendrv1alpha1.RamenConfig,
+) *builder.Builder {
+	objectToReconcileRequestsMapper := objectToReconcileRequestsMapper{reader: r.Client, log: ctrl.Log}
+
+	// Native requests are complete once created, so there is nothing to watch
+	if kubeObjectProtectionEngineOrDefault(ramenConfig) == ramendrv1alpha1.KubeObjectProtectionEngineNative {
+		r.Log.Info("Kube object protection enabled; native engine")
+		recipesWatch(ctrlBuilder, objectToReconcileRequestsMapper)
+
+		r.kubeObjectsProtectable = true
+
+		return ctrlBuilder
+	}
+
 	r.Log.Info("Kube object protection enabled; watch kube objects requests")
 
 	// Find if velero CRDs are present in the cluster
@@ -2157,10 +2241,9 @@ func (r *VolumeReplicationGroupReconciler) addKubeObjectsOwnsAndWatches(ctrlBuil
 	kubeObjectsRequestsWatch(ctrlBuilder, r.Scheme, r.kubeObjects)
 
 	// watch for recipe objects
-	objectToReconcileRequestsMapper := objectToReconcileRequestsMapper{reader: r.Client, log: ctrl.Log}
 	recipesWatch(ctrlBuilder, objectToReconcileRequestsMapper)
 
-	r.veleroCRsAreWatched = true
+	r.kubeObjectsProtectable = true
 
 	return ctrlBuilder
 }
@@ -4,8 +4,12 @@
 package controllers
 
 import (
+	"context"
 	"errors"
 	"fmt"
+	"maps"
+	"math"
+	"slices"
 	"strconv"
 	"strings"
 	"time"
@@ -14,14 +18,24 @@ import (
 	ramen "github.com/ramendr/ramen/api/v1alpha1"
 	"github.com/ramendr/ramen/internal/controller/hooks"
 	"github.com/ramendr/ramen/internal/controller/kubeobjects"
+	"github.com/ramendr/ramen/internal/controller/kubeobjects/native"
+	"github.com/ramendr/ramen/internal/controller/kubeobjects/velero"
 	"github.com/ramendr/ramen/internal/controller/util"
 	Recipe "github.com/ramendr/recipe/api/v1alpha1"
+	corev1 "k8s.io/api/core/v1"
 	k8serrors "k8s.io/apimachinery/pkg/api/errors"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/runtime"
 	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/client-go/discovery"
+	"k8s.io/client-go/dynamic"
+	"k8s.io/client-go/metadata"
+	"k8s.io/utils/ptr"
 	ctrl "sigs.k8s.io/controller-runtime"
 	"sigs.k8s.io/controller-runtime/pkg/builder"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/handler"
+	"sigs.k8s.io/controller-runtime/pkg/source"
 )
 
 var ErrWorkflowNotFound = fmt.Errorf("backup or restore workflow not found")
@@ -63,6 +77,9 @@ func kubeObjectsRecoverName(prefix string, groupNumber int) string {
 }
 
 func (v *VRGInstance) kubeObjectsProtectPrimary(result *ctrl.Result) {
+	v.kubeObjectsChangeWatchUpdate()
+	v.kubeObjectsRecoverVerificationUpdate()
+
 	if v.instance.Spec.PrepareForFinalSync || v.instance.Spec.RunFinalSync {
 		v.log.Info("Skipping kube objects capture in final sync case")
 
@@ -97,6 +114,20 @@ func (v *VRGInstance) kubeObjectsProtect(
 		return
 	}
 
+	if err := v.kubeObjectsStoresEngineCheck(); err != nil {
+		v.log.Error(err, "Kube objects capture store unsupported")
+		v.kubeObjectsCaptureStatusFalse(VRGConditionReasonError, err.Error())
+
+		return
+	}
+
+	if err := v.kubeObjectsSecretPoliciesEngineCheck(); err != nil {
+		v.log.Error(err, "Kube objects capture secret policies unsupported")
+		v.kubeObjectsCaptureStatusFalse(VRGConditionReasonError, err.Error())
+
+		return
+	}
+
 	vrg := v.instance
 	status := &vrg.Status.KubeObjectProtection
 
@@ -124,7 +155,7 @@ func (v *VRGInstance) kubeObjectsCaptureStartOrResumeOrDelay(
 	veleroNamespaceName := v.veleroNamespaceName()
 	vrg := v.instance
 	interval := kubeObjectsCaptureInterval(vrg.Spec.KubeObjectProtection)
-	number := 1 - captureToRecoverFrom.Number
+	number := kubeObjectsCaptureNumberNext(&vrg.Status.KubeObjectProtection)
 	log := v.log.WithValues("number", number)
 	pathName, capturePathName, namePrefix := kubeObjectsCapturePathNamesAndNamePrefix(
 		vrg.Namespace, vrg.Name, number, v.reconciler.kubeObjects)
@@ -162,7 +193,8 @@ func (v *VRGInstance) kubeObjectsCaptureStartOrResumeOrDelay(
 	captureStartConditionally(
 		v, result, captureToRecoverFrom.StartGeneration, time.Since(captureToRecoverFrom.StartTime.Time), interval,
 		func() {
-			if v.kubeObjectsCapturesDelete(result, number, capturePathName) != nil {
+			if v.kubeObjectsCapturesPrune(result) != nil ||
+				v.kubeObjectsCapturesDelete(result, number, capturePathName) != nil {
 				return
 			}
 
@@ -171,12 +203,99 @@ func (v *VRGInstance) kubeObjectsCaptureStartOrResumeOrDelay(
 	)
 }
 
+// kubeObjectsCaptureNumberNext returns a number greater than that of any
+// capture, so that a capture never overwrites a retained one.
+func kubeObjectsCaptureNumberNext(status *ramen.KubeObjectProtectionStatus) int64 {
+	var number int64
+
+	if status.CaptureToRecoverFrom != nil {
+		number = status.CaptureToRecoverFrom.Number
+	}
+
+	for _, capture := range status.Captures {
+		number = max(number, capture.Number)
+	}
+
+	return number + 1
+}
+
+// kubeObjectsCapturesRetained splits the given captures, most recent first, into
+// those retained as per the given retention, and those that are not.  The most
+// recent capture is always retained.
+func kubeObjectsCapturesRetained(
+	captures []ramen.KubeObjectsCaptureIdentifier,
+	retention *ramen.KubeObjectsCaptureRetention,
+	now time.Time,
+) (retained, expired []ramen.KubeObjectsCaptureIdentifier) {
+	count := int32(ramen.KubeObjectsCaptureRetentionCountDefault)
+	maxAge := time.Duration(0)
+
+	if retention != nil {
+		if retention.MaxAge != nil {
+			maxAge = retention.MaxAge.Duration
+			count = math.MaxInt32
+		}
+
+		if retention.Count != nil {
+			count = *retention.Count
+		}
+	}
+
+	for i, capture := range captures {
+		if i == 0 || (int32(i) < count && (maxAge == 0 || now.Sub(capture.EndTime.Time) <= maxAge)) {
+			retained = append(retained, capture)
+
+			continue
+		}
+
+		expired = append(expired, capture)
+	}
+
+	return retained, expired
+}
+
+// kubeObjectsCapturesPrune deletes the captures that are no longer retained
+// from the s3 stores, and from the VRG status.
+func (v *VRGInstance) kubeObjectsCapturesPrune(result *ctrl.Result) error {
+	vrg := v.instance
+	status := &vrg.Status.KubeObjectProtection
+
+	retained, expired := kubeObjectsCapturesRetained(status.Captures,
+		vrg.Spec.KubeObjectProtection.CaptureRetention, time.Now())
+
+	for _, capture := range expired {
+		pathName, _, _ := kubeObjectsCapturePathNamesAndNamePrefix(
+			vrg.Namespace, vrg.Name, capture.Number, v.reconciler.kubeObjects)
+
+		if err := v.kubeObjectsCapturesDelete(result, capture.Number, pathName); err != nil {
+			return err
+		}
+
+		v.log.Info("Kube objects capture pruned", "number", capture.Number, "end", capture.EndTime)
+	}
+
+	status.Captures = retained
+
+	return nil
+}
+
 func kubeObjectsCaptureStartConditionallyPrimary(
 	v *VRGInstance, result *ctrl.Result,
 	captureStartGeneration int64, captureStartTimeSince, captureStartInterval time.Duration,
 	captureStart func(),
 ) {
 	if delay := captureStartInterval - captureStartTimeSince; delay > 0 {
+		if changeDelay, changed := v.kubeObjectsCaptureOnChangeDelay(captureStartTimeSince); changed {
+			if changeDelay <= 0 {
+				v.log.Info("starting kube objects capture as kube objects changed")
+				captureStart()
+
+				return
+			}
+
+			delay = min(delay, changeDelay)
+		}
+
 		v.log.Info("delaying kube objects capture start as per capture interval", "delay", delay,
 			"interval", captureStartInterval)
 		delaySetIfLess(result, delay, v.log)
@@ -193,7 +312,9 @@ func (v *VRGInstance) kubeObjectsCapturesDelete(
 ) error {
 	// current s3 profiles may differ from those at capture time
 	for _, s3StoreAccessor := range v.s3StoreAccessors {
-		if err := s3StoreAccessor.ObjectStorer.DeleteObjectsWithKeyPrefix(pathName); err != nil {
+		if err := v.s3ObjectsLockedTolerate(
+			s3StoreAccessor.ObjectStorer.DeleteObjectsWithKeyPrefix(pathName),
+		); err != nil {
 			v.log.Error(err, "Kube objects capture s3 objects delete error",
 				"number", captureNumber,
 				"profile", s3StoreAccessor.S3ProfileName,
@@ -320,17 +441,18 @@ func (v *VRGInstance) executeCaptureSteps(result *ctrl.Result, pathName, capture
 
 		if cg.IsHook {
 			isEssentialStep = cg.Hook.Essential != nil && *cg.Hook.Essential
+			hook := v.kubeObjectsHookParametersExpand(cg.Hook)
 
-			executor, err1 := hooks.GetHookExecutor(cg.Hook, v.reconciler.APIReader, v.reconciler.Scheme,
-				v.recipeElements)
+			executor, err1 := hooks.GetHookExecutor(hook, v.reconciler.APIReader, v.reconciler.Client,
+				v.reconciler.Scheme, v.reconciler.Client.RESTMapper(), v.recipeElements)
 			if err1 != nil {
-				// continue if hook type is not supported. Supported types are "check" and "exec"
-				log1.Info("Hook type not supported", "hook", cg.Hook)
+				// continue if hook type is not one of hooks.SupportedTypes
+				log1.Info("Hook type not supported", "hook", hook)
 
 				continue
 			}
 
-			err = executor.Execute(log1)
+			err = v.kubeObjectsHookExecute(executor, hook, log1)
 		}
 
 		if !cg.IsHook {
@@ -393,7 +515,7 @@ func (v *VRGInstance) kubeObjectsGroupCapture(
 		request, ok := requests[requestName]
 		if !ok {
 			if _, err := v.reconciler.kubeObjects.ProtectRequestCreate(
-				v.ctx, v.reconciler.Client, v.log,
+				v.ctx, v.reconciler.Client, v.log, s3StoreAccessor.S3ProfileName,
 				s3StoreAccessor.S3CompatibleEndpoint, s3StoreAccessor.S3Bucket, s3StoreAccessor.S3Region,
 				pathName, s3StoreAccessor.VeleroNamespaceSecretKeyRef, s3StoreAccessor.CACertificates,
 				captureGroup.Spec, veleroNamespaceName, requestName,
@@ -472,6 +594,12 @@ func (v *VRGInstance) kubeObjectsCaptureComplete(
 	}
 
 	captureToRecoverFromIdentifierCurrent := *captureToRecoverFromIdentifier
+	captureDiff := &vrg.Status.KubeObjectProtection.CaptureDiff
+	captureDiffCurrent := *captureDiff
+	*captureDiff = v.kubeObjectsCaptureDiff(captureNumber, captureToRecoverFromIdentifierCurrent)
+	captureSecrets := &vrg.Status.KubeObjectProtection.CaptureSecrets
+	captureSecretsCurrent := *captureSecrets
+	*captureSecrets = v.kubeObjectsCaptureSecrets(captureNumber)
 	*captureToRecoverFromIdentifier = &ramen.KubeObjectsCaptureIdentifier{
 		Number:    captureNumber,
 		StartTime: startTime,
@@ -480,9 +608,20 @@ func (v *VRGInstance) kubeObjectsCaptureComplete(
 		StartGeneration: startGeneration,
 	}
 
+	captures := &vrg.Status.KubeObjectProtection.Captures
+	capturesCurrent := *captures
+
+	// Captures completed before they were listed are retained too
+	if len(capturesCurrent) == 0 && captureToRecoverFromIdentifierCurrent != nil {
+		capturesCurrent = []ramen.KubeObjectsCaptureIdentifier{*captureToRecoverFromIdentifierCurrent}
+	}
+
+	*captures = append([]ramen.KubeObjectsCaptureIdentifier{**captureToRecoverFromIdentifier}, capturesCurrent...)
+
 	v.vrgObjectProtectThrottled(
 		result,
 		func() {
+			v.kubeObjectsCaptureToRecoverFromReport(*captureToRecoverFromIdentifier)
 			v.kubeObjectsCaptureIdentifierUpdateComplete(
 				result,
 				captureStartConditionally,
@@ -494,10 +633,150 @@ func (v *VRGInstance) kubeObjectsCaptureComplete(
 		},
 		func() {
 			*captureToRecoverFromIdentifier = captureToRecoverFromIdentifierCurrent
+			*captures = capturesCurrent
+			*captureDiff = captureDiffCurrent
+			*captureSecrets = captureSecretsCurrent
 		},
 	)
 }
 
+const (
+	kubeObjectsInventoryName = "inventory"
+	kubeObjectsDiffName      = "diff"
+)
+
+// kubeObjectsCaptureDiff stores, with the numbered capture in each s3 store, an
+// inventory of the objects its groups captured to it and a report of the
+// differences from the inventory of the previous capture in it, if any, and
+// returns the counts of the first store's.  Failures are logged rather than
+// returned as the report is informational.
+func (v *VRGInstance) kubeObjectsCaptureDiff(
+	captureNumber int64, previous *ramen.KubeObjectsCaptureIdentifier,
+) *ramen.KubeObjectsCaptureDiff {
+	var captureDiff *ramen.KubeObjectsCaptureDiff
+
+	for _, s3StoreAccessor := range v.s3StoreAccessors {
+		diff := v.kubeObjectsCaptureStoreDiff(s3StoreAccessor, captureNumber, previous)
+		if captureDiff == nil {
+			captureDiff = diff
+		}
+	}
+
+	if captureDiff != nil && captureDiff.Added+captureDiff.Removed+ptr.Deref(captureDiff.Modified, 0) != 0 {
+		util.ReportIfNotPresent(v.reconciler.eventRecorder, v.instance, corev1.EventTypeNormal,
+			util.EventReasonKubeObjectsChanged, fmt.Sprintf(
+				"kube objects capture %d differs from capture %d: %d added, %d removed, %s",
+				captureNumber, previous.Number, captureDiff.Added, captureDiff.Removed,
+				kubeObjectsCaptureDiffModifiedMessage(captureDiff.Modified)))
+	}
+
+	return captureDiff
+}
+
+// kubeObjectsCaptureDiffModifiedMessage describes the count of the objects
+// modified, or that they are not counted, as they are not for Velero.
+func kubeObjectsCaptureDiffModifiedMessage(modified *int32) string {
+	if modified == nil {
+		return "modified not counted by Velero"
+	}
+
+	return fmt.Sprintf("%d modified", *modified)
+}
+
+func (v *VRGInstance) kubeObjectsCaptureStoreDiff(
+	s3StoreAccessor s3StoreAccessor, captureNumber int64, previous *ramen.KubeObjectsCaptureIdentifier,
+) *ramen.KubeObjectsCaptureDiff {
+	vrg := v.instance
+	objectStorer := s3StoreAccessor.ObjectStorer
+	log := v.log.WithValues("number", captureNumber, "profile", s3StoreAccessor.S3ProfileName)
+
+	inventory, err := v.kubeObjectsCaptureInventoryGet(s3StoreAccessor, captureNumber)
+	if err != nil {
+		log.Error(err, "Kube objects inventory error")
+
+		return nil
+	}
+
+	pathName, _, _ := kubeObjectsCapturePathNamesAndNamePrefix(
+		vrg.Namespace, vrg.Name, captureNumber, v.reconciler.kubeObjects)
+	if err := objectStorer.UploadObject(pathName+kubeObjectsInventoryName, inventory); err != nil {
+		log.Error(err, "Kube objects inventory upload error")
+	}
+
+	if previous == nil {
+		return nil
+	}
+
+	previousPathName, _, _ := kubeObjectsCapturePathNamesAndNamePrefix(
+		vrg.Namespace, vrg.Name, previous.Number, v.reconciler.kubeObjects)
+	previousInventory := kubeobjects.Inventory{}
+
+	if err := objectStorer.DownloadObject(previousPathName+kubeObjectsInventoryName, &previousInventory); err != nil {
+		log.Info("Kube objects previous inventory download error", "previous", previous.Number, "error", err)
+
+		return nil
+	}
+
+	diff := kubeobjects.InventoryDiffGet(previousInventory, inventory)
+	if err := objectStorer.UploadObject(pathName+kubeObjectsDiffName, diff); err != nil {
+		log.Error(err, "Kube objects capture diff upload error")
+	}
+
+	captureDiff := &ramen.KubeObjectsCaptureDiff{
+		Number:         captureNumber,
+		PreviousNumber: previous.Number,
+		Added:          int32(len(diff.Added)),
+		Removed:        int32(len(diff.Removed)),
+	}
+	if diff.Modified != nil {
+		captureDiff.Modified = ptr.To(int32(len(diff.Modified)))
+	}
+
+	log.Info("Kube objects capture diff", "previous", previous.Number,
+		"added", len(diff.Added), "removed", len(diff.Removed),
+		"modified", kubeObjectsCaptureDiffModifiedMessage(captureDiff.Modified))
+
+	return captureDiff
+}
+
+// kubeObjectsCaptureInventoryGet returns the inventory of the objects the
+// groups of the numbered capture stored in the given s3 store.
+func (v *VRGInstance) kubeObjectsCaptureInventoryGet(
+	s3StoreAccessor s3StoreAccessor, captureNumber int64,
+) (kubeobjects.Inventory, error) {
+	vrg := v.instance
+	pathName, _, namePrefix := kubeObjectsCapturePathNamesAndNamePrefix(
+		vrg.Namespace, vrg.Name, captureNumber, v.reconciler.kubeObjects)
+	inventory := kubeobjects.Inventory{}
+
+	for _, captureGroup := range v.recipeElements.CaptureWorkflow {
+		if captureGroup.IsHook {
+			continue
+		}
+
+		groupInventory, err := v.reconciler.kubeObjects.ProtectRequestInventoryGet(
+			s3StoreAccessor.ObjectStorer, pathName,
+			kubeObjectsCaptureName(namePrefix, captureGroup.Name, s3StoreAccessor.S3ProfileName),
+		)
+		if err != nil {
+			return nil, err
+		}
+
+		maps.Copy(inventory, groupInventory)
+	}
+
+	return inventory, nil
+}
+
+func (v *VRGInstance) kubeObjectsCaptureReportUpload(key string, report interface{}, log logr.Logger) {
+	for _, s3StoreAccessor := range v.s3StoreAccessors {
+		if err := s3StoreAccessor.ObjectStorer.UploadObject(key, report); err != nil {
+			log.Error(err, "Kube objects capture report upload error",
+				"key", key, "profile", s3StoreAccessor.S3ProfileName)
+		}
+	}
+}
+
 func (v *VRGInstance) kubeObjectsCaptureIdentifierUpdateComplete(
 	result *ctrl.Result,
 	captureStartConditionally captureStartConditionally,
@@ -599,17 +878,70 @@ func (v *VRGInstance) kubeObjectsRecoverFromS3(result *ctrl.Result, accessor s3S
 		return fmt.Errorf("kube objects source VRG get error: %v", err)
 	}
 
-	captureToRecoverFromIdentifier := sourceVrg.Status.KubeObjectProtection.CaptureToRecoverFrom
-	if captureToRecoverFromIdentifier == nil {
-		return fmt.Errorf("kube objects source VRG capture-to-recover-from identifier nil: %v", err)
+	captureToRecoverFromIdentifier, err := kubeObjectsCaptureSelect(
+		sourceVrg.Status.KubeObjectProtection, v.kubeObjectsRecoverFrom())
+	if err != nil {
+		return err
 	}
 
 	v.instance.Status.KubeObjectProtection.CaptureToRecoverFrom = captureToRecoverFromIdentifier
+	v.instance.Status.KubeObjectProtection.Captures = sourceVrg.Status.KubeObjectProtection.Captures
 	log := v.log.WithValues("number", captureToRecoverFromIdentifier.Number, "profile", s3ProfileName)
 
 	return v.kubeObjectsRecoveryStartOrResume(result, s3ProfileName, captureToRecoverFromIdentifier, log)
 }
 
+// kubeObjectsRecoverFrom returns the capture selection of a failover, if any.
+func (v *VRGInstance) kubeObjectsRecoverFrom() *ramen.KubeObjectsRecoverFrom {
+	if v.instance.Spec.Action != ramen.VRGActionFailover {
+		return nil
+	}
+
+	return v.instance.Spec.KubeObjectProtection.RecoverFrom
+}
+
+// kubeObjectsCaptureSelect returns the capture, of those listed in the given
+// status, selected by the given selection, or the capture-to-recover-from if
+// there is no selection.
+func kubeObjectsCaptureSelect(
+	status ramen.KubeObjectProtectionStatus, recoverFrom *ramen.KubeObjectsRecoverFrom,
+) (*ramen.KubeObjectsCaptureIdentifier, error) {
+	if recoverFrom == nil || (recoverFrom.CaptureNumber == nil && recoverFrom.Time == nil) {
+		if status.CaptureToRecoverFrom == nil {
+			return nil, fmt.Errorf("kube objects source VRG capture-to-recover-from identifier nil")
+		}
+
+		return status.CaptureToRecoverFrom, nil
+	}
+
+	captures := status.Captures
+	if len(captures) == 0 && status.CaptureToRecoverFrom != nil {
+		captures = []ramen.KubeObjectsCaptureIdentifier{*status.CaptureToRecoverFrom}
+	}
+
+	for i := range captures {
+		capture := &captures[i]
+
+		if recoverFrom.CaptureNumber != nil {
+			if capture.Number == *recoverFrom.CaptureNumber {
+				return capture, nil
+			}
+
+			continue
+		}
+
+		if !capture.EndTime.After(recoverFrom.Time.Time) {
+			return capture, nil
+		}
+	}
+
+	if recoverFrom.CaptureNumber != nil {
+		return nil, fmt.Errorf("kube objects capture %d is not retained", *recoverFrom.CaptureNumber)
+	}
+
+	return nil, fmt.Errorf("kube objects capture completed at or before %v is not retained", recoverFrom.Time)
+}
+
 func (v *VRGInstance) kubeObjectsRecover(result *ctrl.Result) error {
 	if v.kubeObjectProtectionDisabled("recovery") {
 		return nil
@@ -633,6 +965,10 @@ func (v *VRGInstance) kubeObjectsRecover(result *ctrl.Result) error {
 		return nil
 	}
 
+	if err := v.kubeObjectsStoresEngineCheck(); err != nil {
+		return err
+	}
+
 	for _, s3StoreAccessor := range v.s3StoreAccessors {
 		if err := v.kubeObjectsRecoverFromS3(result, s3StoreAccessor); err != nil {
 			v.log.Info("Kube objects restore error", "profile", s3StoreAccessor.S3ProfileName, "error", err)
@@ -650,6 +986,25 @@ func (v *VRGInstance) kubeObjectsRecover(result *ctrl.Result) error {
 	return fmt.Errorf("kube objects restore error, will retry")
 }
 
+// kubeObjectsStoresEngineCheck returns an error if the kube object protection
+// engine does not support the object store provider of an S3 profile.  Velero
+// backup storage locations are created for the aws plugin, i.e. for s3 stores.
+func (v *VRGInstance) kubeObjectsStoresEngineCheck() error {
+	if kubeObjectProtectionEngineOrDefault(v.ramenConfig) != ramen.KubeObjectProtectionEngineVelero {
+		return nil
+	}
+
+	for _, s3StoreAccessor := range v.s3StoreAccessors {
+		if provider := s3StoreAccessor.Provider; provider != "" && provider != ramen.ObjectStoreProviderS3 {
+			return fmt.Errorf("s3Profile %s provider %s is not supported by the %s kube object protection engine, "+
+				"select the %s engine to protect kube objects in it", s3StoreAccessor.S3ProfileName, provider,
+				ramen.KubeObjectProtectionEngineVelero, ramen.KubeObjectProtectionEngineNative)
+		}
+	}
+
+	return nil
+}
+
 func (v *VRGInstance) findS3StoreAccessor(s3ProfileName string) (s3StoreAccessor, error) {
 	for _, s3StoreAccessor := range v.s3StoreAccessors {
 		if s3StoreAccessor.S3StoreProfile.S3ProfileName == s3ProfileName {
@@ -674,6 +1029,8 @@ func (v *VRGInstance) getRecoverOrProtectRequest(
 	recoverNamePrefix := kubeObjectsRecoverNamePrefix(vrg.Namespace, vrg.Name)
 	recoverName := kubeObjectsRecoverName(recoverNamePrefix, groupNumber)
 	recoverRequest, ok := recoverRequests[recoverName]
+	recoverGroup.Transforms = kubeObjectsRecoverTransforms(vrg.Spec.KubeObjectProtection,
+		vrg.GetAnnotations()[DestinationClusterAnnotationKey])
 
 	return recoverRequest, ok, func() (kubeobjects.Request, error) {
 			pathName, _, captureNamePrefix := kubeObjectsCapturePathNamesAndNamePrefix(
@@ -682,7 +1039,7 @@ func (v *VRGInstance) getRecoverOrProtectRequest(
 			captureRequest := captureRequests[captureName]
 
 			return v.reconciler.kubeObjects.RecoverRequestCreate(
-				v.ctx, v.reconciler.Client, v.log,
+				v.ctx, v.reconciler.Client, v.log, s3StoreAccessor.S3ProfileName,
 				s3StoreAccessor.S3CompatibleEndpoint, s3StoreAccessor.S3Bucket, s3StoreAccessor.S3Region, pathName,
 				s3StoreAccessor.VeleroNamespaceSecretKeyRef,
 				s3StoreAccessor.CACertificates,
@@ -698,6 +1055,35 @@ func (v *VRGInstance) getRecoverOrProtectRequest(
 		}
 }
 
+// kubeObjectsRecoverTransforms returns the transforms chosen for the named
+// cluster, in order.
+func kubeObjectsRecoverTransforms(
+	spec *ramen.KubeObjectProtectionSpec, clusterName string,
+) []kubeobjects.Transform {
+	transforms := []kubeobjects.Transform{}
+
+	for _, transform := range spec.RecoverTransforms {
+		if len(transform.Clusters) > 0 && !slices.Contains(transform.Clusters, clusterName) {
+			continue
+		}
+
+		patches := make([]kubeobjects.Patch, len(transform.Patches))
+		for i, patch := range transform.Patches {
+			patches[i] = kubeobjects.Patch(patch)
+		}
+
+		transforms = append(transforms, kubeobjects.Transform{
+			GroupResource:     transform.GroupResource,
+			ResourceNameRegex: transform.ResourceNameRegex,
+			Namespaces:        transform.Namespaces,
+			LabelSelector:     transform.LabelSelector,
+			Patches:           patches,
+		})
+	}
+
+	return transforms
+}
+
 func (v *VRGInstance) getCaptureRequests() (map[string]kubeobjects.Request, error) {
 	captureRequestsStruct, err := v.reconciler.kubeObjects.ProtectRequestsGet(
 		v.ctx, v.reconciler.APIReader, v.veleroNamespaceName(), util.OwnerLabels(v.instance))
@@ -784,17 +1170,18 @@ func (v *VRGInstance) executeRecoverSteps(result *ctrl.Result, s3StoreAccessor s
 
 		if rg.IsHook {
 			isEssentialStep = rg.Hook.Essential != nil && *rg.Hook.Essential
+			hook := v.kubeObjectsHookParametersExpand(rg.Hook)
 
-			executor, err1 := hooks.GetHookExecutor(rg.Hook, v.reconciler.APIReader, v.reconciler.Scheme,
-				v.recipeElements)
+			executor, err1 := hooks.GetHookExecutor(hook, v.reconciler.APIReader, v.reconciler.Client,
+				v.reconciler.Scheme, v.reconciler.Client.RESTMapper(), v.recipeElements)
 			if err1 != nil {
-				// continue if hook type is not supported. Supported types are "check" and "exec"
-				log1.Info("Hook type not supported", "hook", rg.Hook)
+				// continue if hook type is not one of hooks.SupportedTypes
+				log1.Info("Hook type not supported", "hook", hook)
 
 				continue
 			}
 
-			err = executor.Execute(log1)
+			err = v.kubeObjectsHookExecute(executor, hook, log1)
 		}
 
 		if !rg.IsHook {
@@ -913,7 +1300,13 @@ func (v *VRGInstance) kubeObjectsRecoverRequestsDelete(
 	return nil
 }
 
+// veleroNamespaceName returns the namespace of kube objects requests, which is
+// the ramen operator's for the native engine.
 func (v *VRGInstance) veleroNamespaceName() string {
+	if kubeObjectProtectionEngineOrDefault(v.ramenConfig) == ramen.KubeObjectProtectionEngineNative {
+		return RamenOperatorNamespace()
+	}
+
 	if v.ramenConfig.KubeObjectProtection.VeleroNamespaceName != "" {
 		return v.ramenConfig.KubeObjectProtection.VeleroNamespaceName
 	}
@@ -938,6 +1331,10 @@ func (v *VRGInstance) kubeObjectProtectionDisabled(caller string) bool {
 }
 
 func (v *VRGInstance) kubeObjectsProtectionDelete(result *ctrl.Result) error {
+	if v.reconciler.kubeObjectsChangeWatcher != nil {
+		v.reconciler.kubeObjectsChangeWatcher.unwatch(client.ObjectKeyFromObject(v.instance))
+	}
+
 	if v.kubeObjectProtectionDisabled("deletion") {
 		return nil
 	}
@@ -951,6 +1348,128 @@ func (v *VRGInstance) kubeObjectsProtectionDelete(result *ctrl.Result) error {
 	)
 }
 
+// kubeObjectsRequestsManager returns the requests manager of the kube object
+// protection engine of the given config, and a native requests manager to
+// inventory and watch the objects captured, whatever the engine.
+func (r *VolumeReplicationGroupReconciler) kubeObjectsRequestsManager(
+	mgr ctrl.Manager, ramenConfig *ramen.RamenConfig,
+) (kubeobjects.RequestsManager, native.RequestsManager, error) {
+	dynamicClient, err := dynamic.NewForConfig(mgr.GetConfig())
+	if err != nil {
+		return nil, native.RequestsManager{}, fmt.Errorf("kube objects dynamic client create: %w", err)
+	}
+
+	discoveryClient, err := discovery.NewDiscoveryClientForConfig(mgr.GetConfig())
+	if err != nil {
+		return nil, native.RequestsManager{}, fmt.Errorf("kube objects discovery client create: %w", err)
+	}
+
+	nativeRequestsManager := native.RequestsManagerNew(dynamicClient, discoveryClient, r.kubeObjectsObjectStorerGet)
+
+	switch engine := kubeObjectProtectionEngineOrDefault(ramenConfig); engine {
+	case ramen.KubeObjectProtectionEngineVelero:
+		return velero.RequestsManager{}, nativeRequestsManager, nil
+	case ramen.KubeObjectProtectionEngineNative:
+		return nativeRequestsManager, nativeRequestsManager, nil
+	default:
+		return nil, nativeRequestsManager, fmt.Errorf("unsupported kube object protection engine %s", engine)
+	}
+}
+
+// addKubeObjectsChangeWatches adds a source of events for the VRGs whose kube
+// objects change, for those that capture on change.
+func (r *VolumeReplicationGroupReconciler) addKubeObjectsChangeWatches(
+	mgr ctrl.Manager, ctrlBuilder *builder.Builder, nativeRequestsManager native.RequestsManager,
+) (*builder.Builder, error) {
+	metadataClient, err := metadata.NewForConfig(mgr.GetConfig())
+	if err != nil {
+		return nil, fmt.Errorf("kube objects metadata client create: %w", err)
+	}
+
+	r.kubeObjectsChangeWatcher = kubeObjectsChangeWatcherNew(
+		func(specs []kubeobjects.Spec) (kubeObjectsSelector, error) {
+			return nativeRequestsManager.SelectorNew(specs)
+		},
+		metadataClient,
+		r.Log.WithName("KubeObjectsChangeWatcher"),
+	)
+
+	if err := mgr.Add(r.kubeObjectsChangeWatcher); err != nil {
+		return nil, fmt.Errorf("kube objects change watcher add: %w", err)
+	}
+
+	return ctrlBuilder.WatchesRawSource(
+		source.Channel(r.kubeObjectsChangeWatcher.events, &handler.EnqueueRequestForObject{}),
+	), nil
+}
+
+// kubeObjectsChangeWatchUpdate watches the kube objects that the capture groups
+// select for changes if the VRG captures on change, and otherwise stops.
+func (v *VRGInstance) kubeObjectsChangeWatchUpdate() {
+	watcher := v.reconciler.kubeObjectsChangeWatcher
+	if watcher == nil {
+		return
+	}
+
+	kubeObjectProtection := v.instance.Spec.KubeObjectProtection
+	if kubeObjectProtection == nil || kubeObjectProtection.CaptureOnChange == nil ||
+		v.instance.Spec.ReplicationState != ramen.Primary {
+		watcher.unwatch(client.ObjectKeyFromObject(v.instance))
+
+		return
+	}
+
+	specs := []kubeobjects.Spec{}
+
+	for _, captureGroup := range v.recipeElements.CaptureWorkflow {
+		if !captureGroup.IsHook {
+			specs = append(specs, captureGroup.Spec)
+		}
+	}
+
+	if err := watcher.watch(client.ObjectKeyFromObject(v.instance), specs); err != nil {
+		v.log.Error(err, "Kube objects change watch error")
+	}
+}
+
+// kubeObjectsCaptureOnChangeDelay returns the delay until a capture is to start
+// as objects changed since the previous capture started the given duration ago,
+// and whether any did.
+func (v *VRGInstance) kubeObjectsCaptureOnChangeDelay(captureStartTimeSince time.Duration) (time.Duration, bool) {
+	watcher := v.reconciler.kubeObjectsChangeWatcher
+	captureOnChange := v.instance.Spec.KubeObjectProtection.CaptureOnChange
+
+	if watcher == nil || captureOnChange == nil {
+		return 0, false
+	}
+
+	changeTime := watcher.changeTime(client.ObjectKeyFromObject(v.instance))
+	if changeTime.IsZero() || !changeTime.After(time.Now().Add(-captureStartTimeSince)) {
+		return 0, false
+	}
+
+	debounce := ramen.KubeObjectsCaptureOnChangeDebounceDefault
+	if captureOnChange.Debounce != nil {
+		debounce = captureOnChange.Debounce.Duration
+	}
+
+	minInterval := ramen.KubeObjectsCaptureOnChangeMinIntervalDefault
+	if captureOnChange.MinInterval != nil {
+		minInterval = captureOnChange.MinInterval.Duration
+	}
+
+	return max(debounce-time.Since(changeTime), minInterval-captureStartTimeSince), true
+}
+
+// kubeObjectsObjectStorerGet returns the object store of the named s3 profile.
+func (r *VolumeReplicationGroupReconciler) kubeObjectsObjectStorerGet(
+	ctx context.Context, s3ProfileName string,
+) (native.ObjectStorer, error) {
+	objectStore, _, err := r.ObjStoreGetter.ObjectStore(ctx, r.APIReader, s3ProfileName, "kube objects", r.Log)
+
+	return objectStore, err
+}
+
 func kubeObjectsRequestsWatch(
 	b *builder.Builder, scheme *runtime.Scheme, kubeObjects kubeobjects.RequestsManager,
 ) *builder.Builder {
@@ -1177,13 +1696,14 @@ func convertRecipeHookToRecoverSpec(hook Recipe.Hook, suffix string) (*kubeobjec
 	}, nil
 }
 
-// TODO: Return error as well or ensure that other than exec and check hooks are
+// TODO: Return error as well or ensure that hooks of types other than hooks.SupportedTypes are
 // handled properly.
 func getHookSpecFromHook(hook Recipe.Hook, suffix string) kubeobjects.HookSpec {
 	// based on hook.type check of the hook is chks or ops
-	if hook.Type == "exec" {
+	switch hook.Type {
+	case "exec", "scale", "http", "job", "vm":
 		return getOpHookSpec(&hook, suffix)
-	} else if hook.Type == "check" {
+	case "check":
 		return getChkHookSpec(&hook, suffix)
 	}
 
@@ -0,0 +1,275 @@
+// SPDX-FileCopyrightText: The RamenDR authors
+// SPDX-License-Identifier: Apache-2.0
+
+package controllers
+
+import (
+	"context"
+	"maps"
+	"os"
+	"reflect"
+	"slices"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/go-logr/logr"
+	ramen "github.com/ramendr/ramen/api/v1alpha1"
+	"github.com/ramendr/ramen/internal/controller/core"
+	"github.com/ramendr/ramen/internal/controller/hooks"
+	"github.com/ramendr/ramen/internal/controller/kubeobjects"
+	"github.com/ramendr/ramen/internal/controller/util"
+	"github.com/ramendr/ramen/internal/controller/volsync"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+const hookResultErrorLimit = 1024
+
+// kubeObjectsHookExecute executes a recipe hook, records the result of its
+// execution in the VRG's status, and stores its output in the recipe parameters
+// it names, for the workflow's later steps.
+func (v *VRGInstance) kubeObjectsHookExecute(
+	executor hooks.HookExecutor, hook kubeobjects.HookSpec, log logr.Logger,
+) error {
+	startTime := metav1.Now()
+	result, err := executor.ExecuteWithResult(log)
+
+	hookResultSet(&v.instance.Status.KubeObjectProtection.HookResults,
+		hookResultNew(hook, startTime, metav1.Now(), result, err))
+
+	if len(result.Parameters) > 0 {
+		v.hookOutputParametersSet(result.Parameters)
+	}
+
+	return err
+}
+
+func (v *VRGInstance) hookOutputParametersSet(parameters map[string][]string) {
+	if v.hookOutputParameters == nil {
+		v.hookOutputParameters = make(map[string][]string, len(parameters))
+	}
+
+	maps.Copy(v.hookOutputParameters, parameters)
+
+	// copied, rather than updated, since the recipe parameters may be the VRG's
+	recipeParameters := maps.Clone(v.recipeElements.Parameters)
+	if recipeParameters == nil {
+		recipeParameters = make(map[string][]string, len(parameters))
+	}
+
+	maps.Copy(recipeParameters, parameters)
+	v.recipeElements.Parameters = recipeParameters
+}
+
+// kubeObjectsHookParametersExpand returns the given hook with the output
+// parameters of the workflow's previous hooks expanded in its command, check
+// condition and name selector.
+func (v *VRGInstance) kubeObjectsHookParametersExpand(hook kubeobjects.HookSpec) kubeobjects.HookSpec {
+	if len(v.hookOutputParameters) == 0 {
+		return hook
+	}
+
+	expand := func(s string) string {
+		return os.Expand(s, func(key string) string {
+			values, ok := v.hookOutputParameters[key]
+			if !ok {
+				return "${" + key + "}"
+			}
+
+			return strings.Join(values, `","`)
+		})
+	}
+
+	hook.Op.Command = expand(hook.Op.Command)
+	hook.Chk.Condition = expand(hook.Chk.Condition)
+	hook.NameSelector = expand(hook.NameSelector)
+
+	return hook
+}
+
+func hookResultName(hook kubeobjects.HookSpec) string {
+	if hook.Type == "check" {
+		return hook.Name + "/" + hook.Chk.Name
+	}
+
+	return hook.Name + "/" + hook.Op.Name
+}
+
+func hookResultNew(
+	hook kubeobjects.HookSpec, startTime, endTime metav1.Time, result hooks.Result, err error,
+) ramen.HookResult {
+	if result.Error != nil {
+		err = result.Error
+	}
+
+	hookResult := ramen.HookResult{
+		Name:              hookResultName(hook),
+		Type:              hook.Type,
+		Targets:           result.Targets,
+		StartTime:         startTime,
+		EndTime:           endTime,
+		Succeeded:         err == nil,
+		ExitCode:          result.ExitCode,
+		StatusCode:        result.StatusCode,
+		CheckResult:       result.CheckResult,
+		Stdout:            result.Stdout,
+		Stderr:            result.Stderr,
+		InverseOpExecuted: result.InverseOpExecuted,
+	}
+
+	if err != nil {
+		hookResult.Error = hookResultErrorTruncate(err.Error())
+	}
+
+	return hookResult
+}
+
+// hookResultErrorTruncate returns at most the first hookResultErrorLimit bytes
+// of the given error, up to a rune boundary.
+func hookResultErrorTruncate(err string) string {
+	if len(err) <= hookResultErrorLimit {
+		return err
+	}
+
+	end := hookResultErrorLimit
+	for end > 0 && !utf8.RuneStart(err[end]) {
+		end--
+	}
+
+	return err[:end]
+}
+
+// hookResultSet replaces the result of the given result's hook operation or
+// check, unless it differs only in its times, so that the VRG's and DRPC's
+// status change only if the result does, or else adds it.
+func hookResultSet(hookResults *[]ramen.HookResult, hookResult ramen.HookResult) {
+	i := slices.IndexFunc(*hookResults, func(r ramen.HookResult) bool { return r.Name == hookResult.Name })
+	if i < 0 {
+		*hookResults = append(*hookResults, hookResult)
+
+		return
+	}
+
+	previous := (*hookResults)[i]
+	previous.StartTime, previous.EndTime = hookResult.StartTime, hookResult.EndTime
+
+	if reflect.DeepEqual(previous, hookResult) {
+		return
+	}
+
+	(*hookResults)[i] = hookResult
+}
+
+// hookResultsPrune removes the results of hook operations and checks that are
+// not in the given capture and recover workflows, e.g. of a recipe's hook that
+// was removed or renamed.
+func hookResultsPrune(hookResults *[]ramen.HookResult, captureWorkflow []kubeobjects.CaptureSpec,
+	recoverWorkflow []kubeobjects.RecoverSpec,
+) {
+	names := make(map[string]struct{}, len(captureWorkflow)+len(recoverWorkflow))
+
+	for _, group := range captureWorkflow {
+		if group.IsHook {
+			names[hookResultName(group.Hook)] = struct{}{}
+		}
+	}
+
+	for _, group := range recoverWorkflow {
+		if group.IsHook {
+			names[hookResultName(group.Hook)] = struct{}{}
+		}
+	}
+
+	*hookResults = slices.DeleteFunc(*hookResults, func(r ramen.HookResult) bool {
+		_, ok := names[r.Name]
+
+		return !ok
+	})
+
+	if len(*hookResults) == 0 {
+		*hookResults = nil
+	}
+}
+
+// hookResultsSummarize returns summaries of the given hook results, for a DRPC.
+func hookResultsSummarize(hookResults []ramen.HookResult) []ramen.HookResultSummary {
+	if len(hookResults) == 0 {
+		return nil
+	}
+
+	summaries := make([]ramen.HookResultSummary, len(hookResults))
+
+	for i, hookResult := range hookResults {
+		summaries[i] = ramen.HookResultSummary{
+			Name:      hookResult.Name,
+			Type:      hookResult.Type,
+			EndTime:   hookResult.EndTime,
+			Succeeded: hookResult.Succeeded,
+			Error:     hookResult.Error,
+		}
+	}
+
+	return summaries
+}
+
+// recipeFreezer returns a freezer of the recipe's VM freeze hook, or nil if it
+// has none, to freeze the VMs while their volumes are copied for a sync.
+func recipeFreezer(recipeElements util.RecipeElements, reader client.Reader, c client.Client,
+	scheme *runtime.Scheme, log logr.Logger,
+) volsync.Freezer {
+	if recipeElements.RecipeWithParams == nil {
+		return nil
+	}
+
+	for _, hook := range recipeElements.RecipeWithParams.Spec.Hooks {
+		if hook.Name != core.VMFreezeHookName || hook.Type != "vm" {
+			continue
+		}
+
+		freeze := getOpHookSpec(hook, core.VMFreezeOpName)
+		if freeze.Op.Name == "" {
+			return nil
+		}
+
+		return recipeHookFreezer{
+			freeze: freeze, unfreeze: getOpHookSpec(hook, freeze.Op.InverseOp),
+			reader: reader, client: c, scheme: scheme, recipeElements: recipeElements, log: log,
+		}
+	}
+
+	return nil
+}
+
+// recipeHookFreezer freezes, and unfreezes, with a recipe hook's operation and
+// its inverse operation.
+type recipeHookFreezer struct {
+	freeze, unfreeze kubeobjects.HookSpec
+	reader           client.Reader
+	client           client.Client
+	scheme           *runtime.Scheme
+	recipeElements   util.RecipeElements
+	log              logr.Logger
+}
+
+func (f recipeHookFreezer) Freeze(context.Context) error {
+	return f.execute(f.freeze)
+}
+
+func (f recipeHookFreezer) Unfreeze(context.Context) error {
+	if f.unfreeze.Op.Name == "" {
+		return nil
+	}
+
+	return f.execute(f.unfreeze)
+}
+
+func (f recipeHookFreezer) execute(hook kubeobjects.HookSpec) error {
+	executor, err := hooks.GetHookExecutor(hook, f.reader, f.client, f.scheme, f.client.RESTMapper(),
+		f.recipeElements)
+	if err != nil {
+		return err
+	}
+
+	return executor.Execute(f.log)
+}
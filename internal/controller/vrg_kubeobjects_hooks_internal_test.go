@@ -0,0 +1,97 @@
+// SPDX-FileCopyrightText: The RamenDR authors
+// SPDX-License-Identifier: Apache-2.0
+
+package controllers
+
+import (
+	"errors"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	ramen "github.com/ramendr/ramen/api/v1alpha1"
+	"github.com/ramendr/ramen/internal/controller/hooks"
+	"github.com/ramendr/ramen/internal/controller/kubeobjects"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/utils/ptr"
+)
+
+var _ = Describe("hookResults", func() {
+	execHook := kubeobjects.HookSpec{Name: "db", Type: "exec", Op: kubeobjects.Operation{Name: "quiesce"}}
+	checkHook := kubeobjects.HookSpec{Name: "db", Type: "check", Chk: kubeobjects.Check{Name: "ready"}}
+	start := metav1.NewTime(time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC))
+	end := metav1.NewTime(start.Add(time.Second))
+	later := metav1.NewTime(start.Add(time.Minute))
+
+	It("records a hook's result, with its exit code, output and error", func() {
+		result := hookResultNew(execHook, start, end, hooks.Result{
+			Targets: []string{"ns/pod"}, ExitCode: ptr.To(int32(1)), Stdout: "out", Stderr: "err",
+		}, errors.New("command failed"))
+		Expect(result).To(Equal(ramen.HookResult{
+			Name: "db/quiesce", Type: "exec", Targets: []string{"ns/pod"}, StartTime: start, EndTime: end,
+			ExitCode: ptr.To(int32(1)), Stdout: "out", Stderr: "err", Error: "command failed",
+		}))
+
+		result = hookResultNew(checkHook, start, end, hooks.Result{CheckResult: ptr.To(true)}, nil)
+		Expect(result.Name).To(Equal("db/ready"))
+		Expect(result.Succeeded).To(BeTrue())
+	})
+
+	It("records the error of a hook whose errors are ignored", func() {
+		result := hookResultNew(execHook, start, end, hooks.Result{Error: errors.New("ignored")}, nil)
+		Expect(result.Succeeded).To(BeFalse())
+		Expect(result.Error).To(Equal("ignored"))
+	})
+
+	It("truncates an error at a rune boundary", func() {
+		err := strings.Repeat("a", hookResultErrorLimit-1) + "é"
+		truncated := hookResultErrorTruncate(err)
+		Expect(truncated).To(Equal(strings.Repeat("a", hookResultErrorLimit-1)))
+		Expect(utf8.ValidString(truncated)).To(BeTrue())
+		Expect(hookResultErrorTruncate("short")).To(Equal("short"))
+	})
+
+	It("replaces a hook's result only if it changed", func() {
+		results := []ramen.HookResult{}
+		hookResultSet(&results, hookResultNew(execHook, start, end, hooks.Result{}, nil))
+		hookResultSet(&results, hookResultNew(checkHook, start, end, hooks.Result{}, nil))
+		Expect(results).To(HaveLen(2))
+
+		hookResultSet(&results, hookResultNew(execHook, later, later, hooks.Result{}, nil))
+		Expect(results[0].StartTime).To(Equal(start))
+
+		hookResultSet(&results, hookResultNew(execHook, later, later, hooks.Result{}, errors.New("failed")))
+		Expect(results).To(HaveLen(2))
+		Expect(results[0].StartTime).To(Equal(later))
+		Expect(results[0].Succeeded).To(BeFalse())
+	})
+
+	It("prunes the results of hooks not in the workflows", func() {
+		results := []ramen.HookResult{
+			hookResultNew(execHook, start, end, hooks.Result{}, nil),
+			hookResultNew(checkHook, start, end, hooks.Result{}, nil),
+		}
+		captureWorkflow := []kubeobjects.CaptureSpec{
+			{Spec: kubeobjects.Spec{KubeResourcesSpec: kubeobjects.KubeResourcesSpec{Hook: execHook, IsHook: true}}},
+			{Name: "resources"},
+		}
+
+		hookResultsPrune(&results, captureWorkflow, nil)
+		Expect(results).To(HaveLen(1))
+		Expect(results[0].Name).To(Equal("db/quiesce"))
+
+		hookResultsPrune(&results, nil, nil)
+		Expect(results).To(BeNil())
+	})
+
+	It("summarizes results for a DRPC", func() {
+		Expect(hookResultsSummarize(nil)).To(BeNil())
+		Expect(hookResultsSummarize([]ramen.HookResult{
+			hookResultNew(execHook, start, end, hooks.Result{ExitCode: ptr.To(int32(1))}, errors.New("failed")),
+		})).To(Equal([]ramen.HookResultSummary{
+			{Name: "db/quiesce", Type: "exec", EndTime: end, Error: "failed"},
+		}))
+	})
+})
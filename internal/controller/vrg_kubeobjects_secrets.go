@@ -0,0 +1,187 @@
+// SPDX-FileCopyrightText: The RamenDR authors
+// SPDX-License-Identifier: Apache-2.0
+
+package controllers
+
+import (
+	"encoding/json"
+	"fmt"
+	"slices"
+
+	ramen "github.com/ramendr/ramen/api/v1alpha1"
+	"github.com/ramendr/ramen/internal/controller/kubeobjects"
+	"github.com/ramendr/ramen/internal/controller/util"
+	Recipe "github.com/ramendr/recipe/api/v1alpha1"
+	corev1 "k8s.io/api/core/v1"
+)
+
+const kubeObjectsSecretsReportName = "secrets"
+
+// RecipeSecretPoliciesAnnotation is the name of a Recipe annotation whose value
+// is a list of secret policies, in JSON, as those of a VRG, e.g.
+// "ramendr.openshift.io/secret-policies: [{"types": ["kubernetes.io/tls"], "action": "Redact"}]".
+// The Recipe API has no field for them.
+const RecipeSecretPoliciesAnnotation = "ramendr.openshift.io/secret-policies"
+
+// kubeObjectsSecretsReporter reports the Secrets that a capture excluded or
+// redacted.  Only the native engine applies secret policies.
+type kubeObjectsSecretsReporter interface {
+	ProtectRequestSecretsReportGet(
+		objectDownloader kubeobjects.ObjectDownloader, s3KeyPrefix, protectRequestName string,
+	) (kubeobjects.SecretsReport, error)
+}
+
+// kubeObjectsSecretPolicies returns the secret policies of the ramen config,
+// followed by those of the recipe, if any, and those of the VRG.
+func kubeObjectsSecretPolicies(
+	ramenConfig ramen.RamenConfig, recipe *Recipe.Recipe, spec *ramen.KubeObjectProtectionSpec,
+) ([]kubeobjects.SecretPolicy, error) {
+	policies := slices.Clone(ramenConfig.KubeObjectsSecretPolicies)
+
+	if recipe != nil {
+		if value, ok := recipe.GetAnnotations()[RecipeSecretPoliciesAnnotation]; ok {
+			recipePolicies := []ramen.KubeObjectsSecretPolicy{}
+			if err := json.Unmarshal([]byte(value), &recipePolicies); err != nil {
+				return nil, fmt.Errorf("recipe annotation %s value %q unmarshal: %w",
+					RecipeSecretPoliciesAnnotation, value, err)
+			}
+
+			policies = append(policies, recipePolicies...)
+		}
+	}
+
+	if spec != nil {
+		policies = append(policies, spec.SecretPolicies...)
+	}
+
+	secretPolicies := make([]kubeobjects.SecretPolicy, len(policies))
+
+	for i, policy := range policies {
+		types := make([]string, len(policy.Types))
+		for j, secretType := range policy.Types {
+			types[j] = string(secretType)
+		}
+
+		secretPolicies[i] = kubeobjects.SecretPolicy{
+			Types:          types,
+			LabelSelector:  policy.LabelSelector,
+			Action:         string(policy.Action),
+			DataSourceName: policy.DataSourceName,
+		}
+	}
+
+	return secretPolicies, nil
+}
+
+func recipeElementsSecretPoliciesSet(recipeElements *util.RecipeElements, policies []kubeobjects.SecretPolicy) {
+	if len(policies) == 0 {
+		return
+	}
+
+	captureWorkflow := slices.Clone(recipeElements.CaptureWorkflow)
+	for i := range captureWorkflow {
+		if !captureWorkflow[i].IsHook {
+			captureWorkflow[i].SecretPolicies = policies
+		}
+	}
+
+	recipeElements.CaptureWorkflow = captureWorkflow
+}
+
+// kubeObjectsSecretPoliciesEngineCheck returns an error if capture groups have
+// secret policies, but the kube object protection engine does not apply them.
+func (v *VRGInstance) kubeObjectsSecretPoliciesEngineCheck() error {
+	if _, ok := v.reconciler.kubeObjects.(kubeObjectsSecretsReporter); ok {
+		return nil
+	}
+
+	for _, captureGroup := range v.recipeElements.CaptureWorkflow {
+		if !captureGroup.IsHook && len(captureGroup.SecretPolicies) > 0 {
+			return fmt.Errorf("secret policies are not supported by the %s kube object protection engine, "+
+				"select the %s engine to apply them", kubeObjectProtectionEngineOrDefault(v.ramenConfig),
+				ramen.KubeObjectProtectionEngineNative)
+		}
+	}
+
+	return nil
+}
+
+// kubeObjectsCaptureSecrets stores, with the numbered capture in each s3 store,
+// a report of the Secrets its groups excluded from or redacted in it, and
+// returns the counts of the first store's.  Failures are logged rather than
+// returned as the report is informational.
+func (v *VRGInstance) kubeObjectsCaptureSecrets(captureNumber int64) *ramen.KubeObjectsCaptureSecrets {
+	vrg := v.instance
+
+	reporter, ok := v.reconciler.kubeObjects.(kubeObjectsSecretsReporter)
+	if !ok || !slices.ContainsFunc(v.recipeElements.CaptureWorkflow, func(captureGroup kubeobjects.CaptureSpec) bool {
+		return !captureGroup.IsHook && len(captureGroup.SecretPolicies) > 0
+	}) {
+		return nil
+	}
+
+	var captureSecrets *ramen.KubeObjectsCaptureSecrets
+
+	for _, s3StoreAccessor := range v.s3StoreAccessors {
+		log := v.log.WithValues("number", captureNumber, "profile", s3StoreAccessor.S3ProfileName)
+
+		report, err := v.kubeObjectsCaptureSecretsReportGet(reporter, s3StoreAccessor, captureNumber)
+		if err != nil {
+			log.Error(err, "Kube objects secrets report error")
+
+			continue
+		}
+
+		pathName, _, _ := kubeObjectsCapturePathNamesAndNamePrefix(
+			vrg.Namespace, vrg.Name, captureNumber, v.reconciler.kubeObjects)
+		if err := s3StoreAccessor.ObjectStorer.UploadObject(pathName+kubeObjectsSecretsReportName, report); err != nil {
+			log.Error(err, "Kube objects secrets report upload error")
+		}
+
+		log.Info("Kube objects capture secrets", "excluded", len(report.Excluded), "redacted", len(report.Redacted))
+
+		if captureSecrets == nil {
+			captureSecrets = &ramen.KubeObjectsCaptureSecrets{
+				Number:   captureNumber,
+				Excluded: int32(len(report.Excluded)),
+				Redacted: int32(len(report.Redacted)),
+			}
+		}
+	}
+
+	if captureSecrets != nil && captureSecrets.Excluded+captureSecrets.Redacted > 0 {
+		util.ReportIfNotPresent(v.reconciler.eventRecorder, vrg, corev1.EventTypeNormal,
+			util.EventReasonKubeObjectsSecretsRedacted, fmt.Sprintf(
+				"kube objects capture %d excluded %d and redacted %d secrets",
+				captureNumber, captureSecrets.Excluded, captureSecrets.Redacted))
+	}
+
+	return captureSecrets
+}
+
+// kubeObjectsCaptureSecretsReportGet returns the Secrets the groups of the
+// numbered capture excluded from or redacted in the given s3 store.
+func (v *VRGInstance) kubeObjectsCaptureSecretsReportGet(
+	reporter kubeObjectsSecretsReporter, s3StoreAccessor s3StoreAccessor, captureNumber int64,
+) (kubeobjects.SecretsReport, error) {
+	vrg := v.instance
+	pathName, _, namePrefix := kubeObjectsCapturePathNamesAndNamePrefix(
+		vrg.Namespace, vrg.Name, captureNumber, v.reconciler.kubeObjects)
+	report := kubeobjects.SecretsReport{Excluded: []string{}, Redacted: []string{}}
+
+	for _, captureGroup := range v.recipeElements.CaptureWorkflow {
+		if captureGroup.IsHook || len(captureGroup.SecretPolicies) == 0 {
+			continue
+		}
+
+		groupReport, err := reporter.ProtectRequestSecretsReportGet(s3StoreAccessor.ObjectStorer, pathName,
+			kubeObjectsCaptureName(namePrefix, captureGroup.Name, s3StoreAccessor.S3ProfileName))
+		if err != nil {
+			return report, err
+		}
+
+		report.Add(groupReport)
+	}
+
+	return report, nil
+}
@@ -5,12 +5,22 @@
 package controllers //nolint:testpackage
 
 import (
+	"maps"
+	"slices"
+	"time"
+
+	"github.com/go-logr/logr"
 	. "github.com/onsi/ginkgo/v2"
 	. "github.com/onsi/gomega"
 
+	ramen "github.com/ramendr/ramen/api/v1alpha1"
 	"github.com/ramendr/ramen/internal/controller/kubeobjects"
 
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/types"
+	metadatafake "k8s.io/client-go/metadata/fake"
 
 	Recipe "github.com/ramendr/recipe/api/v1alpha1"
 )
@@ -168,4 +178,236 @@ var _ = Describe("VRG_KubeObjectProtection", func() {
 			Expect(converted).To(Equal(targetRecoverSpec))
 		})
 	})
+
+	Context("Captures", func() {
+		now := time.Now()
+		capture := func(number int64, age time.Duration) ramen.KubeObjectsCaptureIdentifier {
+			return ramen.KubeObjectsCaptureIdentifier{Number: number, EndTime: metav1.NewTime(now.Add(-age))}
+		}
+		captures := []ramen.KubeObjectsCaptureIdentifier{
+			capture(4, time.Minute), capture(3, time.Hour), capture(2, 2*time.Hour),
+		}
+		count := func(count int32) *int32 { return &count }
+
+		It("numbers the next capture after the most recent one", func() {
+			Expect(kubeObjectsCaptureNumberNext(&ramen.KubeObjectProtectionStatus{})).To(Equal(int64(1)))
+			Expect(kubeObjectsCaptureNumberNext(&ramen.KubeObjectProtectionStatus{
+				CaptureToRecoverFrom: &ramen.KubeObjectsCaptureIdentifier{Number: 1},
+			})).To(Equal(int64(2)))
+			Expect(kubeObjectsCaptureNumberNext(&ramen.KubeObjectProtectionStatus{
+				CaptureToRecoverFrom: &captures[0],
+				Captures:             captures,
+			})).To(Equal(int64(5)))
+		})
+
+		DescribeTable("retains captures as per the retention",
+			func(retention *ramen.KubeObjectsCaptureRetention, retainedCount int) {
+				retained, expired := kubeObjectsCapturesRetained(captures, retention, now)
+				Expect(retained).To(Equal(captures[:retainedCount]))
+				Expect(expired).To(HaveLen(len(captures) - retainedCount))
+			},
+			Entry("default", nil, 1),
+			Entry("count", &ramen.KubeObjectsCaptureRetention{Count: count(2)}, 2),
+			Entry("max age", &ramen.KubeObjectsCaptureRetention{
+				MaxAge: &metav1.Duration{Duration: 90 * time.Minute},
+			}, 2),
+			Entry("max age older than the most recent", &ramen.KubeObjectsCaptureRetention{
+				MaxAge: &metav1.Duration{Duration: time.Second},
+			}, 1),
+			Entry("count and max age", &ramen.KubeObjectsCaptureRetention{
+				Count:  count(1),
+				MaxAge: &metav1.Duration{Duration: 3 * time.Hour},
+			}, 1),
+		)
+
+		DescribeTable("selects the capture to recover from",
+			func(recoverFrom *ramen.KubeObjectsRecoverFrom, number int64) {
+				capture, err := kubeObjectsCaptureSelect(ramen.KubeObjectProtectionStatus{
+					CaptureToRecoverFrom: &captures[0],
+					Captures:             captures,
+				}, recoverFrom)
+				Expect(err).ToNot(HaveOccurred())
+				Expect(capture.Number).To(Equal(number))
+			},
+			Entry("most recent by default", nil, int64(4)),
+			Entry("by number", &ramen.KubeObjectsRecoverFrom{CaptureNumber: func() *int64 {
+				number := int64(3)
+
+				return &number
+			}()}, int64(3)),
+			Entry("by time", &ramen.KubeObjectsRecoverFrom{Time: &metav1.Time{Time: now.Add(-90 * time.Minute)}},
+				int64(2)),
+		)
+
+		It("fails to select a capture that is not retained", func() {
+			number := int64(1)
+			_, err := kubeObjectsCaptureSelect(ramen.KubeObjectProtectionStatus{
+				CaptureToRecoverFrom: &captures[0],
+				Captures:             captures,
+			}, &ramen.KubeObjectsRecoverFrom{CaptureNumber: &number})
+			Expect(err).To(HaveOccurred())
+		})
+	})
+
+	Context("Recover transforms", func() {
+		It("chooses the transforms for a cluster, in order", func() {
+			patches := []ramen.KubeObjectsRecoverPatch{{Operation: "replace", Path: "/spec/replicas", Value: "1"}}
+			transforms := kubeObjectsRecoverTransforms(&ramen.KubeObjectProtectionSpec{
+				RecoverTransforms: []ramen.KubeObjectsRecoverTransform{
+					{GroupResource: "deployments.apps", Patches: patches},
+					{GroupResource: "ingresses.networking.k8s.io", Clusters: []string{"east"}, Patches: patches},
+					{GroupResource: "persistentvolumeclaims", Clusters: []string{"west"}, Patches: patches},
+				},
+			}, "west")
+
+			Expect(transforms).To(HaveLen(2))
+			Expect(transforms[0].GroupResource).To(Equal("deployments.apps"))
+			Expect(transforms[1].GroupResource).To(Equal("persistentvolumeclaims"))
+			Expect(transforms[1].Patches).To(Equal([]kubeobjects.Patch{
+				{Operation: "replace", Path: "/spec/replicas", Value: "1"},
+			}))
+		})
+	})
+
+	Context("Change watch", func() {
+		object := func(resourceVersion string, generation int64, labels map[string]string) *metav1.PartialObjectMetadata {
+			return &metav1.PartialObjectMetadata{ObjectMeta: metav1.ObjectMeta{
+				ResourceVersion: resourceVersion, Generation: generation, Labels: labels,
+			}}
+		}
+
+		DescribeTable("tells an object changed by its metadata",
+			func(oldObject, newObject *metav1.PartialObjectMetadata, changed bool) {
+				Expect(kubeObjectChanged(oldObject, newObject)).To(Equal(changed))
+			},
+			Entry("resync", object("1", 1, nil), object("1", 1, nil), false),
+			Entry("status update", object("1", 1, nil), object("2", 1, nil), false),
+			Entry("spec update", object("1", 1, nil), object("2", 2, nil), true),
+			Entry("label update", object("1", 1, nil), object("2", 1, map[string]string{"a": "b"}), true),
+			Entry("update without generation", object("1", 0, nil), object("2", 0, nil), true),
+		)
+
+		It("watches the namespaces each VRG selects until it is unwatched, without blocking", func() {
+			configMaps := schema.GroupVersionResource{Version: "v1", Resource: "configmaps"}
+			vrgName := types.NamespacedName{Namespace: "ramen", Name: "vrg"}
+			watcher := kubeObjectsChangeWatcherNew(
+				func([]kubeobjects.Spec) (kubeObjectsSelector, error) {
+					return kubeObjectsSelectorFake{configMaps: {"a", "b"}}, nil
+				},
+				metadatafake.NewSimpleMetadataClient(runtime.NewScheme()),
+				logr.Discard(),
+			)
+
+			Expect(watcher.watch(vrgName, []kubeobjects.Spec{{}})).To(Succeed())
+			watch := watcher.watches[vrgName]
+			Expect(watch.informerFactories).To(HaveLen(2))
+			Expect(watcher.watch(vrgName, []kubeobjects.Spec{{}})).To(Succeed())
+			Expect(watcher.watches[vrgName]).To(BeIdenticalTo(watch))
+
+			for range kubeObjectsChangeEventsBufferSize + 1 {
+				watcher.changed(vrgName, watch, configMaps, object("1", 1, nil))
+			}
+
+			Expect(watcher.events).To(HaveLen(kubeObjectsChangeEventsBufferSize))
+			Expect(watcher.changeTime(vrgName)).ToNot(BeZero())
+
+			watcher.unwatch(vrgName)
+			Expect(watcher.watches).To(BeEmpty())
+			Expect(watcher.changeTime(vrgName)).To(BeZero())
+		})
+	})
+
+	Context("Secret policies", func() {
+		It("applies those of the ramen config, then the recipe, then the VRG", func() {
+			ramenConfig := ramen.RamenConfig{KubeObjectsSecretPolicies: []ramen.KubeObjectsSecretPolicy{
+				{Action: ramen.KubeObjectsSecretPolicyActionExclude},
+			}}
+			recipe := &Recipe.Recipe{}
+			recipe.SetAnnotations(map[string]string{
+				RecipeSecretPoliciesAnnotation: `[{"types": ["kubernetes.io/tls"], "action": "Redact"}]`,
+			})
+			spec := &ramen.KubeObjectProtectionSpec{SecretPolicies: []ramen.KubeObjectsSecretPolicy{
+				{Action: ramen.KubeObjectsSecretPolicyActionRedact, DataSourceName: "source"},
+			}}
+
+			policies, err := kubeObjectsSecretPolicies(ramenConfig, recipe, spec)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(policies).To(Equal([]kubeobjects.SecretPolicy{
+				{Types: []string{}, Action: kubeobjects.SecretPolicyActionExclude},
+				{Types: []string{"kubernetes.io/tls"}, Action: kubeobjects.SecretPolicyActionRedact},
+				{Types: []string{}, Action: kubeobjects.SecretPolicyActionRedact, DataSourceName: "source"},
+			}))
+
+			policies, err = kubeObjectsSecretPolicies(ramen.RamenConfig{}, nil, nil)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(policies).To(BeEmpty())
+
+			recipe.SetAnnotations(map[string]string{RecipeSecretPoliciesAnnotation: "redact"})
+			_, err = kubeObjectsSecretPolicies(ramenConfig, recipe, spec)
+			Expect(err).To(MatchError(ContainSubstring(RecipeSecretPoliciesAnnotation)))
+		})
+	})
+
+	Context("Recover verification", func() {
+		It("reports the failures of each resource", func() {
+			status := kubeObjectsRecoverVerificationStatus(3, kubeobjects.RecoverVerification{
+				ObjectCount: 5,
+				Failures: []kubeobjects.RecoverFailure{
+					{Resource: "widgets.example.com", Count: 2, Message: "no matches for kind Widget"},
+				},
+			})
+
+			Expect(status.CaptureNumber).To(Equal(int64(3)))
+			Expect(status.ObjectCount).To(Equal(int32(5)))
+			Expect(status.Failures).To(Equal([]ramen.KubeObjectsRecoverFailure{
+				{Resource: "widgets.example.com", Count: 2, Message: "no matches for kind Widget"},
+			}))
+			Expect(kubeObjectsRecoverVerificationMessage(status)).To(Equal(
+				"kube objects capture 3 unrecoverable: widgets.example.com (2): no matches for kind Widget"))
+		})
+		It("reports the objects verified if none fail", func() {
+			status := kubeObjectsRecoverVerificationStatus(3, kubeobjects.RecoverVerification{ObjectCount: 5})
+
+			Expect(status.Failures).To(BeEmpty())
+			Expect(kubeObjectsRecoverVerificationMessage(status)).To(Equal(
+				"kube objects capture 3 recoverable: 5 objects verified"))
+		})
+		It("reports the objects unverifiable if none fail", func() {
+			status := kubeObjectsRecoverVerificationStatus(3, kubeobjects.RecoverVerification{
+				ObjectCount: 5, Unverifiable: 2,
+			})
+
+			Expect(status.Unverifiable).To(Equal(int32(2)))
+			Expect(kubeObjectsRecoverVerificationMessage(status)).To(Equal(
+				"kube objects capture 3 unverifiable: 5 objects verified, 2 not as their namespaces do not exist"))
+		})
+		It("orders the verifications of each store by the objects that fail, then are unverifiable", func() {
+			failed := kubeobjects.RecoverVerification{Failures: []kubeobjects.RecoverFailure{{Count: 1}}}
+			unverifiable := kubeobjects.RecoverVerification{ObjectCount: 1, Unverifiable: 1}
+			verified := kubeobjects.RecoverVerification{ObjectCount: 2}
+
+			Expect(kubeObjectsRecoverVerificationLess(failed, unverifiable)).To(BeTrue())
+			Expect(kubeObjectsRecoverVerificationLess(unverifiable, verified)).To(BeTrue())
+			Expect(kubeObjectsRecoverVerificationLess(verified, failed)).To(BeFalse())
+			Expect(kubeObjectsRecoverVerificationLess(verified, verified)).To(BeFalse())
+		})
+	})
 })
+
+// kubeObjectsSelectorFake selects every object of its resources, in the
+// namespaces of each
+type kubeObjectsSelectorFake map[schema.GroupVersionResource][]string
+
+func (s kubeObjectsSelectorFake) Resources() []schema.GroupVersionResource {
+	return slices.Collect(maps.Keys(s))
+}
+
+func (s kubeObjectsSelectorFake) Namespaces(resource schema.GroupVersionResource) []string {
+	return s[resource]
+}
+
+func (s kubeObjectsSelectorFake) Selects(resource schema.GroupVersionResource, _ metav1.Object) bool {
+	_, ok := s[resource]
+
+	return ok
+}
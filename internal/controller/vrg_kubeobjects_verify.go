@@ -0,0 +1,283 @@
+// SPDX-FileCopyrightText: The RamenDR authors
+// SPDX-License-Identifier: Apache-2.0
+
+package controllers
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/go-logr/logr"
+	ramen "github.com/ramendr/ramen/api/v1alpha1"
+	"github.com/ramendr/ramen/internal/controller/kubeobjects"
+	"github.com/ramendr/ramen/internal/controller/util"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	ctrl "sigs.k8s.io/controller-runtime"
+)
+
+// kubeObjectsRecoverVerifier verifies that the objects of a capture can be
+// recovered by recovering them with a server-side dry run.  Only the native
+// engine does so, as Velero's captures are not recovered object by object, and
+// the VRG's recoverable condition says so otherwise.
+type kubeObjectsRecoverVerifier interface {
+	RecoverVerify(
+		ctx context.Context,
+		s3ProfileName string,
+		s3KeyPrefix string,
+		captureName string,
+		recoverSpec kubeobjects.RecoverSpec,
+		log logr.Logger,
+	) (kubeobjects.RecoverVerification, error)
+}
+
+func kubeObjectsRecoverVerificationKey(namespaceName, vrgName string) string {
+	return s3PathNamePrefix(namespaceName, vrgName) + "kube-objects/recover-verification"
+}
+
+// kubeObjectsCaptureToRecoverFromKey is the key of the identifier of the
+// capture to recover from, stored by the primary so that the secondary need
+// not download the VRG to learn of a capture to verify.
+func kubeObjectsCaptureToRecoverFromKey(namespaceName, vrgName string) string {
+	return s3PathNamePrefix(namespaceName, vrgName) + "kube-objects/capture-to-recover-from"
+}
+
+// kubeObjectsCaptureToRecoverFromReport stores, on the primary cluster, the
+// identifier of the capture to recover from in each s3 store, for the
+// secondary to verify.
+func (v *VRGInstance) kubeObjectsCaptureToRecoverFromReport(capture *ramen.KubeObjectsCaptureIdentifier) {
+	if _, ok := v.reconciler.kubeObjects.(kubeObjectsRecoverVerifier); !ok {
+		return
+	}
+
+	vrg := v.instance
+	v.kubeObjectsCaptureReportUpload(kubeObjectsCaptureToRecoverFromKey(vrg.Namespace, vrg.Name), capture,
+		v.log.WithValues("number", capture.Number))
+}
+
+// kubeObjectsRecoverVerify verifies, on the secondary cluster, that the kube
+// objects of the primary's capture-to-recover-from would be recovered from
+// each s3 store, once per capture, and stores the result of the least
+// recoverable copy in each s3 store for the primary to report.
+func (v *VRGInstance) kubeObjectsRecoverVerify(result *ctrl.Result) {
+	verifier, ok := v.reconciler.kubeObjects.(kubeObjectsRecoverVerifier)
+	if !ok || v.kubeObjectProtectionDisabled("recover verification") ||
+		len(v.s3StoreAccessors) == 0 || v.skipIfS3ProfileIsForTest() {
+		return
+	}
+
+	vrg := v.instance
+	status := &vrg.Status.KubeObjectProtection
+
+	delaySetIfLess(result, kubeObjectsCaptureInterval(vrg.Spec.KubeObjectProtection), v.log)
+
+	capture := v.kubeObjectsCaptureToRecoverFromGet()
+	if capture == nil ||
+		(status.RecoverVerification != nil && status.RecoverVerification.CaptureNumber == capture.Number) {
+		return
+	}
+
+	log := v.log.WithValues("number", capture.Number)
+
+	var verification *kubeobjects.RecoverVerification
+
+	for _, s3StoreAccessor := range v.s3StoreAccessors {
+		storeVerification, err := v.kubeObjectsStoreRecoverVerify(verifier, s3StoreAccessor, capture.Number)
+		if err != nil {
+			log.Error(err, "Kube objects recover verification error", "profile", s3StoreAccessor.S3ProfileName)
+
+			return
+		}
+
+		if verification == nil || kubeObjectsRecoverVerificationLess(storeVerification, *verification) {
+			verification = &storeVerification
+		}
+	}
+
+	status.RecoverVerification = kubeObjectsRecoverVerificationStatus(capture.Number, *verification)
+	log.Info("Kube objects recover verified", "objects", verification.ObjectCount,
+		"unverifiable", verification.Unverifiable, "failures", verification.Failures)
+	v.kubeObjectsRecoverableConditionSet(status.RecoverVerification)
+
+	if len(verification.Failures) > 0 {
+		util.ReportIfNotPresent(v.reconciler.eventRecorder, vrg, corev1.EventTypeWarning,
+			util.EventReasonKubeObjectsUnrecoverable, kubeObjectsRecoverVerificationMessage(status.RecoverVerification))
+	}
+
+	v.kubeObjectsCaptureReportUpload(kubeObjectsRecoverVerificationKey(vrg.Namespace, vrg.Name),
+		status.RecoverVerification, log)
+}
+
+// kubeObjectsCaptureToRecoverFromGet returns the identifier of the capture to
+// recover from that the primary stored in the first s3 store that has one, or
+// nil if none does.
+func (v *VRGInstance) kubeObjectsCaptureToRecoverFromGet() *ramen.KubeObjectsCaptureIdentifier {
+	vrg := v.instance
+
+	for _, s3StoreAccessor := range v.s3StoreAccessors {
+		capture := &ramen.KubeObjectsCaptureIdentifier{}
+		if err := s3StoreAccessor.ObjectStorer.DownloadObject(
+			kubeObjectsCaptureToRecoverFromKey(vrg.Namespace, vrg.Name), capture,
+		); err != nil {
+			v.log.Info("Kube objects capture to recover from download error",
+				"profile", s3StoreAccessor.S3ProfileName, "error", err)
+
+			continue
+		}
+
+		return capture
+	}
+
+	return nil
+}
+
+func (v *VRGInstance) kubeObjectsStoreRecoverVerify(
+	verifier kubeObjectsRecoverVerifier, s3StoreAccessor s3StoreAccessor, captureNumber int64,
+) (kubeobjects.RecoverVerification, error) {
+	vrg := v.instance
+	log := v.log.WithValues("number", captureNumber, "profile", s3StoreAccessor.S3ProfileName)
+	pathName, _, captureNamePrefix := kubeObjectsCapturePathNamesAndNamePrefix(
+		vrg.Namespace, vrg.Name, captureNumber, v.reconciler.kubeObjects)
+	verification := kubeobjects.RecoverVerification{}
+
+	for _, recoverGroup := range v.recipeElements.RecoverWorkflow {
+		if recoverGroup.IsHook {
+			continue
+		}
+
+		recoverGroup.Transforms = kubeObjectsRecoverTransforms(vrg.Spec.KubeObjectProtection,
+			vrg.GetAnnotations()[DestinationClusterAnnotationKey])
+
+		groupVerification, err := verifier.RecoverVerify(v.ctx,
+			s3StoreAccessor.S3ProfileName, pathName,
+			kubeObjectsCaptureName(captureNamePrefix, recoverGroup.BackupName, s3StoreAccessor.S3ProfileName),
+			recoverGroup, log,
+		)
+		if err != nil {
+			return verification, fmt.Errorf("group %s: %w", recoverGroup.BackupName, err)
+		}
+
+		verification.Add(groupVerification)
+	}
+
+	return verification, nil
+}
+
+// kubeObjectsRecoverVerificationLess returns true if the first verification
+// is of fewer objects recoverable than the second: more that would fail, or as
+// many and more that could not be verified.
+func kubeObjectsRecoverVerificationLess(a, b kubeobjects.RecoverVerification) bool {
+	if a.FailureCount() != b.FailureCount() {
+		return a.FailureCount() > b.FailureCount()
+	}
+
+	return a.Unverifiable > b.Unverifiable
+}
+
+// kubeObjectsRecoverVerificationUpdate reports, on the primary cluster, the
+// secondary's verification of a capture more recent than that reported, if the
+// secondary has stored one in any s3 store.  That the engine does not verify
+// captures is reported instead, if it does not.
+func (v *VRGInstance) kubeObjectsRecoverVerificationUpdate() {
+	vrg := v.instance
+
+	if _, ok := v.reconciler.kubeObjects.(kubeObjectsRecoverVerifier); !ok {
+		if vrg.Spec.KubeObjectProtection != nil && !v.ramenConfig.KubeObjectProtection.Disabled {
+			setVRGKubeObjectsRecoverableCondition(&vrg.Status.Conditions, vrg.Generation,
+				metav1.ConditionUnknown, VRGConditionReasonKubeObjectsVerificationUnsupported, fmt.Sprintf(
+					"kube objects recover verification is not supported by the %s kube object protection engine",
+					kubeObjectProtectionEngineOrDefault(v.ramenConfig)))
+		}
+
+		return
+	}
+
+	status := &vrg.Status.KubeObjectProtection
+
+	if status.CaptureToRecoverFrom == nil || (status.RecoverVerification != nil &&
+		status.RecoverVerification.CaptureNumber >= status.CaptureToRecoverFrom.Number) {
+		return
+	}
+
+	for _, s3StoreAccessor := range v.s3StoreAccessors {
+		verification := &ramen.KubeObjectsRecoverVerification{}
+		if err := s3StoreAccessor.ObjectStorer.DownloadObject(
+			kubeObjectsRecoverVerificationKey(vrg.Namespace, vrg.Name), verification,
+		); err != nil {
+			v.log.Info("Kube objects recover verification download error",
+				"profile", s3StoreAccessor.S3ProfileName, "error", err)
+
+			continue
+		}
+
+		if status.RecoverVerification != nil && verification.CaptureNumber <= status.RecoverVerification.CaptureNumber {
+			return
+		}
+
+		status.RecoverVerification = verification
+		v.kubeObjectsRecoverableConditionSet(verification)
+
+		return
+	}
+}
+
+// kubeObjectsRecoverableConditionSet sets the recoverable condition from the
+// given verification: false if any object would not be recovered, unknown if
+// any could not be verified, and true otherwise.
+func (v *VRGInstance) kubeObjectsRecoverableConditionSet(verification *ramen.KubeObjectsRecoverVerification) {
+	vrg := v.instance
+	status, reason := metav1.ConditionTrue, VRGConditionReasonKubeObjectsRecoverable
+
+	switch {
+	case len(verification.Failures) > 0:
+		status, reason = metav1.ConditionFalse, VRGConditionReasonKubeObjectsUnrecoverable
+	case verification.Unverifiable > 0:
+		status, reason = metav1.ConditionUnknown, VRGConditionReasonKubeObjectsUnverifiable
+	}
+
+	setVRGKubeObjectsRecoverableCondition(&vrg.Status.Conditions, vrg.Generation, status, reason,
+		kubeObjectsRecoverVerificationMessage(verification))
+}
+
+func kubeObjectsRecoverVerificationStatus(
+	captureNumber int64, verification kubeobjects.RecoverVerification,
+) *ramen.KubeObjectsRecoverVerification {
+	failures := make([]ramen.KubeObjectsRecoverFailure, len(verification.Failures))
+	for i, failure := range verification.Failures {
+		failures[i] = ramen.KubeObjectsRecoverFailure{
+			Resource: failure.Resource,
+			Count:    int32(failure.Count),
+			Message:  failure.Message,
+		}
+	}
+
+	return &ramen.KubeObjectsRecoverVerification{
+		CaptureNumber: captureNumber,
+		Time:          metav1.Now(),
+		ObjectCount:   int32(verification.ObjectCount),
+		Unverifiable:  int32(verification.Unverifiable),
+		Failures:      failures,
+	}
+}
+
+func kubeObjectsRecoverVerificationMessage(verification *ramen.KubeObjectsRecoverVerification) string {
+	if len(verification.Failures) == 0 && verification.Unverifiable > 0 {
+		return fmt.Sprintf("kube objects capture %d unverifiable: %d objects verified, "+
+			"%d not as their namespaces do not exist",
+			verification.CaptureNumber, verification.ObjectCount, verification.Unverifiable)
+	}
+
+	if len(verification.Failures) == 0 {
+		return fmt.Sprintf("kube objects capture %d recoverable: %d objects verified",
+			verification.CaptureNumber, verification.ObjectCount)
+	}
+
+	failures := make([]string, len(verification.Failures))
+	for i, failure := range verification.Failures {
+		failures[i] = fmt.Sprintf("%s (%d): %s", failure.Resource, failure.Count, failure.Message)
+	}
+
+	return fmt.Sprintf("kube objects capture %d unrecoverable: %s",
+		verification.CaptureNumber, strings.Join(failures, "; "))
+}
@@ -0,0 +1,278 @@
+// SPDX-FileCopyrightText: The RamenDR authors
+// SPDX-License-Identifier: Apache-2.0
+
+// Objects of any resource may be watched for capture on change, with the
+// permissions of config/dr-cluster/rbac-native, which are granted only if
+// capture on change or the native engine is used, and are therefore not
+// declared here
+
+package controllers
+
+import (
+	"context"
+	"maps"
+	"reflect"
+	"sync"
+	"time"
+
+	"github.com/go-logr/logr"
+	ramen "github.com/ramendr/ramen/api/v1alpha1"
+	"github.com/ramendr/ramen/internal/controller/kubeobjects"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/metadata"
+	"k8s.io/client-go/metadata/metadatainformer"
+	"k8s.io/client-go/tools/cache"
+	"sigs.k8s.io/controller-runtime/pkg/event"
+)
+
+// kubeObjectsSelector selects the kube objects that capture specs select.
+type kubeObjectsSelector interface {
+	Resources() []schema.GroupVersionResource
+	Namespaces(schema.GroupVersionResource) []string
+	Selects(schema.GroupVersionResource, metav1.Object) bool
+}
+
+// kubeObjectsChangeEventsBufferSize is the number of VRG events buffered for
+// the reconciler.  An event is dropped rather than blocking the informers if
+// the buffer is full, and the change is captured when the VRG is next
+// reconciled, as the time of the change is recorded.
+const kubeObjectsChangeEventsBufferSize = 1024
+
+// kubeObjectsChangeWatcher watches the metadata of the kube objects that the
+// capture groups of each VRG that captures on change select, records the time
+// of their most recent change, and sends an event for the VRG so that it is
+// reconciled.  Each VRG's informers watch only the namespaces it selects, and
+// stop when it is unwatched.
+type kubeObjectsChangeWatcher struct {
+	selectorNew    func([]kubeobjects.Spec) (kubeObjectsSelector, error)
+	metadataClient metadata.Interface
+	events         chan event.GenericEvent
+	log            logr.Logger
+
+	mutex   sync.Mutex
+	ctx     context.Context
+	watches map[types.NamespacedName]*kubeObjectsChangeWatch
+}
+
+type kubeObjectsChangeWatch struct {
+	specs             []kubeobjects.Spec
+	selector          kubeObjectsSelector
+	informerFactories []metadatainformer.SharedInformerFactory
+	cancel            context.CancelFunc
+	changeTime        time.Time
+}
+
+func kubeObjectsChangeWatcherNew(
+	selectorNew func([]kubeobjects.Spec) (kubeObjectsSelector, error),
+	metadataClient metadata.Interface,
+	log logr.Logger,
+) *kubeObjectsChangeWatcher {
+	return &kubeObjectsChangeWatcher{
+		selectorNew:    selectorNew,
+		metadataClient: metadataClient,
+		events:         make(chan event.GenericEvent, kubeObjectsChangeEventsBufferSize),
+		log:            log,
+		watches:        map[types.NamespacedName]*kubeObjectsChangeWatch{},
+	}
+}
+
+// Start starts the informers of watches added before the manager started, and
+// those added after when they are.  It implements manager.Runnable.
+func (w *kubeObjectsChangeWatcher) Start(ctx context.Context) error {
+	w.mutex.Lock()
+	w.ctx = ctx
+
+	for _, watch := range w.watches {
+		watch.start(ctx)
+	}
+
+	w.mutex.Unlock()
+
+	<-ctx.Done()
+
+	w.mutex.Lock()
+	defer w.mutex.Unlock()
+
+	for vrgName, watch := range w.watches {
+		watch.stop()
+		delete(w.watches, vrgName)
+	}
+
+	return nil
+}
+
+// watch watches the objects that the given specs select for the named VRG, or
+// continues to if the specs are unchanged.
+func (w *kubeObjectsChangeWatcher) watch(vrgName types.NamespacedName, specs []kubeobjects.Spec) error {
+	w.mutex.Lock()
+	defer w.mutex.Unlock()
+
+	watch, ok := w.watches[vrgName]
+	if ok && reflect.DeepEqual(watch.specs, specs) {
+		return nil
+	}
+
+	selector, err := w.selectorNew(specs)
+	if err != nil {
+		return err
+	}
+
+	if ok {
+		watch.stop()
+		delete(w.watches, vrgName)
+	}
+
+	watch = &kubeObjectsChangeWatch{specs: specs, selector: selector}
+	informerFactories := map[string]metadatainformer.SharedInformerFactory{}
+
+	for _, resource := range selector.Resources() {
+		for _, namespaceName := range selector.Namespaces(resource) {
+			informerFactory, found := informerFactories[namespaceName]
+			if !found {
+				informerFactory = metadatainformer.NewFilteredSharedInformerFactory(
+					w.metadataClient, 0, namespaceName, nil)
+				informerFactories[namespaceName] = informerFactory
+				watch.informerFactories = append(watch.informerFactories, informerFactory)
+			}
+
+			if _, err := informerFactory.ForResource(resource).Informer().AddEventHandler(
+				w.eventHandler(vrgName, watch, resource),
+			); err != nil {
+				watch.stop()
+
+				return err
+			}
+		}
+	}
+
+	w.watches[vrgName] = watch
+	w.log.Info("Kube objects change watch started", "vrg", vrgName, "resources", selector.Resources())
+
+	if w.ctx != nil {
+		watch.start(w.ctx)
+	}
+
+	return nil
+}
+
+func (w *kubeObjectsChangeWatcher) unwatch(vrgName types.NamespacedName) {
+	w.mutex.Lock()
+	defer w.mutex.Unlock()
+
+	if watch, ok := w.watches[vrgName]; ok {
+		watch.stop()
+		delete(w.watches, vrgName)
+		w.log.Info("Kube objects change watch stopped", "vrg", vrgName)
+	}
+}
+
+func (watch *kubeObjectsChangeWatch) start(ctx context.Context) {
+	ctx, watch.cancel = context.WithCancel(ctx)
+
+	for _, informerFactory := range watch.informerFactories {
+		informerFactory.Start(ctx.Done())
+	}
+}
+
+// stop stops the informers, and waits for them to in the background so as not
+// to block watches of other VRGs.
+func (watch *kubeObjectsChangeWatch) stop() {
+	if watch.cancel != nil {
+		watch.cancel()
+	}
+
+	informerFactories := watch.informerFactories
+
+	go func() {
+		for _, informerFactory := range informerFactories {
+			informerFactory.Shutdown()
+		}
+	}()
+}
+
+// changeTime returns the time of the most recent change of an object watched
+// for the named VRG, or zero if none has changed since it was watched.
+func (w *kubeObjectsChangeWatcher) changeTime(vrgName types.NamespacedName) time.Time {
+	w.mutex.Lock()
+	defer w.mutex.Unlock()
+
+	if watch, ok := w.watches[vrgName]; ok {
+		return watch.changeTime
+	}
+
+	return time.Time{}
+}
+
+func (w *kubeObjectsChangeWatcher) eventHandler(
+	vrgName types.NamespacedName, watch *kubeObjectsChangeWatch, resource schema.GroupVersionResource,
+) cache.ResourceEventHandler {
+	changed := func(object interface{}) { w.changed(vrgName, watch, resource, object) }
+
+	return cache.ResourceEventHandlerDetailedFuncs{
+		// Objects listed initially exist since before the watch, not changed
+		AddFunc: func(object interface{}, isInInitialList bool) {
+			if !isInInitialList {
+				changed(object)
+			}
+		},
+		UpdateFunc: func(oldObject, newObject interface{}) {
+			if kubeObjectChanged(oldObject, newObject) {
+				changed(newObject)
+			}
+		},
+		DeleteFunc: func(object interface{}) {
+			if tombstone, ok := object.(cache.DeletedFinalStateUnknown); ok {
+				object = tombstone.Obj
+			}
+
+			changed(object)
+		},
+	}
+}
+
+// kubeObjectChanged returns true if an update of an object changed more than
+// its status, as far as its metadata tells.  The generation of objects that
+// do not maintain it is zero, so any update of those is a change.
+func kubeObjectChanged(oldObject, newObject interface{}) bool {
+	oldMetadata, ok := oldObject.(metav1.Object)
+	if !ok {
+		return false
+	}
+
+	newMetadata, ok := newObject.(metav1.Object)
+	if !ok || oldMetadata.GetResourceVersion() == newMetadata.GetResourceVersion() {
+		return false
+	}
+
+	return newMetadata.GetGeneration() == 0 ||
+		oldMetadata.GetGeneration() != newMetadata.GetGeneration() ||
+		!maps.Equal(oldMetadata.GetLabels(), newMetadata.GetLabels()) ||
+		!maps.Equal(oldMetadata.GetAnnotations(), newMetadata.GetAnnotations())
+}
+
+func (w *kubeObjectsChangeWatcher) changed(
+	vrgName types.NamespacedName, watch *kubeObjectsChangeWatch,
+	resource schema.GroupVersionResource, object interface{},
+) {
+	objectMetadata, ok := object.(metav1.Object)
+	if !ok || !watch.selector.Selects(resource, objectMetadata) {
+		return
+	}
+
+	w.mutex.Lock()
+	watch.changeTime = time.Now()
+	w.mutex.Unlock()
+
+	w.log.Info("Kube object changed", "vrg", vrgName, "resource", resource,
+		"name", objectMetadata.GetNamespace()+"/"+objectMetadata.GetName())
+
+	select {
+	case w.events <- event.GenericEvent{Object: &ramen.VolumeReplicationGroup{
+		ObjectMeta: metav1.ObjectMeta{Namespace: vrgName.Namespace, Name: vrgName.Name},
+	}}:
+	default:
+		w.log.Info("Kube object change event dropped", "vrg", vrgName)
+	}
+}
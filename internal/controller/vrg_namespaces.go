@@ -0,0 +1,277 @@
+// SPDX-FileCopyrightText: The RamenDR authors
+// SPDX-License-Identifier: Apache-2.0
+
+package controllers
+
+import (
+	"fmt"
+	"maps"
+	"slices"
+
+	ramen "github.com/ramendr/ramen/api/v1alpha1"
+	"github.com/ramendr/ramen/internal/controller/kubeobjects"
+	"github.com/ramendr/ramen/internal/controller/util"
+)
+
+// namespaceMapping maps each protected namespace to its name on the VRG's
+// cluster, and on its peer clusters.  Namespaces that are not mapped keep their
+// names.  Peer clusters are not distinguished, as a workload is protected by a
+// pair of clusters.
+type namespaceMapping struct {
+	local map[string]string
+	peer  map[string]string
+}
+
+// namespaceMappingGet returns the mapping, of the given mappings, of the named
+// cluster, the cluster a VRG is on as named by its destination cluster
+// annotation.
+func namespaceMappingGet(mappings []ramen.NamespaceMapping, clusterName string) namespaceMapping {
+	mapping := namespaceMapping{local: map[string]string{}, peer: map[string]string{}}
+
+	for _, namespaceMapping := range mappings {
+		local, peer := len(namespaceMapping.Clusters) == 0, len(namespaceMapping.Clusters) == 0
+
+		for _, name := range namespaceMapping.Clusters {
+			if name == clusterName {
+				local = true
+			} else {
+				peer = true
+			}
+		}
+
+		if local {
+			maps.Copy(mapping.local, namespaceMapping.Namespaces)
+		}
+
+		if peer {
+			maps.Copy(mapping.peer, namespaceMapping.Namespaces)
+		}
+	}
+
+	return mapping
+}
+
+func vrgNamespaceMapping(vrg ramen.VolumeReplicationGroup) namespaceMapping {
+	return namespaceMappingGet(vrg.Spec.NamespaceMappings, vrg.GetAnnotations()[DestinationClusterAnnotationKey])
+}
+
+func (m namespaceMapping) empty() bool {
+	return len(m.local) == 0 && len(m.peer) == 0
+}
+
+// localName returns the name of a protected namespace on the VRG's cluster.
+func (m namespaceMapping) localName(namespaceName string) string {
+	return mappedName(m.local, namespaceName)
+}
+
+// peerName returns the name of a protected namespace on the peer cluster.
+func (m namespaceMapping) peerName(namespaceName string) string {
+	return mappedName(m.peer, namespaceName)
+}
+
+// mappedName returns the name the given mapping maps a namespace to, or its
+// own name if it is not mapped.
+func mappedName(mapping map[string]string, namespaceName string) string {
+	if mappedNamespaceName, ok := mapping[namespaceName]; ok {
+		return mappedNamespaceName
+	}
+
+	return namespaceName
+}
+
+// recoverMapping maps the name of each protected namespace on the peer
+// cluster, the namespace its objects are recovered from, to its name on the
+// VRG's cluster, the namespace they are recovered into.
+func (m namespaceMapping) recoverMapping() map[string]string {
+	return m.between(m.peerName, m.localName)
+}
+
+// remoteMapping maps the name of each protected namespace on the VRG's
+// cluster to its name on the peer cluster, the namespace its PVCs are
+// replicated to.
+func (m namespaceMapping) remoteMapping() map[string]string {
+	return m.between(m.localName, m.peerName)
+}
+
+func (m namespaceMapping) between(from, to func(string) string) map[string]string {
+	mapping := map[string]string{}
+
+	for _, namespaces := range []map[string]string{m.local, m.peer} {
+		for namespaceName := range namespaces {
+			if from(namespaceName) != to(namespaceName) {
+				mapping[from(namespaceName)] = to(namespaceName)
+			}
+		}
+	}
+
+	return mapping
+}
+
+func (m namespaceMapping) recoverName(namespaceName string) string {
+	return mappedName(m.recoverMapping(), namespaceName)
+}
+
+// namespaceMapForRestore returns the name of the namespace that objects of the
+// named namespace on the peer cluster are restored into, and creates it if it is
+// mapped and does not exist.
+func (v *VRGInstance) namespaceMapForRestore(mapping namespaceMapping, namespaceName string) (string, error) {
+	mappedNamespaceName := mapping.recoverName(namespaceName)
+	if mappedNamespaceName == namespaceName {
+		return namespaceName, nil
+	}
+
+	if err := util.CreateNamespaceIfNotExists(v.ctx, v.reconciler.Client, mappedNamespaceName); err != nil {
+		return "", fmt.Errorf("namespace %s create error: %w", mappedNamespaceName, err)
+	}
+
+	return mappedNamespaceName, nil
+}
+
+// namespaceMappingsValidate returns an error if the VRG maps namespaces but is
+// not in an admin namespace, as only a VRG that protects namespaces may, or
+// if it maps the namespace of a consistency group of VolSync PVCs, as the PVCs
+// of a group are replicated to the namespace they are in.
+func namespaceMappingsValidate(vrg *ramen.VolumeReplicationGroup, adminNamespaceVRG bool) error {
+	if len(vrg.Spec.NamespaceMappings) == 0 {
+		return nil
+	}
+
+	if !adminNamespaceVRG {
+		return fmt.Errorf("namespace mappings are allowed only for a VRG in an admin namespace")
+	}
+
+	mapping := vrgNamespaceMapping(*vrg)
+
+	for i := range vrg.Spec.VolSync.RDSpec {
+		protectedPVC := &vrg.Spec.VolSync.RDSpec[i].ProtectedPVC
+		if err := namespaceMappingConsistencyGroupValidate(protectedPVC, mapping.recoverName); err != nil {
+			return err
+		}
+	}
+
+	remoteMapping := mapping.remoteMapping()
+	remoteName := func(namespaceName string) string { return mappedName(remoteMapping, namespaceName) }
+
+	for i := range vrg.Status.ProtectedPVCs {
+		protectedPVC := &vrg.Status.ProtectedPVCs[i]
+		if !protectedPVC.ProtectedByVolSync {
+			continue
+		}
+
+		if err := namespaceMappingConsistencyGroupValidate(protectedPVC, remoteName); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func namespaceMappingConsistencyGroupValidate(
+	protectedPVC *ramen.ProtectedPVC, namespaceName func(string) string,
+) error {
+	if _, ok := protectedPVC.Labels[ConsistencyGroupLabel]; ok &&
+		namespaceName(protectedPVC.Namespace) != protectedPVC.Namespace {
+		return fmt.Errorf("namespace %s of PVC %s of a consistency group is mapped, "+
+			"which consistency groups do not support", protectedPVC.Namespace, protectedPVC.Name)
+	}
+
+	return nil
+}
+
+// volSyncRDSpecs returns the VRG's VolSync replication destination specs with
+// the namespaces of their PVCs mapped to those on this cluster.  Those of PVCs
+// in a consistency group are not mapped, as namespaceMappingsValidate ensures.
+func (v *VRGInstance) volSyncRDSpecs() []ramen.VolSyncReplicationDestinationSpec {
+	rdSpecs := v.instance.Spec.VolSync.RDSpec
+
+	namespaceMapping := vrgNamespaceMapping(*v.instance)
+	if namespaceMapping.empty() {
+		return rdSpecs
+	}
+
+	mappedRDSpecs := make([]ramen.VolSyncReplicationDestinationSpec, len(rdSpecs))
+
+	for i := range rdSpecs {
+		rdSpecs[i].DeepCopyInto(&mappedRDSpecs[i])
+		mappedRDSpecs[i].ProtectedPVC.Namespace = namespaceMapping.recoverName(rdSpecs[i].ProtectedPVC.Namespace)
+	}
+
+	return mappedRDSpecs
+}
+
+// volSyncRDNamespacesCreate creates the namespaces, that do not exist, of the
+// given replication destination specs that are mapped.
+func (v *VRGInstance) volSyncRDNamespacesCreate(rdSpecs []ramen.VolSyncReplicationDestinationSpec) error {
+	for i := range rdSpecs {
+		if rdSpecs[i].ProtectedPVC.Namespace == v.instance.Spec.VolSync.RDSpec[i].ProtectedPVC.Namespace {
+			continue
+		}
+
+		if err := util.CreateNamespaceIfNotExists(v.ctx, v.reconciler.Client, rdSpecs[i].ProtectedPVC.Namespace); err != nil {
+			return fmt.Errorf("namespace %s create error: %w", rdSpecs[i].ProtectedPVC.Namespace, err)
+		}
+	}
+
+	return nil
+}
+
+func namespaceNamesMap(namespaceNames []string, namespaceName func(string) string) []string {
+	if namespaceNames == nil {
+		return nil
+	}
+
+	mappedNamespaceNames := make([]string, len(namespaceNames))
+	for i, name := range namespaceNames {
+		mappedNamespaceNames[i] = namespaceName(name)
+	}
+
+	return mappedNamespaceNames
+}
+
+// recipeElementsNamespacesMap maps the namespaces of the given recipe elements
+// to those on the VRG's cluster: the namespaces PVCs are selected in, objects
+// are captured in, and hooks are executed in, and the namespaces objects are
+// recovered into.  Objects are recovered from the namespaces they were
+// captured in on the peer cluster.  A recover group's own mapping of a
+// namespace takes precedence.
+func recipeElementsNamespacesMap(recipeElements *util.RecipeElements, mapping namespaceMapping) {
+	if mapping.empty() {
+		return
+	}
+
+	recipeElements.PvcSelector.NamespaceNames = namespaceNamesMap(
+		recipeElements.PvcSelector.NamespaceNames, mapping.localName)
+
+	captureWorkflow := slices.Clone(recipeElements.CaptureWorkflow)
+	for i := range captureWorkflow {
+		kubeObjectsSpecNamespacesMap(&captureWorkflow[i].Spec, mapping.localName)
+	}
+
+	recipeElements.CaptureWorkflow = captureWorkflow
+
+	recoverWorkflow := slices.Clone(recipeElements.RecoverWorkflow)
+	for i := range recoverWorkflow {
+		recoverGroup := &recoverWorkflow[i]
+		if recoverGroup.IsHook {
+			recoverGroup.Hook.Namespace = mapping.localName(recoverGroup.Hook.Namespace)
+
+			continue
+		}
+
+		kubeObjectsSpecNamespacesMap(&recoverGroup.Spec, mapping.peerName)
+
+		namespaceMapping := mapping.recoverMapping()
+		maps.Copy(namespaceMapping, recoverGroup.NamespaceMapping)
+		recoverGroup.NamespaceMapping = namespaceMapping
+	}
+
+	recipeElements.RecoverWorkflow = recoverWorkflow
+}
+
+func kubeObjectsSpecNamespacesMap(spec *kubeobjects.Spec, namespaceName func(string) string) {
+	spec.IncludedNamespaces = namespaceNamesMap(spec.IncludedNamespaces, namespaceName)
+
+	if spec.IsHook {
+		spec.Hook.Namespace = namespaceName(spec.Hook.Namespace)
+	}
+}
@@ -0,0 +1,100 @@
+// SPDX-FileCopyrightText: The RamenDR authors
+// SPDX-License-Identifier: Apache-2.0
+
+package controllers
+
+import (
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	ramen "github.com/ramendr/ramen/api/v1alpha1"
+	"github.com/ramendr/ramen/internal/controller/kubeobjects"
+	"github.com/ramendr/ramen/internal/controller/util"
+)
+
+var _ = Describe("namespaceMapping", func() {
+	mappings := []ramen.NamespaceMapping{
+		{Namespaces: map[string]string{"a": "a-any", "b": "b-any"}},
+		{Clusters: []string{"east"}, Namespaces: map[string]string{"a": "a-east"}},
+		{Clusters: []string{"west"}, Namespaces: map[string]string{"c": "c-west"}},
+	}
+
+	It("maps the protected namespaces to those on the cluster and its peer", func() {
+		mapping := namespaceMappingGet(mappings, "east")
+		Expect(mapping.localName("a")).To(Equal("a-east"))
+		Expect(mapping.localName("c")).To(Equal("c"))
+		Expect(mapping.peerName("a")).To(Equal("a-any"))
+		Expect(mapping.peerName("c")).To(Equal("c-west"))
+		Expect(mapping.localName("d")).To(Equal("d"))
+	})
+
+	It("maps namespaces from the peer cluster to this cluster, and back", func() {
+		mapping := namespaceMappingGet(mappings, "east")
+		Expect(mapping.recoverMapping()).To(Equal(map[string]string{"a-any": "a-east", "c-west": "c"}))
+		Expect(mapping.remoteMapping()).To(Equal(map[string]string{"a-east": "a-any", "c": "c-west"}))
+		Expect(namespaceMappingGet(mappings, "west").recoverMapping()).To(Equal(
+			map[string]string{"a-east": "a-any", "c": "c-west"}))
+	})
+
+	It("maps nothing without mappings", func() {
+		mapping := namespaceMappingGet(nil, "east")
+		Expect(mapping.empty()).To(BeTrue())
+		Expect(mapping.recoverMapping()).To(BeEmpty())
+	})
+
+	It("maps the namespaces of recipe elements", func() {
+		spec := func(namespaceNames ...string) kubeobjects.Spec {
+			return kubeobjects.Spec{KubeResourcesSpec: kubeobjects.KubeResourcesSpec{IncludedNamespaces: namespaceNames}}
+		}
+		hookSpec := spec()
+		hookSpec.IsHook = true
+		hookSpec.Hook.Namespace = "c"
+		recipeElements := util.RecipeElements{
+			PvcSelector:     util.PvcSelector{NamespaceNames: []string{"a", "c"}},
+			CaptureWorkflow: []kubeobjects.CaptureSpec{{Spec: spec("a")}},
+			RecoverWorkflow: []kubeobjects.RecoverSpec{
+				{Spec: spec("a", "c")},
+				{Spec: hookSpec},
+				{Spec: spec("a"), NamespaceMapping: map[string]string{"a-any": "a-other"}},
+			},
+		}
+		captureWorkflow := recipeElements.CaptureWorkflow
+
+		recipeElementsNamespacesMap(&recipeElements, namespaceMappingGet(mappings, "west"))
+		Expect(recipeElements.PvcSelector.NamespaceNames).To(Equal([]string{"a-any", "c-west"}))
+		Expect(recipeElements.CaptureWorkflow[0].IncludedNamespaces).To(Equal([]string{"a-any"}))
+		Expect(captureWorkflow[0].IncludedNamespaces).To(Equal([]string{"a"}))
+		Expect(recipeElements.RecoverWorkflow[0].IncludedNamespaces).To(Equal([]string{"a-east", "c"}))
+		Expect(recipeElements.RecoverWorkflow[0].NamespaceMapping).To(Equal(
+			map[string]string{"a-east": "a-any", "c": "c-west"}))
+		Expect(recipeElements.RecoverWorkflow[1].Hook.Namespace).To(Equal("c-west"))
+		Expect(recipeElements.RecoverWorkflow[2].NamespaceMapping).To(Equal(
+			map[string]string{"a-east": "a-any", "c": "c-west", "a-any": "a-other"}))
+	})
+
+	It("allows mappings only in an admin namespace, and not of consistency groups", func() {
+		protectedPVC := func(namespaceName string, labels map[string]string) ramen.ProtectedPVC {
+			return ramen.ProtectedPVC{Namespace: namespaceName, Name: "pvc", ProtectedByVolSync: true, Labels: labels}
+		}
+		consistencyGroup := map[string]string{ConsistencyGroupLabel: "cg"}
+		vrg := &ramen.VolumeReplicationGroup{}
+		vrg.SetAnnotations(map[string]string{DestinationClusterAnnotationKey: "east"})
+		Expect(namespaceMappingsValidate(vrg, false)).To(Succeed())
+
+		vrg.Spec.NamespaceMappings = mappings
+		Expect(namespaceMappingsValidate(vrg, false)).To(MatchError(ContainSubstring("admin namespace")))
+		Expect(namespaceMappingsValidate(vrg, true)).To(Succeed())
+
+		vrg.Spec.VolSync.RDSpec = []ramen.VolSyncReplicationDestinationSpec{
+			{ProtectedPVC: protectedPVC("a-any", nil)},
+			{ProtectedPVC: protectedPVC("d", consistencyGroup)},
+		}
+		Expect(namespaceMappingsValidate(vrg, true)).To(Succeed())
+
+		vrg.Spec.VolSync.RDSpec[1].ProtectedPVC.Namespace = "a-any"
+		Expect(namespaceMappingsValidate(vrg, true)).To(MatchError(ContainSubstring("consistency group")))
+
+		vrg.Spec.VolSync.RDSpec = nil
+		vrg.Status.ProtectedPVCs = []ramen.ProtectedPVC{protectedPVC("a-east", consistencyGroup)}
+		Expect(namespaceMappingsValidate(vrg, true)).To(MatchError(ContainSubstring("consistency group")))
+	})
+})
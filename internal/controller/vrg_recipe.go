@@ -7,16 +7,17 @@ import (
 	"context"
 	"encoding/json"
 	"fmt"
-	"os"
 	"strings"
 
 	"github.com/go-logr/logr"
 	ramen "github.com/ramendr/ramen/api/v1alpha1"
 	recipecore "github.com/ramendr/ramen/internal/controller/core"
+	"github.com/ramendr/ramen/internal/controller/hooks"
 	"github.com/ramendr/ramen/internal/controller/kubeobjects"
 	"github.com/ramendr/ramen/internal/controller/util"
 	recipev1 "github.com/ramendr/recipe/api/v1alpha1"
 	"golang.org/x/exp/slices"
+	k8serrors "k8s.io/apimachinery/pkg/api/errors"
 	"k8s.io/apimachinery/pkg/types"
 	"k8s.io/apimachinery/pkg/util/sets"
 	"sigs.k8s.io/controller-runtime/pkg/builder"
@@ -89,9 +90,33 @@ func GetPVCSelector(ctx context.Context, reader client.Reader, vrg ramen.VolumeR
 	return recipeElements.PvcSelector, nil
 }
 
-//nolint:funlen
+// RecipeElementsGet returns the recipe elements of the VRG, with the protected
+// namespaces mapped to the namespaces on the VRG's cluster, and the secret
+// policies set for each capture group.
 func RecipeElementsGet(ctx context.Context, reader client.Reader, vrg ramen.VolumeReplicationGroup,
 	ramenConfig ramen.RamenConfig, log logr.Logger,
+) (util.RecipeElements, error) {
+	recipeElements, err := recipeElementsGet(ctx, reader, vrg, ramenConfig, log)
+	if err != nil {
+		return recipeElements, err
+	}
+
+	recipeElementsNamespacesMap(&recipeElements, vrgNamespaceMapping(vrg))
+
+	secretPolicies, err := kubeObjectsSecretPolicies(ramenConfig, recipeElements.RecipeWithParams,
+		vrg.Spec.KubeObjectProtection)
+	if err != nil {
+		return recipeElements, err
+	}
+
+	recipeElementsSecretPoliciesSet(&recipeElements, secretPolicies)
+
+	return recipeElements, nil
+}
+
+//nolint:funlen
+func recipeElementsGet(ctx context.Context, reader client.Reader, vrg ramen.VolumeReplicationGroup,
+	ramenConfig ramen.RamenConfig, log logr.Logger,
 ) (util.RecipeElements, error) {
 	var recipeElements util.RecipeElements
 
@@ -140,6 +165,10 @@ func RecipeElementsGet(ctx context.Context, reader client.Reader, vrg ramen.Volu
 		return recipeElements, fmt.Errorf("recipe %v parameters expansion error: %w", recipeNamespacedName.String(), err)
 	}
 
+	if err := recipeHooksValidate(recipe); err != nil {
+		return recipeElements, fmt.Errorf("recipe %v hooks validation error: %w", recipeNamespacedName.String(), err)
+	}
+
 	var selector PvcSelector
 	if recipe.Spec.Volumes == nil {
 		selector = getPVCSelector(vrg, ramenConfig, nil, nil)
@@ -154,6 +183,7 @@ func RecipeElementsGet(ctx context.Context, reader client.Reader, vrg ramen.Volu
 			NamespaceNames: selector.NamespaceNames,
 		},
 		RecipeWithParams:    &recipe,
+		Parameters:          parameters,
 		StopRecipeReconcile: isRecipeReconcileToStop(parameters),
 	}
 
@@ -239,7 +269,7 @@ func RecipeParametersExpand(ctx context.Context, recipe *recipev1.Recipe, parame
 	}
 
 	s1 := string(bytes)
-	s2 := parametersExpand(s1, parameters)
+	s2 := util.ParametersExpand(s1, parameters, hooks.ExecHookOutputParameterNames(recipe.GetAnnotations()))
 
 	if err = json.Unmarshal([]byte(s2), spec); err != nil {
 		return fmt.Errorf("recipe spec %v json unmarshal error: %w", s2, err)
@@ -252,14 +282,6 @@ func RecipeParametersExpand(ctx context.Context, recipe *recipev1.Recipe, parame
 	return nil
 }
 
-func parametersExpand(s string, parameters map[string][]string) string {
-	return os.Expand(s, func(key string) string {
-		values := parameters[key]
-
-		return strings.Join(values, `","`)
-	})
-}
-
 func recipeWorkflowsGet(recipe recipev1.Recipe, recipeElements *util.RecipeElements, vrg ramen.VolumeReplicationGroup,
 	ramenConfig ramen.RamenConfig,
 ) error {
@@ -329,6 +351,75 @@ func recipeNamespacesValidate(recipeElements util.RecipeElements, vrg ramen.Volu
 	return nil
 }
 
+// recipeHooksValidate returns an error for the first check of the recipe's
+// hooks whose condition does not compile, or for the first of its exec hook
+// annotations that is not valid or names no hook operation.
+func recipeHooksValidate(recipe recipev1.Recipe) error {
+	if err := hooks.ExecHookAnnotationNamesValidate(recipe.GetAnnotations(), recipe.Spec.Hooks); err != nil {
+		return err
+	}
+
+	for _, hook := range recipe.Spec.Hooks {
+		if err := hooks.ExecHookConcurrencyValidate(recipe.GetAnnotations(), hook.Name); err != nil {
+			return fmt.Errorf("hook %s: %w", hook.Name, err)
+		}
+
+		for _, op := range hook.Ops {
+			if err := hooks.ExecHookOutputValidate(recipe.GetAnnotations(), hook.Name, op.Name); err != nil {
+				return fmt.Errorf("hook %s operation %s: %w", hook.Name, op.Name, err)
+			}
+		}
+
+		for _, chk := range hook.Chks {
+			if err := hooks.CheckConditionValidate(chk.Condition); err != nil {
+				return fmt.Errorf("hook %s check %s: %w", hook.Name, chk.Name, err)
+			}
+		}
+	}
+
+	return nil
+}
+
+// recipeValidatedConditionSet sets the RecipeValidated condition of a VRG that
+// refers to a recipe to the error getting its recipe elements or, if there is
+// none, to whether the recipe is valid with the VRG's parameters.
+func (v *VRGInstance) recipeValidatedConditionSet(err error) {
+	if v.instance.Spec.KubeObjectProtection == nil || v.instance.Spec.KubeObjectProtection.RecipeRef == nil {
+		return
+	}
+
+	recipeNamespacedName := types.NamespacedName{
+		Namespace: v.instance.Spec.KubeObjectProtection.RecipeRef.Namespace,
+		Name:      v.instance.Spec.KubeObjectProtection.RecipeRef.Name,
+	}
+	reason := VRGConditionReasonRecipeInvalid
+
+	if err == nil {
+		err = v.recipeValidate(recipeNamespacedName)
+	}
+
+	if k8serrors.IsNotFound(err) {
+		reason = VRGConditionReasonRecipeNotFound
+	}
+
+	setVRGRecipeValidatedCondition(&v.instance.Status.Conditions, v.instance.Generation, reason, err)
+}
+
+func (v *VRGInstance) recipeValidate(recipeNamespacedName types.NamespacedName) error {
+	recipe, err := getRecipeObj(v.ctx, recipeNamespacedName, *v.instance, v.reconciler.Client, *v.ramenConfig)
+	if err != nil {
+		return err
+	}
+
+	// non-nil, so that parameters the recipe refers to are reported missing
+	parameters := v.recipeElements.Parameters
+	if parameters == nil {
+		parameters = map[string][]string{}
+	}
+
+	return recipeValidate(v.ctx, recipe, parameters)
+}
+
 func recipeNamespaceNames(recipeElements util.RecipeElements) sets.Set[string] {
 	namespaceNames := make(sets.Set[string], 0)
 
@@ -342,7 +433,8 @@ func recipeNamespaceNames(recipeElements util.RecipeElements) sets.Set[string] {
 		namespaceNames.Insert(recoverSpec.IncludedNamespaces...)
 	}
 
-	return namespaceNames
+	// a vm hook without a namespace selects vms in the protected vm namespaces
+	return namespaceNames.Delete("")
 }
 
 func recipesWatch(b *builder.Builder, m objectToReconcileRequestsMapper) *builder.Builder {
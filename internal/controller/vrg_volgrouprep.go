@@ -40,16 +40,10 @@ func (v *VRGInstance) reconcileVolGroupRepsAsPrimary(groupPVCs map[types.Namespa
 			continue
 		}
 
-		for idx := range pvcs {
-			pvc := pvcs[idx]
-
-			if err := v.uploadPVandPVCtoS3Stores(pvc, log); err != nil {
-				log.Error(err, "Requeuing due to failure to upload PV/PVC object to S3 store(s)")
-
-				v.requeue()
+		if err := v.uploadPVsandPVCsToS3Stores(pvcs, log); err != nil {
+			log.Error(err, "Requeuing due to failure to upload PV/PVC object to S3 store(s)")
 
-				continue
-			}
+			v.requeue()
 		}
 
 		if err := v.uploadVGRandVGRCtoS3Stores(vgrNamespacedName, log); err != nil {
@@ -226,25 +220,6 @@ func (v *VRGInstance) uploadVGRandVGRCtoS3Stores(vrNamespacedName types.Namespac
 	return nil
 }
 
-func (v *VRGInstance) UploadVGRandVGRCtoS3Store(s3ProfileName string, vgr *volrep.VolumeGroupReplication) error {
-	if s3ProfileName == "" {
-		return fmt.Errorf("missing S3 profiles, failed to protect cluster data for VGR %s", vgr.Name)
-	}
-
-	objectStore, err := v.getObjectStorer(s3ProfileName)
-	if err != nil {
-		return fmt.Errorf("error getting object store, failed to protect cluster data for VGR %s, %w", vgr.Name, err)
-	}
-
-	vgrc, err := v.getVGRCFromVGR(vgr)
-	if err != nil {
-		return fmt.Errorf("error getting VGRC for VGR, failed to protect cluster data for VGRC %s to s3Profile %s, %w",
-			vgrc.Name, s3ProfileName, err)
-	}
-
-	return v.UploadVGRAndVGRCtoS3(s3ProfileName, objectStore, vgr, &vgrc)
-}
-
 func (v *VRGInstance) UploadVGRAndVGRCtoS3(s3ProfileName string, objectStore ObjectStorer,
 	vgr *volrep.VolumeGroupReplication, vgrc *volrep.VolumeGroupReplicationContent,
 ) error {
@@ -275,23 +250,38 @@ func (v *VRGInstance) UploadVGRAndVGRCtoS3(s3ProfileName string, objectStore Obj
 	return nil
 }
 
+// UploadVGRandVGRCtoS3Stores uploads the VGR and its VGRC to all the S3
+// profiles in the VRG spec concurrently, and returns the profiles uploaded to.
 func (v *VRGInstance) UploadVGRandVGRCtoS3Stores(vgr *volrep.VolumeGroupReplication,
 	log logr.Logger,
 ) ([]string, error) {
+	vgrc, err := v.getVGRCFromVGR(vgr)
+	if err != nil {
+		return []string{}, fmt.Errorf("error getting VGRC for VGR, failed to protect cluster data for VGR %s, %w",
+			vgr.Name, err)
+	}
+
+	uploadErrs := v.s3UploadsDo(1, func(_ int, s3ProfileName string, objectStore ObjectStorer) error {
+		return v.UploadVGRAndVGRCtoS3(s3ProfileName, objectStore, vgr, &vgrc)
+	})[0]
+
 	succeededProfiles := []string{}
-	// Upload the VGR and VGRC to all the S3 profiles in the VRG spec
-	for _, s3ProfileName := range v.instance.Spec.S3Profiles {
-		err := v.UploadVGRandVGRCtoS3Store(s3ProfileName, vgr)
-		if err != nil {
-			rmnutil.ReportIfNotPresent(v.reconciler.eventRecorder, v.instance, corev1.EventTypeWarning,
-				rmnutil.EventReasonUploadFailed, err.Error())
 
-			return succeededProfiles, err
+	for _, s3ProfileName := range v.instance.Spec.S3Profiles {
+		if _, ok := uploadErrs[s3ProfileName]; !ok {
+			succeededProfiles = append(succeededProfiles, s3ProfileName)
 		}
+	}
+
+	if len(uploadErrs) != 0 {
+		rmnutil.ReportIfNotPresent(v.reconciler.eventRecorder, v.instance, corev1.EventTypeWarning,
+			rmnutil.EventReasonUploadFailed, uploadErrs.Error())
 
-		succeededProfiles = append(succeededProfiles, s3ProfileName)
+		return succeededProfiles, uploadErrs
 	}
 
+	log.Info("Uploaded VGR/VGRC cluster data", "s3Profiles", succeededProfiles)
+
 	return succeededProfiles, nil
 }
 
@@ -905,6 +895,9 @@ func (v *VRGInstance) restoreVGRsAndVGRCsFromS3(result *ctrl.Result) error {
 			continue
 		}
 
+		// Downloads the manifest once for all the types of objects restored
+		objectStore = v.s3ManifestBatch(s3ProfileName, objectStore)
+
 		var vgrcCount, vgrCount int
 
 		// Restore all VGRCs found in the s3 store. If any failure, the next profile will be retried
@@ -966,6 +959,19 @@ func (v *VRGInstance) restoreVGRsFromObjectStore(objectStore ObjectStorer, s3Pro
 
 	v.log.Info(fmt.Sprintf("Found %d VGRs in s3 store using profile %s", len(vgrList), s3ProfileName))
 
+	namespaceMapping := vrgNamespaceMapping(*v.instance)
+
+	for i := range vgrList {
+		vgr := &vgrList[i]
+
+		namespaceName, err := v.namespaceMapForRestore(namespaceMapping, vgr.Namespace)
+		if err != nil {
+			return 0, fmt.Errorf("VGR %s/%s restore error: %w", vgr.Namespace, vgr.Name, err)
+		}
+
+		vgr.Namespace = namespaceName
+	}
+
 	return restoreClusterDataObjects(v, vgrList, "VGR", v.cleanupVGRForRestore, v.validateExistingVGR)
 }
 
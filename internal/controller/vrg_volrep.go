@@ -6,8 +6,11 @@ package controllers
 import (
 	"errors"
 	"fmt"
+	"maps"
 	"reflect"
+	"slices"
 	"strings"
+	"sync"
 
 	"github.com/aws/aws-sdk-go/aws/awserr"
 	"github.com/go-logr/logr"
@@ -61,6 +64,7 @@ func logWithPvcName(log logr.Logger, pvc *corev1.PersistentVolumeClaim) logr.Log
 //nolint:funlen,gocognit,cyclop
 func (v *VRGInstance) reconcileVolRepsAsPrimary() {
 	groupPVCs := make(map[types.NamespacedName][]*corev1.PersistentVolumeClaim)
+	pvcsToUpload := make([]*corev1.PersistentVolumeClaim, 0, len(v.volRepPVCs))
 
 	v.log.Info(fmt.Sprintf("Reconciling VolRep as Primary. %d VolRepPVCs", len(v.volRepPVCs)))
 
@@ -112,21 +116,21 @@ func (v *VRGInstance) reconcileVolRepsAsPrimary() {
 			continue
 		}
 
-		// Protect the PVC's PV object stored in etcd by uploading it to S3
-		// store(s).  Note that the VRG is responsible only to protect the PV
-		// object of each PVC of the subscription.  However, the PVC object
-		// itself is assumed to be protected along with other k8s objects in the
-		// subscription, such as, the deployment, pods, services, etc., by an
-		// entity external to the VRG a la IaC.
-		if err := v.uploadPVandPVCtoS3Stores(pvc, log); err != nil {
-			log.Error(err, "Requeuing due to failure to upload PV object to S3 store(s)")
+		pvcsToUpload = append(pvcsToUpload, pvc)
 
-			v.requeue()
+		log.Info("Successfully processed VolumeReplication for PersistentVolumeClaim")
+	}
 
-			continue
-		}
+	// Protect the PVCs' PV objects stored in etcd by uploading them to S3
+	// store(s).  Note that the VRG is responsible only to protect the PV
+	// object of each PVC of the subscription.  However, the PVC object
+	// itself is assumed to be protected along with other k8s objects in the
+	// subscription, such as, the deployment, pods, services, etc., by an
+	// entity external to the VRG a la IaC.
+	if err := v.uploadPVsandPVCsToS3Stores(pvcsToUpload, v.log); err != nil {
+		v.log.Error(err, "Requeuing due to failure to upload PV object to S3 store(s)")
 
-		log.Info("Successfully processed VolumeReplication for PersistentVolumeClaim")
+		v.requeue()
 	}
 
 	v.reconcileVolGroupRepsAsPrimary(groupPVCs)
@@ -618,44 +622,87 @@ func (v *VRGInstance) isArchivedAlready(pvc *corev1.PersistentVolumeClaim, log l
 	return true
 }
 
-// Upload PV to the list of S3 stores in the VRG spec
-func (v *VRGInstance) uploadPVandPVCtoS3Stores(pvc *corev1.PersistentVolumeClaim, log logr.Logger) (err error) {
-	if v.isArchivedAlready(pvc, log) {
-		msg := fmt.Sprintf("PV cluster data already protected for PVC %s", pvc.Name)
-		v.updatePVCClusterDataProtectedCondition(pvc.Namespace, pvc.Name,
-			VRGConditionReasonUploaded, msg)
+// uploadPVsandPVCsToS3Stores uploads the PV and PVC objects of the given PVCs
+// to the list of S3 stores in the VRG spec, concurrently across PVCs and S3
+// stores.  A PVC and its PV are annotated as archived only once uploaded to
+// all the S3 stores; those already archived at their current generation are
+// skipped.  Returns an error if any PVC failed to upload to any S3 store.
+func (v *VRGInstance) uploadPVsandPVCsToS3Stores(pvcs []*corev1.PersistentVolumeClaim, log logr.Logger) error {
+	pendingPVCs := make([]*corev1.PersistentVolumeClaim, 0, len(pvcs))
+
+	for _, pvc := range pvcs {
+		if v.isArchivedAlready(pvc, log) {
+			msg := fmt.Sprintf("PV cluster data already protected for PVC %s", pvc.Name)
+			v.updatePVCClusterDataProtectedCondition(pvc.Namespace, pvc.Name,
+				VRGConditionReasonUploaded, msg)
 
+			continue
+		}
+
+		pendingPVCs = append(pendingPVCs, pvc)
+	}
+
+	if len(pendingPVCs) == 0 {
 		return nil
 	}
 
 	// Error out if VRG has no S3 profiles
-	numProfilesToUpload := len(v.instance.Spec.S3Profiles)
-	if numProfilesToUpload == 0 {
+	if len(v.instance.Spec.S3Profiles) == 0 {
 		msg := "Error uploading PV cluster data because VRG spec has no S3 profiles"
-		v.updatePVCClusterDataProtectedCondition(pvc.Namespace, pvc.Name,
-			VRGConditionReasonUploadError, msg)
+
+		for _, pvc := range pendingPVCs {
+			v.updatePVCClusterDataProtectedCondition(pvc.Namespace, pvc.Name,
+				VRGConditionReasonUploadError, msg)
+		}
+
 		v.log.Info(msg)
 
-		return fmt.Errorf("error uploading cluster data of PV %s because VRG spec has no S3 profiles",
-			pvc.Name)
+		return fmt.Errorf("error uploading cluster data of %d PV(s) because VRG spec has no S3 profiles",
+			len(pendingPVCs))
 	}
 
-	s3Profiles, err := v.UploadPVandPVCtoS3Stores(pvc, log)
-	if err != nil {
-		return fmt.Errorf("failed to upload PV/PVC with error (%w). Uploaded to %v S3 profile(s)", err, s3Profiles)
+	pvs := make([]corev1.PersistentVolume, len(pendingPVCs))
+	pvErrs := make([]error, len(pendingPVCs))
+
+	for i, pvc := range pendingPVCs {
+		pvs[i], pvErrs[i] = v.getPVFromPVC(pvc)
 	}
 
-	numProfilesUploaded := len(s3Profiles)
+	uploadErrs := v.s3UploadsDo(len(pendingPVCs), func(i int, s3ProfileName string, objectStore ObjectStorer) error {
+		if pvErrs[i] != nil {
+			return fmt.Errorf("error getting PV for PVC, failed to protect cluster data for PVC %s to s3Profile %s, %w",
+				pendingPVCs[i].Name, s3ProfileName, pvErrs[i])
+		}
 
-	if numProfilesUploaded != numProfilesToUpload {
-		// Merely defensive as we don't expect to reach here
-		msg := fmt.Sprintf("uploaded PV/PVC cluster data to only  %d of %d S3 profile(s): %v",
-			numProfilesUploaded, numProfilesToUpload, s3Profiles)
-		v.log.Info(msg)
-		v.updatePVCClusterDataProtectedCondition(pvc.Namespace, pvc.Name,
-			VRGConditionReasonUploadError, msg)
+		return v.UploadPVAndPVCtoS3(s3ProfileName, objectStore, &pvs[i], pendingPVCs[i])
+	})
 
-		return errors.New(msg)
+	errs := make([]error, 0, len(pendingPVCs))
+
+	for i, pvc := range pendingPVCs {
+		if err := v.pvAndPVCArchive(pvc, uploadErrs[i], logWithPvcName(log, pvc)); err != nil {
+			errs = append(errs, err)
+		}
+	}
+
+	return errors.Join(errs...)
+}
+
+// pvAndPVCArchive records the outcome of the uploads of a PVC and its PV to
+// the VRG's S3 stores, annotating them as archived if none failed.
+func (v *VRGInstance) pvAndPVCArchive(pvc *corev1.PersistentVolumeClaim, uploadErrs s3ProfileErrors,
+	log logr.Logger,
+) error {
+	numProfilesToUpload := len(v.instance.Spec.S3Profiles)
+
+	if len(uploadErrs) != 0 {
+		v.updatePVCClusterDataProtectedCondition(pvc.Namespace, pvc.Name, VRGConditionReasonUploadError,
+			uploadErrs.Error())
+		rmnutil.ReportIfNotPresent(v.reconciler.eventRecorder, v.instance, corev1.EventTypeWarning,
+			rmnutil.EventReasonUploadFailed, uploadErrs.Error())
+
+		return fmt.Errorf("failed to upload PV/PVC of PVC %s to %d of %d S3 profile(s), %w",
+			pvc.Name, len(uploadErrs), numProfilesToUpload, uploadErrs)
 	}
 
 	if err := v.addArchivedAnnotationForPVC(pvc, log); err != nil {
@@ -667,33 +714,14 @@ func (v *VRGInstance) uploadPVandPVCtoS3Stores(pvc *corev1.PersistentVolumeClaim
 	}
 
 	msg := fmt.Sprintf("Done uploading PV/PVC cluster data to %d of %d S3 profile(s): %v",
-		numProfilesUploaded, numProfilesToUpload, s3Profiles)
-	v.log.Info(msg)
+		numProfilesToUpload, numProfilesToUpload, v.instance.Spec.S3Profiles)
+	log.Info(msg)
 	v.updatePVCClusterDataProtectedCondition(pvc.Namespace, pvc.Name,
 		VRGConditionReasonUploaded, msg)
 
 	return nil
 }
 
-func (v *VRGInstance) UploadPVandPVCtoS3Store(s3ProfileName string, pvc *corev1.PersistentVolumeClaim) error {
-	if s3ProfileName == "" {
-		return fmt.Errorf("missing S3 profiles, failed to protect cluster data for PVC %s", pvc.Name)
-	}
-
-	objectStore, err := v.getObjectStorer(s3ProfileName)
-	if err != nil {
-		return fmt.Errorf("error getting object store, failed to protect cluster data for PVC %s, %w", pvc.Name, err)
-	}
-
-	pv, err := v.getPVFromPVC(pvc)
-	if err != nil {
-		return fmt.Errorf("error getting PV for PVC, failed to protect cluster data for PVC %s to s3Profile %s, %w",
-			pvc.Name, s3ProfileName, err)
-	}
-
-	return v.UploadPVAndPVCtoS3(s3ProfileName, objectStore, &pv, pvc)
-}
-
 func (v *VRGInstance) UploadPVAndPVCtoS3(s3ProfileName string, objectStore ObjectStorer,
 	pv *corev1.PersistentVolume, pvc *corev1.PersistentVolumeClaim,
 ) error {
@@ -724,27 +752,6 @@ func (v *VRGInstance) UploadPVAndPVCtoS3(s3ProfileName string, objectStore Objec
 	return nil
 }
 
-func (v *VRGInstance) UploadPVandPVCtoS3Stores(pvc *corev1.PersistentVolumeClaim,
-	log logr.Logger,
-) ([]string, error) {
-	succeededProfiles := []string{}
-	// Upload the PV to all the S3 profiles in the VRG spec
-	for _, s3ProfileName := range v.instance.Spec.S3Profiles {
-		err := v.UploadPVandPVCtoS3Store(s3ProfileName, pvc)
-		if err != nil {
-			v.updatePVCClusterDataProtectedCondition(pvc.Namespace, pvc.Name, VRGConditionReasonUploadError, err.Error())
-			rmnutil.ReportIfNotPresent(v.reconciler.eventRecorder, v.instance, corev1.EventTypeWarning,
-				rmnutil.EventReasonUploadFailed, err.Error())
-
-			return succeededProfiles, err
-		}
-
-		succeededProfiles = append(succeededProfiles, s3ProfileName)
-	}
-
-	return succeededProfiles, nil
-}
-
 func (v *VRGInstance) getPVFromPVC(pvc *corev1.PersistentVolumeClaim) (corev1.PersistentVolume, error) {
 	pv := corev1.PersistentVolume{}
 	volumeName := pvc.Spec.VolumeName
@@ -781,6 +788,9 @@ func (v *VRGInstance) getObjectStorer(s3ProfileName string) (ObjectStorer, error
 }
 
 func (v *VRGInstance) getCachedObjectStorer(s3ProfileName string) (ObjectStorer, error) {
+	v.objectStorersMutex.Lock()
+	defer v.objectStorersMutex.Unlock()
+
 	if cachedObjectStore, ok := v.objectStorers[s3ProfileName]; ok {
 		return cachedObjectStore.storer, cachedObjectStore.err
 	}
@@ -789,6 +799,9 @@ func (v *VRGInstance) getCachedObjectStorer(s3ProfileName string) (ObjectStorer,
 }
 
 func (v *VRGInstance) cacheObjectStorer(s3ProfileName string, objectStore ObjectStorer, err error) {
+	v.objectStorersMutex.Lock()
+	defer v.objectStorersMutex.Unlock()
+
 	v.objectStorers[s3ProfileName] = cachedObjectStorer{
 		storer: objectStore,
 		err:    err,
@@ -1037,11 +1050,28 @@ func (v *VRGInstance) deleteClusterDataInS3Stores(log logr.Logger) error {
 	keyPrefix := v.s3KeyPrefix()
 
 	return v.s3StoresDo(
-		func(s ObjectStorer) error { return s.DeleteObjectsWithKeyPrefix(keyPrefix) },
+		func(s ObjectStorer) error { return v.s3ObjectsLockedTolerate(s.DeleteObjectsWithKeyPrefix(keyPrefix)) },
 		fmt.Sprintf("delete objects with key prefix %s", keyPrefix),
 	)
 }
 
+// s3ObjectsLockedTolerate reports objects whose versions a deletion of cluster
+// data retained because they are under object lock, which expires on its own,
+// and returns nil instead of the error reporting them; other errors are
+// returned.
+func (v *VRGInstance) s3ObjectsLockedTolerate(err error) error {
+	keys, locked := s3ObjectsLocked(err)
+	if !locked {
+		return err
+	}
+
+	v.log.Info("Object versions retained by object lock are not deleted", "keys", keys)
+	rmnutil.ReportIfNotPresent(v.reconciler.eventRecorder, v.instance, corev1.EventTypeWarning,
+		rmnutil.EventReasonS3ObjectsLocked, err.Error())
+
+	return nil
+}
+
 func (v *VRGInstance) pvAndPvcObjectReplicasDelete(pvc corev1.PersistentVolumeClaim, log logr.Logger) error {
 	vrg := v.instance
 
@@ -1080,6 +1110,187 @@ func (v *VRGInstance) s3StoresDo(do func(ObjectStorer) error, msg string) error
 	return nil
 }
 
+// s3UploadConcurrency bounds the number of uploads of cluster data that a VRG
+// reconcile runs concurrently across its S3 stores.
+const s3UploadConcurrency = 8
+
+// s3ProfileErrors holds errors keyed by the name of the S3 profile they
+// occurred with.
+type s3ProfileErrors map[string]error
+
+func (e s3ProfileErrors) Error() string {
+	msgs := make([]string, 0, len(e))
+
+	for _, s3ProfileName := range slices.Sorted(maps.Keys(e)) {
+		msgs = append(msgs, e[s3ProfileName].Error())
+	}
+
+	return strings.Join(msgs, "; ")
+}
+
+func (e s3ProfileErrors) Unwrap() []error {
+	return slices.Collect(maps.Values(e))
+}
+
+// s3UploadsDo calls upload for each of count items and each S3 profile in the
+// VRG spec, with at most s3UploadConcurrency calls in progress at a time, and
+// returns the errors of each item.  The object store of each S3 profile is
+// retrieved once, and if inaccessible, fails all the items for that profile.
+// Manifest updates of the typed objects uploaded to a profile are deferred to
+// the end of the reconcile.  upload must be safe to call concurrently.
+func (v *VRGInstance) s3UploadsDo(count int,
+	upload func(index int, s3ProfileName string, objectStore ObjectStorer) error,
+) []s3ProfileErrors {
+	uploadErrs := make([]s3ProfileErrors, count)
+	for i := range uploadErrs {
+		uploadErrs[i] = make(s3ProfileErrors)
+	}
+
+	var (
+		mutex     sync.Mutex
+		waitGroup sync.WaitGroup
+	)
+
+	semaphore := make(chan struct{}, s3UploadConcurrency)
+	objectStores := make(map[string]ObjectStorer, len(v.instance.Spec.S3Profiles))
+
+	for _, s3ProfileName := range v.instance.Spec.S3Profiles {
+		objectStorer, err := v.getObjectStorer(s3ProfileName)
+		if err != nil {
+			for i := range uploadErrs {
+				uploadErrs[i][s3ProfileName] = fmt.Errorf("error getting object store for s3Profile %s, %w",
+					s3ProfileName, err)
+			}
+
+			continue
+		}
+
+		objectStores[s3ProfileName] = v.s3ManifestBatch(s3ProfileName, objectStorer)
+	}
+
+	for s3ProfileName, objectStore := range objectStores {
+		for i := range count {
+			semaphore <- struct{}{}
+
+			waitGroup.Add(1)
+
+			go func() {
+				defer func() {
+					<-semaphore
+					waitGroup.Done()
+				}()
+
+				if err := upload(i, s3ProfileName, objectStore); err != nil {
+					mutex.Lock()
+					uploadErrs[i][s3ProfileName] = err
+					mutex.Unlock()
+				}
+			}()
+		}
+	}
+
+	waitGroup.Wait()
+
+	return uploadErrs
+}
+
+// s3ManifestBatch returns the manifest batch of the given S3 profile, through
+// which the reconcile uploads typed objects to it.
+func (v *VRGInstance) s3ManifestBatch(s3ProfileName string, objectStorer ObjectStorer) *s3ManifestBatch {
+	v.objectStorersMutex.Lock()
+	defer v.objectStorersMutex.Unlock()
+
+	if batch, ok := v.s3ManifestBatches[s3ProfileName]; ok {
+		return batch
+	}
+
+	if v.s3ManifestBatches == nil {
+		v.s3ManifestBatches = make(map[string]*s3ManifestBatch)
+	}
+
+	batch := newS3ManifestBatch(objectStorer)
+	v.s3ManifestBatches[s3ProfileName] = batch
+
+	return batch
+}
+
+// s3ManifestsPendingKey is the key of the manifest entries of a VRG and S3
+// profile that a reconcile failed to record.
+type s3ManifestsPendingKey struct {
+	vrg, s3ProfileName string
+}
+
+// s3ManifestsFlush updates the manifests of the objects the reconcile uploaded,
+// once per S3 profile, with those a previous reconcile failed to update them
+// with.  Entries that fail to be recorded are retained for the next reconcile.
+func (v *VRGInstance) s3ManifestsFlush() error {
+	pending := &v.reconciler.s3ManifestsPending
+
+	// The VRG's key prefix is deleted with it, and must not be recreated
+	if !v.instance.GetDeletionTimestamp().IsZero() {
+		s3ManifestsPendingForget(pending, v.namespacedName)
+
+		return nil
+	}
+
+	errs := make([]error, 0)
+
+	for _, s3ProfileName := range v.instance.Spec.S3Profiles {
+		if err := v.s3ManifestFlush(s3ProfileName); err != nil {
+			errs = append(errs, fmt.Errorf("error updating manifest in s3Profile %s, %w", s3ProfileName, err))
+		}
+	}
+
+	return errors.Join(errs...)
+}
+
+func (v *VRGInstance) s3ManifestFlush(s3ProfileName string) error {
+	pending := &v.reconciler.s3ManifestsPending
+	key := s3ManifestsPendingKey{vrg: v.namespacedName, s3ProfileName: s3ProfileName}
+	batch := v.s3ManifestBatches[s3ProfileName]
+
+	if value, ok := pending.LoadAndDelete(key); ok {
+		entries, _ := value.(map[string]map[string]s3ManifestObject)
+
+		if batch == nil {
+			objectStorer, err := v.getObjectStorer(s3ProfileName)
+			if err != nil {
+				pending.Store(key, entries)
+
+				return err
+			}
+
+			batch = newS3ManifestBatch(objectStorer)
+		}
+
+		batch.recordIfAbsent(entries)
+	}
+
+	if batch == nil {
+		return nil
+	}
+
+	if err := batch.flush(); err != nil {
+		pending.Store(key, batch.entries)
+
+		return err
+	}
+
+	return nil
+}
+
+// s3ManifestsPendingForget forgets the manifest entries of the given VRG that
+// a reconcile failed to record.
+func s3ManifestsPendingForget(pending *sync.Map, vrg string) {
+	pending.Range(func(key, _ any) bool {
+		if pendingKey, _ := key.(s3ManifestsPendingKey); pendingKey.vrg == vrg {
+			pending.Delete(key)
+		}
+
+		return true
+	})
+}
+
 func (v *VRGInstance) s3StoreDo(do func(ObjectStorer) error, msg, s3ProfileName string) (err error) {
 	objectStore, _, err := v.reconciler.ObjStoreGetter.ObjectStore(
 		v.ctx,
@@ -2124,6 +2335,9 @@ func (v *VRGInstance) restorePVsAndPVCsFromS3(result *ctrl.Result) (int, error)
 			continue
 		}
 
+		// Downloads the manifest once for all the types of objects restored
+		objectStore = v.s3ManifestBatch(s3ProfileName, objectStore)
+
 		var pvCount, pvcCount int
 
 		// Restore all PVs found in the s3 store. If any failure, the next profile will be retried
@@ -2179,6 +2393,14 @@ func (v *VRGInstance) restorePVsFromObjectStore(objectStore ObjectStorer, s3Prof
 		return 0, fmt.Errorf("%s: %w", errMsg, err)
 	}
 
+	namespaceMapping := vrgNamespaceMapping(*v.instance)
+
+	for i := range pvList {
+		if claimRef := pvList[i].Spec.ClaimRef; claimRef != nil {
+			claimRef.Namespace = namespaceMapping.recoverName(claimRef.Namespace)
+		}
+	}
+
 	return restoreClusterDataObjects(v, pvList, "PV", v.cleanupPVForRestore, v.validateExistingPV)
 }
 
@@ -2192,11 +2414,34 @@ func (v *VRGInstance) restorePVCsFromObjectStore(objectStore ObjectStorer, s3Pro
 
 	v.log.Info(fmt.Sprintf("Found %d PVCs in s3 store using profile %s", len(pvcList), s3ProfileName))
 
+	if err := v.pvcNamespacesMapForRestore(pvcList); err != nil {
+		return 0, err
+	}
+
 	v.volRepPVCs = append(v.volRepPVCs, pvcList...)
 
 	return restoreClusterDataObjects(v, pvcList, "PVC", cleanupPVCForRestore, v.validateExistingPVC)
 }
 
+// pvcNamespacesMapForRestore maps the namespaces of the PVCs to those they are
+// restored into on this cluster.
+func (v *VRGInstance) pvcNamespacesMapForRestore(pvcList []corev1.PersistentVolumeClaim) error {
+	namespaceMapping := vrgNamespaceMapping(*v.instance)
+
+	for i := range pvcList {
+		pvc := &pvcList[i]
+
+		namespaceName, err := v.namespaceMapForRestore(namespaceMapping, pvc.Namespace)
+		if err != nil {
+			return fmt.Errorf("PVC %s/%s restore error: %w", pvc.Namespace, pvc.Name, err)
+		}
+
+		pvc.Namespace = namespaceName
+	}
+
+	return nil
+}
+
 // checkPVClusterData returns an error if there are PVs in the input pvList
 // that have conflicting claimRefs that point to the same PVC name but
 // different PVC UID.
@@ -22,15 +22,20 @@ import (
 func (v *VRGInstance) restorePVsAndPVCsForVolSync() (int, error) {
 	v.log.Info("VolSync: Restoring VolSync PVs")
 
-	if len(v.instance.Spec.VolSync.RDSpec) == 0 {
+	rdSpecs := v.volSyncRDSpecs()
+	if len(rdSpecs) == 0 {
 		v.log.Info("No RDSpec entries. There are no PVCs to restore")
 		// No ReplicationDestinations (i.e. no PVCs) to restore
 		return 0, nil
 	}
 
+	if err := v.volSyncRDNamespacesCreate(rdSpecs); err != nil {
+		return 0, err
+	}
+
 	numPVsRestored := 0
 
-	for _, rdSpec := range v.instance.Spec.VolSync.RDSpec {
+	for _, rdSpec := range rdSpecs {
 		failoverAction := v.instance.Spec.Action == ramendrv1alpha1.VRGActionFailover
 
 		var err error
@@ -83,9 +88,9 @@ func (v *VRGInstance) restorePVsAndPVCsForVolSync() (int, error) {
 		setVRGConditionTypeVolSyncPVRestoreComplete(&protectedPVC.Conditions, v.instance.Generation, "PVC restored")
 	}
 
-	if numPVsRestored != len(v.instance.Spec.VolSync.RDSpec) {
+	if numPVsRestored != len(rdSpecs) {
 		return numPVsRestored, fmt.Errorf("failed to restore all PVCs. Restored %d PVCs out of %d RDSpecs",
-			numPVsRestored, len(v.instance.Spec.VolSync.RDSpec))
+			numPVsRestored, len(rdSpecs))
 	}
 
 	v.log.Info("Success restoring VolSync PVs", "Total", numPVsRestored)
@@ -110,7 +115,7 @@ func (v *VRGInstance) reconcileVolSyncAsPrimary(finalSyncPrepared *bool) (requeu
 	// Cleanup - this VRG is primary, cleanup if necessary
 	// remove any ReplicationDestinations (that would have been created when this VRG was secondary) if they
 	// are not in the RDSpec list
-	err := v.volSyncHandler.CleanupRDNotInSpecList(v.instance.Spec.VolSync.RDSpec, v.instance.Spec.ReplicationState)
+	err := v.volSyncHandler.CleanupRDNotInSpecList(v.volSyncRDSpecs(), v.instance.Spec.ReplicationState)
 	if err != nil {
 		v.log.Error(err, "Failed to cleanup the RDSpecs when this VRG instance was secondary")
 
@@ -121,11 +126,14 @@ func (v *VRGInstance) reconcileVolSyncAsPrimary(finalSyncPrepared *bool) (requeu
 
 	*finalSyncPrepared = true
 
+	freezer := recipeFreezer(v.recipeElements, v.reconciler.APIReader, v.reconciler.Client, v.reconciler.Scheme,
+		v.log)
+
 	for _, pvc := range v.volSyncPVCs {
 		var finalSyncForPVCPrepared bool
 
 		// TODO: Add deleted PVC handling here?
-		requeuePVC := v.reconcilePVCAsVolSyncPrimary(pvc, &finalSyncForPVCPrepared)
+		requeuePVC := v.reconcilePVCAsVolSyncPrimary(pvc, freezer, &finalSyncForPVCPrepared)
 		if requeuePVC {
 			requeue = true
 		}
@@ -135,6 +143,10 @@ func (v *VRGInstance) reconcileVolSyncAsPrimary(finalSyncPrepared *bool) (requeu
 		}
 	}
 
+	if freezer != nil && v.volSyncCopiesFrozenTrigger(freezer) {
+		requeue = true
+	}
+
 	if requeue {
 		v.log.Info("Not all ReplicationSources completed setup. We'll retry...")
 
@@ -148,7 +160,8 @@ func (v *VRGInstance) reconcileVolSyncAsPrimary(finalSyncPrepared *bool) (requeu
 }
 
 //nolint:gocognit,funlen,cyclop,gocyclo,nestif
-func (v *VRGInstance) reconcilePVCAsVolSyncPrimary(pvc corev1.PersistentVolumeClaim, finalSyncPrepared *bool,
+func (v *VRGInstance) reconcilePVCAsVolSyncPrimary(pvc corev1.PersistentVolumeClaim, freezer volsync.Freezer,
+	finalSyncPrepared *bool,
 ) (requeue bool) {
 	newProtectedPVC := &ramendrv1alpha1.ProtectedPVC{
 		Name:               pvc.Name,
@@ -232,6 +245,15 @@ func (v *VRGInstance) reconcilePVCAsVolSyncPrimary(pvc corev1.PersistentVolumeCl
 		return v.instance.Spec.RunFinalSync && !finalSyncComplete
 	}
 
+	// VolSync waits for the copy of a PVC whose application is to be frozen for it
+	// to be triggered, once the application is frozen, by volSyncCopiesFrozenTrigger
+	err = v.volSyncHandler.PVCCopyTriggerUse(util.ProtectedPVCNamespacedName(*protectedPVC), freezer != nil)
+	if err != nil {
+		v.log.Info(fmt.Sprintf("Unable to update PVC copy trigger use. We'll retry later. %v", err))
+
+		return true
+	}
+
 	// reconcile RS and if runFinalSync is true, then one final sync will be run
 	finalSyncComplete, rs, err := v.volSyncHandler.ReconcileRS(rsSpec, v.instance.Spec.RunFinalSync)
 	if err != nil {
@@ -258,6 +280,24 @@ func (v *VRGInstance) reconcilePVCAsVolSyncPrimary(pvc corev1.PersistentVolumeCl
 	return v.instance.Spec.RunFinalSync && !finalSyncComplete
 }
 
+// volSyncCopiesFrozenTrigger triggers VolSync to copy the VolSync PVCs whose
+// copies for their sync wait for it, with their applications frozen by the given
+// freezer, and returns whether to requeue as it failed to.
+func (v *VRGInstance) volSyncCopiesFrozenTrigger(freezer volsync.Freezer) bool {
+	pvcNamespacedNames := make([]types.NamespacedName, 0, len(v.volSyncPVCs))
+	for _, pvc := range v.volSyncPVCs {
+		pvcNamespacedNames = append(pvcNamespacedNames, types.NamespacedName{Namespace: pvc.Namespace, Name: pvc.Name})
+	}
+
+	if err := v.volSyncHandler.CopiesFrozenTrigger(pvcNamespacedNames, freezer); err != nil {
+		v.log.Info(fmt.Sprintf("Unable to trigger frozen PVC copies. We'll retry later. %v", err))
+
+		return true
+	}
+
+	return false
+}
+
 func (v *VRGInstance) reconcileVolSyncAsSecondary() bool {
 	v.log.Info("Reconcile VolSync as Secondary", "RDSpec", v.instance.Spec.VolSync.RDSpec)
 
@@ -326,13 +366,21 @@ func (v *VRGInstance) updateWorkloadActivityAsSecondary() bool {
 }
 
 func (v *VRGInstance) reconcileRDSpecForDeletionOrReplication() bool {
-	err := v.volSyncHandler.CleanupRDNotInSpecList(v.instance.Spec.VolSync.RDSpec, v.instance.Spec.ReplicationState)
+	rdSpecs := v.volSyncRDSpecs()
+
+	err := v.volSyncHandler.CleanupRDNotInSpecList(rdSpecs, v.instance.Spec.ReplicationState)
 	if err != nil {
 		v.log.Error(err, "Failed to cleanup the RDSpecs when this VRG instance was secondary")
 
 		return true // requeue
 	}
 
+	if err := v.volSyncRDNamespacesCreate(rdSpecs); err != nil {
+		v.log.Error(err, "Failed to create the namespaces of the RDSpecs")
+
+		return true // requeue
+	}
+
 	rdSpecsUsingCG, requeue, err := v.reconcileCGMembership()
 	if err != nil {
 		v.log.Error(err, "Failed to reconcile CG for deletion or replication")
@@ -340,7 +388,7 @@ func (v *VRGInstance) reconcileRDSpecForDeletionOrReplication() bool {
 		return requeue
 	}
 
-	for _, rdSpec := range v.instance.Spec.VolSync.RDSpec {
+	for _, rdSpec := range rdSpecs {
 		v.log.Info("Reconcile RD as Secondary", "RDSpec", rdSpec.ProtectedPVC.Name)
 
 		key := fmt.Sprintf("%s-%s", rdSpec.ProtectedPVC.Namespace, rdSpec.ProtectedPVC.Name)
@@ -641,7 +689,7 @@ func (v *VRGInstance) buildDataProtectedCondition() *metav1.Condition {
 	return dataProtectedCondition
 }
 
-func (v VRGInstance) isVolSyncProtectedPVCConditionReady(conType string) bool {
+func (v *VRGInstance) isVolSyncProtectedPVCConditionReady(conType string) bool {
 	ready := len(v.instance.Status.ProtectedPVCs) != 0
 
 	for _, protectedPVC := range v.instance.Status.ProtectedPVCs {
@@ -779,8 +827,9 @@ func (v *VRGInstance) cleanupResources() error {
 		}
 	}
 
-	for idx := range v.instance.Spec.VolSync.RDSpec {
-		protectedPVC := v.instance.Spec.VolSync.RDSpec[idx].ProtectedPVC
+	rdSpecs := v.volSyncRDSpecs()
+	for idx := range rdSpecs {
+		protectedPVC := rdSpecs[idx].ProtectedPVC
 
 		if err := v.doCleanupResources(protectedPVC.Name, protectedPVC.Namespace); err != nil {
 			return err
@@ -39,7 +39,9 @@ func (v *VRGInstance) vrgObjectProtectThrottled(result *ctrl.Result,
 	for _, s3StoreAccessor := range v.s3StoreAccessors {
 		log1 := log.WithValues("profile", s3StoreAccessor.S3ProfileName)
 
-		if err := VrgObjectProtect(s3StoreAccessor.ObjectStorer, *vrg); err != nil {
+		objectStorer := v.s3ManifestBatch(s3StoreAccessor.S3ProfileName, s3StoreAccessor.ObjectStorer)
+
+		if err := VrgObjectProtect(objectStorer, *vrg); err != nil {
 			util.ReportIfNotPresent(
 				eventReporter, vrg, corev1.EventTypeWarning, util.EventReasonVrgUploadFailed, err.Error(),
 			)